@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -18,10 +20,15 @@ type (
 		Swagger  Swagger
 		Schedule Schedule
 		JWT      JWT
+		Login    Login
+		MFA      MFA
 		OAuth    OAuth
 		Xendit   Xendit
+		Tenant   Tenant
 		Supabase Supabase
 		Mail     Mail
+		SMS      SMS
+		OTP      OTP
 	}
 
 	App struct {
@@ -75,6 +82,7 @@ type (
 
 	Schedule struct {
 		BookingsExpiration string `env:"SCHEDULE_BOOKINGS_EXPIRATION,required"`
+		TokensCleanup      string `env:"SCHEDULE_TOKENS_CLEANUP"      envDefault:"0 0 * * * *"`
 	}
 
 	JWT struct {
@@ -83,15 +91,54 @@ type (
 		RefreshTokenExpiry string `env:"JWT_REFRESH_TOKEN_EXPIRY" envDefault:"7d"`
 	}
 
+	Login struct {
+		// FreeAttempts failures are allowed before each further attempt on the account is delayed exponentially
+		FreeAttempts    int           `env:"LOGIN_FREE_ATTEMPTS"     envDefault:"3"`
+		MaxAttempts     int           `env:"LOGIN_MAX_ATTEMPTS"      envDefault:"10"`
+		IPMaxAttempts   int           `env:"LOGIN_IP_MAX_ATTEMPTS"   envDefault:"50"`
+		LockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	}
+
+	MFA struct {
+		// RequiredRoles must log in with two-factor authentication, e.g. "staff,admin"
+		RequiredRoles []string `env:"MFA_REQUIRED_ROLES" envSeparator:","`
+		// Issuer names the account in authenticator apps, defaults to the app name
+		Issuer string `env:"MFA_ISSUER"`
+	}
+
 	OAuth struct {
 		Google GoogleOAuth `env:"OAUTH_GOOGLE"`
+		GitHub GitHubOAuth
+		// Apple and OIDC are OpenID Connect providers configured through discovery, enabled by a client ID
+		Apple OIDCOAuth `envPrefix:"OAUTH_APPLE_"`
+		OIDC  OIDCOAuth `envPrefix:"OAUTH_OIDC_"`
+		// FrontendURL receives the authorization code when a login names no redirect URI
+		FrontendURL string `env:"OAUTH_FRONTEND_URL"`
+		// AllowedFrontendURLs are frontends besides FrontendURL that may receive authorization codes
+		AllowedFrontendURLs []string `env:"OAUTH_ALLOWED_FRONTEND_URLS" envSeparator:","`
 	}
 
 	GoogleOAuth struct {
 		ClientID     string `env:"OAUTH_GOOGLE_CLIENT_ID,required"`
 		ClientSecret string `env:"OAUTH_GOOGLE_CLIENT_SECRET,required"`
 		RedirectURL  string `env:"OAUTH_GOOGLE_REDIRECT_URL,required"`
-		FrontendURL  string `env:"OAUTH_GOOGLE_FRONTEND_URL,required"`
+		// Deprecated: FrontendURL is only read when OAUTH_FRONTEND_URL is unset, use OAuth.FrontendURL.
+		FrontendURL string `env:"OAUTH_GOOGLE_FRONTEND_URL"`
+	}
+
+	GitHubOAuth struct {
+		ClientID     string `env:"OAUTH_GITHUB_CLIENT_ID"`
+		ClientSecret string `env:"OAUTH_GITHUB_CLIENT_SECRET"`
+		RedirectURL  string `env:"OAUTH_GITHUB_REDIRECT_URL"`
+	}
+
+	OIDCOAuth struct {
+		Name         string   `env:"NAME"`
+		IssuerURL    string   `env:"ISSUER_URL"`
+		ClientID     string   `env:"CLIENT_ID"`
+		ClientSecret string   `env:"CLIENT_SECRET"`
+		RedirectURL  string   `env:"REDIRECT_URL"`
+		Scopes       []string `env:"SCOPES" envSeparator:","`
 	}
 
 	Xendit struct {
@@ -101,12 +148,23 @@ type (
 		FailureURL    string `env:"XENDIT_FAILURE_URL,required"`
 	}
 
+	Tenant struct {
+		// BaseDomain is the domain organisations get a subdomain of, e.g. "goth.app" for "arena.goth.app"
+		BaseDomain string `env:"TENANT_BASE_DOMAIN"`
+		// Default is the slug of the organisation used when a request names none
+		Default string `env:"TENANT_DEFAULT" envDefault:"default"`
+		// EncryptionKey is a base64 encoded 32 byte key sealing the organisations' Xendit credentials
+		EncryptionKey string `env:"TENANT_ENCRYPTION_KEY,required"`
+	}
+
 	Supabase struct {
 		AccessKeyID     string `env:"SUPABASE_AWS_ACCESS_KEY_ID,required"`
 		SecretAccessKey string `env:"SUPABASE_AWS_SECRET_ACCESS_KEY,required"`
 		EndpointURL     string `env:"SUPABASE_ENDPOINT_URL,required"`
 		Region          string `env:"SUPABASE_REGION,required"`
 		BucketName      string `env:"SUPABASE_BUCKET_NAME,required"`
+		// PrivateBucketName holds documents with personal data, e.g. receipts. It must not be a public bucket.
+		PrivateBucketName string `env:"SUPABASE_PRIVATE_BUCKET_NAME,required"`
 	}
 
 	Mail struct {
@@ -117,6 +175,17 @@ type (
 		FromEmail    string `env:"MAIL_FROM_EMAIL,required"`
 		FromName     string `env:"MAIL_FROM_NAME,required"`
 	}
+
+	SMS struct {
+		// Driver sends the messages, "log" only writes them to the log
+		Driver string `env:"SMS_DRIVER" envDefault:"log"`
+	}
+
+	OTP struct {
+		TTL            time.Duration `env:"OTP_TTL"             envDefault:"5m"`
+		MaxAttempts    int           `env:"OTP_MAX_ATTEMPTS"    envDefault:"5"`
+		ResendCooldown time.Duration `env:"OTP_RESEND_COOLDOWN" envDefault:"1m"`
+	}
 )
 
 func New() (*Config, error) {
@@ -125,5 +194,13 @@ func New() (*Config, error) {
 		return nil, fmt.Errorf("parse config failed: %w", err)
 	}
 
+	if cfg.OAuth.FrontendURL == "" {
+		cfg.OAuth.FrontendURL = cfg.OAuth.Google.FrontendURL
+	}
+
+	if cfg.OAuth.FrontendURL == "" {
+		return nil, errors.New("parse config failed: OAUTH_FRONTEND_URL is required")
+	}
+
 	return cfg, nil
 }
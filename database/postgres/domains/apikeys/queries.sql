@@ -0,0 +1,26 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (organization_id, user_id, name, prefix, key_hash, scopes, rate_limit)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1 AND organization_id = $2 AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: GetAPIKeys :many
+SELECT * FROM api_keys
+WHERE organization_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountAPIKeys :one
+SELECT COUNT(*) FROM api_keys WHERE organization_id = $1;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = now()
+WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - INTERVAL '1 minute');
@@ -0,0 +1,25 @@
+-- name: InsertAuditLog :exec
+INSERT INTO audit_logs (organization_id, actor_user_id, actor_api_key_id, impersonator_user_id, action, entity_type, entity_id, changes, request_id, ip_address)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetAuditLogs :many
+SELECT * FROM audit_logs
+WHERE organization_id = sqlc.arg(organization_id)
+  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR actor_user_id = sqlc.narg(actor_user_id)::uuid)
+  AND (sqlc.arg(action)::text = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(entity_type)::text = '' OR entity_type = sqlc.arg(entity_type))
+  AND (sqlc.arg(entity_id)::text = '' OR entity_id = sqlc.arg(entity_id))
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountAuditLogs :one
+SELECT COUNT(*) FROM audit_logs
+WHERE organization_id = sqlc.arg(organization_id)
+  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR actor_user_id = sqlc.narg(actor_user_id)::uuid)
+  AND (sqlc.arg(action)::text = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(entity_type)::text = '' OR entity_type = sqlc.arg(entity_type))
+  AND (sqlc.arg(entity_id)::text = '' OR entity_id = sqlc.arg(entity_id))
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1);
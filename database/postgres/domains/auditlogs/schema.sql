@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    actor_user_id UUID DEFAULT NULL,
+    actor_api_key_id UUID DEFAULT NULL,
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR(100) DEFAULT NULL,
+    ip_address VARCHAR(45) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    impersonator_user_id UUID DEFAULT NULL
+);
@@ -1,10 +1,10 @@
 -- name: InsertBooking :one
-INSERT INTO bookings (user_id, field_id, booking_date, start_time, end_time, total_price, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO bookings (user_id, field_id, booking_date, start_time, end_time, total_price, status, subtotal, tax_rate, tax_inclusive, tax_amount, currency, organization_id, api_key_id, created_by, guest_name, guest_phone, guest_email)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING id;
 
 -- name: GetBookingById :one
-SELECT * FROM bookings WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+SELECT * FROM bookings WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: CountOverlaps :one
 SELECT COUNT(*) FROM bookings
@@ -12,6 +12,7 @@ WHERE field_id = $1
   AND booking_date = $2
   AND status IN ('PENDING', 'CONFIRMED', 'PAID')
   AND (start_time, end_time) OVERLAPS ($3::time, $4::time)
+  AND organization_id = $5
   AND deleted_at IS NULL;
 
 -- name: CancelBooking :exec
@@ -20,9 +21,17 @@ SET status = 'CANCELLED',
     canceled_at = now(),
     canceled_by = $3,
     updated_at = now()
-WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+WHERE id = $1 AND user_id = $2 AND organization_id = $4 AND deleted_at IS NULL
 RETURNING id;
 
+-- name: CancelAnyBooking :exec
+UPDATE bookings
+SET status = 'CANCELLED',
+    canceled_at = now(),
+    canceled_by = $2,
+    updated_at = now()
+WHERE id = $1 AND organization_id = $3 AND deleted_at IS NULL;
+
 -- name: ExpireOldBookings :exec
 UPDATE bookings
 SET status = 'EXPIRED',
@@ -35,6 +44,7 @@ RETURNING id;
 -- name: GetBookingsByUserId :many
 SELECT * FROM bookings
 WHERE user_id = $1
+  AND organization_id = $5
   AND deleted_at IS NULL
     AND ($2::text = '' OR status ILIKE '%' || $2 || '%')
 ORDER BY booking_date DESC, start_time DESC
@@ -43,6 +53,7 @@ LIMIT $3 OFFSET $4;
 -- name: CountBookingsByUserId :one
 SELECT COUNT(*) FROM bookings
 WHERE user_id = $1
+  AND organization_id = $3
   AND deleted_at IS NULL
     AND ($2::text = '' OR status ILIKE '%' || $2 || '%');
 
@@ -51,6 +62,7 @@ SELECT start_time, end_time
 FROM bookings
 WHERE field_id = $1
   AND booking_date = $2
+  AND organization_id = $3
   AND status IN ('PENDING', 'CONFIRMED')
 ORDER BY start_time;
 
@@ -58,11 +70,12 @@ ORDER BY start_time;
 UPDATE bookings
 SET status = $2,
     updated_at = now()
-WHERE id = $1 AND deleted_at IS NULL;
+WHERE id = $1 AND organization_id = $3 AND deleted_at IS NULL;
 
 -- name: GetAllBookings :many
 SELECT * FROM bookings
 WHERE deleted_at IS NULL
+  AND organization_id = $4
   AND ($1::text = '' OR status ILIKE '%' || $1 || '%')
 ORDER BY booking_date DESC, start_time DESC
 LIMIT $2 OFFSET $3;
@@ -70,10 +83,57 @@ LIMIT $2 OFFSET $3;
 -- name: CountAllBookings :one
 SELECT COUNT(*) FROM bookings
 WHERE deleted_at IS NULL
+  AND organization_id = $2
   AND ($1::text = '' OR status ILIKE '%' || $1 || '%');
 
 -- name: GetBookingFieldIDs :many
 SELECT DISTINCT field_id FROM bookings
 WHERE deleted_at IS NULL
+  AND organization_id = $2
   AND ($1::text = '' OR status ILIKE '%' || $1 || '%')
 ORDER BY field_id;
+
+-- name: GetAllBookingsByUserId :many
+SELECT * FROM bookings
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetBookingsByFieldIDs :many
+SELECT * FROM bookings
+WHERE deleted_at IS NULL
+  AND organization_id = sqlc.arg(organization_id)
+  AND field_id = ANY(sqlc.arg(field_ids)::uuid[])
+  AND (sqlc.arg(filter)::text = '' OR status ILIKE '%' || sqlc.arg(filter) || '%')
+ORDER BY booking_date DESC, start_time DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountBookingsByFieldIDs :one
+SELECT COUNT(*) FROM bookings
+WHERE deleted_at IS NULL
+  AND organization_id = sqlc.arg(organization_id)
+  AND field_id = ANY(sqlc.arg(field_ids)::uuid[])
+  AND (sqlc.arg(filter)::text = '' OR status ILIKE '%' || sqlc.arg(filter) || '%');
+
+-- name: GetBookingIDsByFieldIDs :many
+SELECT id FROM bookings
+WHERE field_id = ANY(sqlc.arg(field_ids)::uuid[])
+  AND organization_id = sqlc.arg(organization_id);
+
+-- name: GetBookingIDsByUserID :many
+SELECT id FROM bookings
+WHERE user_id = $1 AND organization_id = $2;
+
+-- name: SetBookingShareTokenHash :execrows
+UPDATE bookings SET share_token_hash = $2, updated_at = now()
+WHERE id = $1 AND organization_id = $3 AND deleted_at IS NULL;
+
+-- name: GetBookingByShareTokenHash :one
+SELECT * FROM bookings WHERE share_token_hash = $1 AND organization_id = $2 AND deleted_at IS NULL LIMIT 1;
+
+-- name: AttachGuestBookings :execrows
+UPDATE bookings SET user_id = sqlc.arg(user_id), updated_at = now()
+WHERE user_id IS NULL AND lower(guest_email) = lower(sqlc.arg(email)::text) AND deleted_at IS NULL;
+
+-- name: AnonymizeGuestBookingsByUserID :exec
+UPDATE bookings SET guest_name = NULL, guest_phone = NULL, guest_email = NULL, updated_at = now()
+WHERE user_id = $1 AND (guest_name IS NOT NULL OR guest_phone IS NOT NULL OR guest_email IS NOT NULL);
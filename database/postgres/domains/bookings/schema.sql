@@ -1,6 +1,6 @@
 CREATE TABLE IF NOT EXISTS bookings (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE RESTRICT,
     field_id UUID REFERENCES fields(id) ON DELETE CASCADE,
     booking_date DATE NOT NULL,
     start_time TIME NOT NULL,
@@ -12,5 +12,17 @@ CREATE TABLE IF NOT EXISTS bookings (
     canceled_by VARCHAR(20) DEFAULT NULL,
     created_at TIMESTAMP DEFAULT now(),
     updated_at TIMESTAMP DEFAULT now(),
-    deleted_at TIMESTAMP DEFAULT NULL
+    deleted_at TIMESTAMP DEFAULT NULL,
+    subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT NOT NULL,
+    share_token_hash VARCHAR(64) DEFAULT NULL,
+    api_key_id UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    guest_name VARCHAR(255) DEFAULT NULL,
+    guest_phone VARCHAR(16) DEFAULT NULL,
+    guest_email VARCHAR(255) DEFAULT NULL
 );
\ No newline at end of file
@@ -1,26 +1,29 @@
 -- name: CreateField :one
-INSERT INTO fields (location_id, name, type, price, description, images)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO fields (location_id, name, type, price, description, images, currency, organization_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING id;
 
 -- name: GetFieldById :one
-SELECT * FROM fields WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+SELECT * FROM fields WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetFields :many
 SELECT * FROM fields
 WHERE deleted_at IS NULL
+  AND organization_id = $4
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%')
     LIMIT $2 OFFSET $3;
 
 -- name: CountFields :one
 SELECT COUNT(*) FROM fields
 WHERE deleted_at IS NULL
+  AND organization_id = $2
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%');
 
 -- name: GetFieldsByLocationID :many
 SELECT * FROM fields
 WHERE deleted_at IS NULL
   AND location_id = $2
+  AND organization_id = $5
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%')
     LIMIT $3 OFFSET $4;
 
@@ -28,6 +31,7 @@ WHERE deleted_at IS NULL
 SELECT COUNT(*) FROM fields
 WHERE deleted_at IS NULL
   AND location_id = $2
+  AND organization_id = $3
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%');
 
 -- name: UpdateField :one
@@ -38,9 +42,15 @@ UPDATE fields SET
     price = $5,
     description = $6,
     images = $7,
+    currency = $8,
     updated_at = now()
-WHERE id = $1 AND deleted_at IS NULL
+WHERE id = $1 AND organization_id = $9 AND deleted_at IS NULL
 RETURNING id;
 
 -- name: DeleteField :exec
-DELETE FROM fields WHERE id = $1 AND deleted_at IS NULL;
+DELETE FROM fields WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL;
+
+-- name: GetFieldIDsByLocationIDs :many
+SELECT id FROM fields
+WHERE location_id = ANY(sqlc.arg(location_ids)::uuid[])
+  AND organization_id = sqlc.arg(organization_id);
@@ -8,5 +8,7 @@ CREATE TABLE IF NOT EXISTS fields (
     images TEXT[] DEFAULT '{}',
     created_at TIMESTAMP DEFAULT now(),
     updated_at TIMESTAMP DEFAULT now(),
-    deleted_at TIMESTAMP DEFAULT NULL
+    deleted_at TIMESTAMP DEFAULT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT NOT NULL
 );
\ No newline at end of file
@@ -1,13 +1,14 @@
 -- name: CreateLocation :one
-INSERT INTO locations (name, latitude, longitude, description)
-VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO locations (name, latitude, longitude, description, service_fee, convenience_fee, tax_rate, tax_inclusive, currency, organization_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
 
 -- name: GetLocationById :one
-SELECT * FROM locations WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+SELECT * FROM locations WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetLocationsWithFilter :many
 SELECT * FROM locations
 WHERE deleted_at IS NULL
+  AND organization_id = $4
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%')
 ORDER BY created_at DESC
     LIMIT $2 OFFSET $3;
@@ -15,11 +16,28 @@ ORDER BY created_at DESC
 -- name: CountLocationsWithFilter :one
 SELECT COUNT(*) FROM locations
 WHERE deleted_at IS NULL
+  AND organization_id = $2
   AND ($1::text = '' OR name ILIKE '%' || $1 || '%');
 
 -- name: UpdateLocation :one
-UPDATE locations SET name = $1, latitude = $2, longitude = $3, description = $4, updated_at = now()
-    WHERE id = $5 AND deleted_at IS NULL RETURNING *;
+UPDATE locations SET name = $1, latitude = $2, longitude = $3, description = $4, service_fee = $5, convenience_fee = $6, tax_rate = $7, tax_inclusive = $8, currency = $9, updated_at = now()
+    WHERE id = $10 AND organization_id = $11 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeleteLocation :exec
-DELETE FROM locations WHERE id = $1 AND deleted_at IS NULL;
+DELETE FROM locations WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL;
+
+-- name: AssignLocationStaff :exec
+INSERT INTO location_staff (location_id, user_id) VALUES ($1, $2)
+ON CONFLICT (location_id, user_id) DO NOTHING;
+
+-- name: UnassignLocationStaff :execrows
+DELETE FROM location_staff WHERE location_id = $1 AND user_id = $2;
+
+-- name: GetLocationStaff :many
+SELECT * FROM location_staff WHERE location_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetStaffLocationIDs :many
+SELECT ls.location_id FROM location_staff ls
+JOIN locations l ON l.id = ls.location_id
+WHERE ls.user_id = $1 AND l.organization_id = $2;
@@ -4,7 +4,20 @@ CREATE TABLE IF NOT EXISTS locations (
     latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
     longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
     description TEXT DEFAULT NULL,
+    service_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    convenience_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate BETWEEN 0 AND 100),
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT now(),
     updated_at TIMESTAMP DEFAULT now(),
-    deleted_at TIMESTAMP DEFAULT NULL
-);
\ No newline at end of file
+    deleted_at TIMESTAMP DEFAULT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS location_staff (
+    location_id UUID REFERENCES locations(id) ON DELETE CASCADE NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (location_id, user_id)
+);
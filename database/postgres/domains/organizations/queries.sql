@@ -0,0 +1,26 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (slug, name) VALUES ($1, $2) RETURNING *;
+
+-- name: GetOrganizationByID :one
+SELECT * FROM organizations WHERE id = $1 LIMIT 1;
+
+-- name: GetOrganizationBySlug :one
+SELECT * FROM organizations WHERE slug = $1 LIMIT 1;
+
+-- name: GetOrganizations :many
+SELECT * FROM organizations
+WHERE ($1::text = '' OR name ILIKE '%' || $1 || '%' OR slug ILIKE '%' || $1 || '%')
+ORDER BY created_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountOrganizations :one
+SELECT COUNT(*) FROM organizations
+WHERE ($1::text = '' OR name ILIKE '%' || $1 || '%' OR slug ILIKE '%' || $1 || '%');
+
+-- name: UpdateOrganization :one
+UPDATE organizations SET name = $2, updated_at = now()
+WHERE id = $1 RETURNING *;
+
+-- name: SetOrganizationXenditCredentials :one
+UPDATE organizations SET xendit_api_key = $2, xendit_callback_token = $3, updated_at = now()
+WHERE id = $1 RETURNING *;
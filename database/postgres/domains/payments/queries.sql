@@ -1,23 +1,37 @@
 -- name: InsertPayment :one
-INSERT into payments (booking_id, payment_method, payment_status, transaction_id)
-values ($1, $2, $3, $4)
+INSERT into payments (booking_id, payment_method, payment_status, transaction_id, payment_channel, channel_code, description, customer_name, customer_phone, subtotal, tax_amount, total_amount, currency, organization_id)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 returning id;
 
+-- name: InsertPaymentItem :exec
+INSERT INTO payment_items (payment_id, reference_id, name, category, description, quantity, unit_price, discount, amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetPaymentItemsByPaymentID :many
+SELECT * FROM payment_items WHERE payment_id = $1
+ORDER BY created_at ASC, id ASC;
+
 -- name: GetPaymentsByBookingID :many
-SELECT * FROM payments WHERE booking_id = $1
+SELECT * FROM payments WHERE booking_id = $1 AND organization_id = $2
 ORDER BY created_at DESC;
 
 -- name: GetPayments :many
 SELECT * FROM payments
-WHERE ($1::text = '' OR payment_method ILIKE '%' || $1 || '%')
-  AND ($2::text = '' OR payment_status ILIKE '%' || $2 || '%')
+WHERE organization_id = sqlc.arg(organization_id)
+  AND (sqlc.arg(payment_method)::text = '' OR payment_method ILIKE '%' || sqlc.arg(payment_method) || '%')
+  AND (sqlc.arg(payment_status)::text = '' OR payment_status ILIKE '%' || sqlc.arg(payment_status) || '%')
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1)
 ORDER BY created_at DESC
-LIMIT $3 OFFSET $4;
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
 
 -- name: CountPayments :one
 SELECT COUNT(*) FROM payments
-WHERE ($1::text = '' OR payment_method ILIKE '%' || $1 || '%')
-  AND ($2::text = '' OR payment_status ILIKE '%' || $2 || '%');
+WHERE organization_id = sqlc.arg(organization_id)
+  AND (sqlc.arg(payment_method)::text = '' OR payment_method ILIKE '%' || sqlc.arg(payment_method) || '%')
+  AND (sqlc.arg(payment_status)::text = '' OR payment_status ILIKE '%' || sqlc.arg(payment_status) || '%')
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1);
 
 -- name: UpdatePaymentStatus :exec
 UPDATE payments
@@ -25,12 +39,42 @@ SET payment_status = $2,
     payment_method = $3,
     paid_at = $4,
     updated_at = now()
-WHERE transaction_id = $1;
+WHERE transaction_id = $1 AND organization_id = $5;
 
--- name: UpdatePaymentStatusByBookingID :exec
+-- name: UpdatePaymentStatusByBookingID :execrows
+-- A paid payment is final, late callbacks of another status must not reopen it.
 UPDATE payments
 SET payment_status = $2,
     payment_method = $3,
-    paid_at = $4,
+    paid_at = COALESCE(paid_at, $4),
     updated_at = now()
-WHERE booking_id = $1;
+WHERE booking_id = $1 AND organization_id = $5
+  AND (payment_status <> 'PAID' OR $2 = 'PAID');
+
+-- name: GetPaymentsByBookingIDs :many
+SELECT * FROM payments WHERE booking_id = ANY(sqlc.arg(booking_ids)::uuid[])
+ORDER BY created_at DESC;
+
+-- name: AnonymizePaymentsByBookingIDs :exec
+UPDATE payments SET customer_name = NULL, customer_phone = NULL, updated_at = now()
+WHERE booking_id = ANY(sqlc.arg(booking_ids)::uuid[]);
+
+-- name: GetPaymentsForBookings :many
+SELECT * FROM payments
+WHERE organization_id = sqlc.arg(organization_id)
+  AND booking_id = ANY(sqlc.arg(booking_ids)::uuid[])
+  AND (sqlc.arg(payment_method)::text = '' OR payment_method ILIKE '%' || sqlc.arg(payment_method) || '%')
+  AND (sqlc.arg(payment_status)::text = '' OR payment_status ILIKE '%' || sqlc.arg(payment_status) || '%')
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountPaymentsForBookings :one
+SELECT COUNT(*) FROM payments
+WHERE organization_id = sqlc.arg(organization_id)
+  AND booking_id = ANY(sqlc.arg(booking_ids)::uuid[])
+  AND (sqlc.arg(payment_method)::text = '' OR payment_method ILIKE '%' || sqlc.arg(payment_method) || '%')
+  AND (sqlc.arg(payment_status)::text = '' OR payment_status ILIKE '%' || sqlc.arg(payment_status) || '%')
+  AND (sqlc.narg(date_from)::date IS NULL OR created_at >= sqlc.narg(date_from)::date)
+  AND (sqlc.narg(date_to)::date IS NULL OR created_at < sqlc.narg(date_to)::date + 1);
@@ -6,5 +6,29 @@ CREATE TABLE IF NOT EXISTS payments (
     transaction_id VARCHAR(255) UNIQUE NOT NULL,
     paid_at TIMESTAMP DEFAULT NULL,
     created_at TIMESTAMP DEFAULT now(),
-    updated_at TIMESTAMP DEFAULT now()
-);
\ No newline at end of file
+    updated_at TIMESTAMP DEFAULT now(),
+    payment_channel VARCHAR(50) NOT NULL DEFAULT 'INVOICE',
+    channel_code VARCHAR(50) DEFAULT NULL,
+    description TEXT DEFAULT NULL,
+    customer_name VARCHAR(255) DEFAULT NULL,
+    customer_phone VARCHAR(50) DEFAULT NULL,
+    subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    total_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS payment_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID REFERENCES payments(id) ON DELETE CASCADE NOT NULL,
+    reference_id VARCHAR(255) DEFAULT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    description TEXT DEFAULT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_price NUMERIC(12, 2) NOT NULL,
+    discount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    amount NUMERIC(12, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
@@ -2,52 +2,257 @@
 SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: CreateUser :one
-INSERT INTO users (email, password, level, google_id, full_name, profile_image, is_verified) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+INSERT INTO users (email, password, role, full_name, profile_image, is_verified) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: UpdateUser :one
-UPDATE users SET email = $1, password = $2, google_id = $3, full_name = $4, profile_image = $5, is_verified = $6, updated_at = now()
-    WHERE id = $7 AND deleted_at IS NULL RETURNING *;
+UPDATE users SET email = $1, password = $2, full_name = $3, profile_image = $4, is_verified = $5, updated_at = now()
+    WHERE id = $6 AND deleted_at IS NULL RETURNING *;
 
 -- name: UpdateLastLogin :one
 UPDATE users SET last_login = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id;
 
 -- name: CreateEmailVerification :one
-INSERT INTO email_verifications (user_id, token, expires_at) VALUES ($1, $2, now() + interval '24 hours') RETURNING *;
+INSERT INTO email_verifications (user_id, token_hash, expires_at) VALUES ($1, $2, now() + interval '24 hours') RETURNING *;
 
--- name: GetEmailVerificationByToken :one
-SELECT * FROM email_verifications WHERE token = $1 AND expires_at > now() LIMIT 1;
+-- name: GetEmailVerificationByTokenHash :one
+SELECT * FROM email_verifications WHERE token_hash = $1 AND expires_at > now() LIMIT 1;
+
+-- name: DeleteEmailVerificationsByUserID :exec
+DELETE FROM email_verifications WHERE user_id = $1;
 
 -- name: VerifyEmail :one
 UPDATE users SET is_verified = true WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
 -- name: CreatePasswordReset :one
-INSERT INTO password_resets (user_id, token, expires_at) VALUES ($1, $2, now() + interval '1 hour') RETURNING *;
+INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, now() + interval '1 hour') RETURNING *;
+
+-- name: GetPasswordResetByTokenHash :one
+SELECT * FROM password_resets WHERE token_hash = $1 AND expires_at > now() LIMIT 1;
 
--- name: GetPasswordResetByToken :one
-SELECT * FROM password_resets WHERE token = $1 AND expires_at > now() LIMIT 1;
+-- name: DeletePasswordResetsByUserID :exec
+DELETE FROM password_resets WHERE user_id = $1;
 
 -- name: ResetPassword :one
 UPDATE users SET password = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING *;
 
 -- name: GetAllUsers :many
 SELECT * FROM users 
-WHERE deleted_at IS NULL
+WHERE (deleted_at IS NULL) <> $4::boolean
+  AND email NOT LIKE '%@deleted.invalid'
   AND ($1::text = '' OR email ILIKE '%' || $1 || '%')
   AND ($2::text = '' OR full_name ILIKE '%' || $2 || '%')
-  AND ($3::text = '' OR level = $3)
+  AND ($3::text = '' OR role = $3)
 ORDER BY created_at DESC
-LIMIT $4 OFFSET $5;
+LIMIT $5 OFFSET $6;
 
 -- name: CountUsers :one
 SELECT COUNT(*) FROM users
-WHERE deleted_at IS NULL
+WHERE (deleted_at IS NULL) <> $4::boolean
+  AND email NOT LIKE '%@deleted.invalid'
   AND ($1::text = '' OR email ILIKE '%' || $1 || '%')
   AND ($2::text = '' OR full_name ILIKE '%' || $2 || '%')
-  AND ($3::text = '' OR level = $3);
+  AND ($3::text = '' OR role = $3);
 
 -- name: GetUserByID :one
 SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: UpdateUserRole :one
-UPDATE users SET level = $2, updated_at = now()
+UPDATE users SET role = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, now() + sqlc.arg(ttl)::interval) RETURNING id;
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens WHERE token_hash = $1 LIMIT 1;
+
+-- name: UseRefreshToken :execrows
+UPDATE refresh_tokens SET used_at = now()
+WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > now();
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens SET revoked_at = now()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, device, ip_address, user_agent) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT * FROM sessions WHERE id = $1 LIMIT 1;
+
+-- name: GetActiveSessionsByUserID :many
+SELECT * FROM sessions
+WHERE sessions.user_id = $1 AND sessions.revoked_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM refresh_tokens
+    WHERE refresh_tokens.family_id = sessions.id AND refresh_tokens.revoked_at IS NULL
+      AND refresh_tokens.used_at IS NULL AND refresh_tokens.expires_at > now()
+  )
+ORDER BY last_seen_at DESC;
+
+-- name: TouchSession :exec
+UPDATE sessions SET last_seen_at = now(), ip_address = $2, user_agent = $3 WHERE id = $1;
+
+-- name: RevokeSession :execrows
+UPDATE sessions SET revoked_at = now()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities WHERE provider = $1 AND subject = $2 LIMIT 1;
+
+-- name: GetUserIdentitiesByUserID :many
+SELECT * FROM user_identities WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities WHERE user_id = $1 AND provider = $2;
+
+-- name: UpsertPendingUserMFA :execrows
+INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+WHERE user_mfa.enabled_at IS NULL;
+
+-- name: GetUserMFA :one
+SELECT * FROM user_mfa WHERE user_id = $1 LIMIT 1;
+
+-- name: EnableUserMFA :execrows
+UPDATE user_mfa SET enabled_at = now(), last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL;
+
+-- name: UseUserMFAStep :execrows
+UPDATE user_mfa SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa WHERE user_id = $1;
+
+-- name: CreateMFARecoveryCodes :exec
+INSERT INTO mfa_recovery_codes (user_id, code_hash)
+SELECT $1, unnest(sqlc.arg(code_hashes)::text[]);
+
+-- name: UseMFARecoveryCode :execrows
+UPDATE mfa_recovery_codes SET used_at = now()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM mfa_recovery_codes WHERE user_id = $1;
+
+-- name: DeleteExpiredEmailVerifications :execrows
+DELETE FROM email_verifications WHERE expires_at <= now();
+
+-- name: DeleteExpiredPasswordResets :execrows
+DELETE FROM password_resets WHERE expires_at <= now();
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens WHERE expires_at <= now();
+
+-- name: DeleteInactiveSessions :execrows
+DELETE FROM sessions
+WHERE NOT EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.family_id = sessions.id);
+
+-- name: DeleteExpiredEmailChanges :execrows
+DELETE FROM email_changes WHERE expires_at <= now();
+
+-- name: UpdateUserProfile :one
+UPDATE users SET full_name = COALESCE(sqlc.narg(full_name), full_name),
+    profile_image = COALESCE(sqlc.narg(profile_image), profile_image), updated_at = now()
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE users SET email = $2, is_verified = true, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (user_id, new_email, token_hash, expires_at) VALUES ($1, $2, $3, now() + interval '24 hours') RETURNING *;
+
+-- name: GetEmailChangeByTokenHash :one
+SELECT * FROM email_changes WHERE token_hash = $1 AND expires_at > now() LIMIT 1;
+
+-- name: DeleteEmailChangesByUserID :exec
+DELETE FROM email_changes WHERE user_id = $1;
+
+-- name: RevokeOtherSessions :many
+UPDATE sessions SET revoked_at = now()
+WHERE user_id = sqlc.arg(user_id) AND id IS DISTINCT FROM sqlc.narg(keep_id) AND revoked_at IS NULL
+RETURNING id;
+
+-- name: AnonymizeUser :execrows
+UPDATE users SET email = 'deleted-' || id || '@deleted.invalid', password = NULL, full_name = NULL,
+    profile_image = NULL, phone = NULL, phone_verified = false, is_verified = false, last_login = NULL, updated_at = now(), deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: DeleteUserIdentitiesByUserID :exec
+DELETE FROM user_identities WHERE user_id = $1;
+
+-- name: GetSessionsByUserID :many
+SELECT * FROM sessions WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: GetPermissions :many
+SELECT * FROM permissions ORDER BY name;
+
+-- name: GetRoles :many
+SELECT * FROM roles ORDER BY created_at, name;
+
+-- name: GetRole :one
+SELECT * FROM roles WHERE name = $1 LIMIT 1;
+
+-- name: CreateRole :one
+INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING *;
+
+-- name: UpdateRole :one
+UPDATE roles SET description = $2, updated_at = now() WHERE name = $1 RETURNING *;
+
+-- name: DeleteRole :execrows
+DELETE FROM roles WHERE name = $1 AND NOT is_system;
+
+-- name: GetRolePermissions :many
+SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission;
+
+-- name: GetAllRolePermissions :many
+SELECT * FROM role_permissions ORDER BY role, permission;
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM role_permissions WHERE role = $1;
+
+-- name: AddRolePermissions :exec
+INSERT INTO role_permissions (role, permission)
+SELECT $1, unnest(sqlc.arg(permissions)::text[]);
+
+-- name: GetUserIDsByRole :many
+SELECT id FROM users WHERE role = $1 AND deleted_at IS NULL;
+
+-- name: SetUserOrganization :execrows
+UPDATE users SET organization_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetUsersByOrganizationID :many
+SELECT * FROM users WHERE organization_id = $1 AND deleted_at IS NULL
+ORDER BY created_at ASC;
+
+-- name: GetDeactivatedUserByEmail :one
+SELECT * FROM users WHERE email = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: DeactivateUser :one
+UPDATE users SET deleted_at = now(), updated_at = now()
 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: ReactivateUser :one
+-- Deleted accounts are anonymised and cannot come back
+UPDATE users SET deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND deleted_at IS NOT NULL AND email NOT LIKE '%@deleted.invalid' RETURNING *;
+
+-- name: CreateInvitation :one
+INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, now() + interval '72 hours') RETURNING *;
+
+-- name: SetUserPhone :one
+UPDATE users SET phone = $2, phone_verified = false, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: VerifyUserPhone :one
+UPDATE users SET phone_verified = true, updated_at = now()
+WHERE id = $1 AND phone = $2 AND deleted_at IS NULL RETURNING *;
+
+-- name: GetUserByVerifiedPhone :one
+SELECT * FROM users WHERE phone = $1 AND phone_verified AND deleted_at IS NULL LIMIT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP COLUMN channel_code;
+ALTER TABLE payments DROP COLUMN payment_channel;
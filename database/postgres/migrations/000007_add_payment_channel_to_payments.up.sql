@@ -0,0 +1,2 @@
+ALTER TABLE payments ADD COLUMN payment_channel VARCHAR(50) NOT NULL DEFAULT 'INVOICE';
+ALTER TABLE payments ADD COLUMN channel_code VARCHAR(50) DEFAULT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE locations DROP COLUMN convenience_fee;
+ALTER TABLE locations DROP COLUMN service_fee;
@@ -0,0 +1,2 @@
+ALTER TABLE locations ADD COLUMN service_fee NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE locations ADD COLUMN convenience_fee NUMERIC(12, 2) NOT NULL DEFAULT 0;
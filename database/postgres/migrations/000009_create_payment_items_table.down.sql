@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS payment_items;
+
+ALTER TABLE payments DROP COLUMN customer_phone;
+ALTER TABLE payments DROP COLUMN customer_name;
+ALTER TABLE payments DROP COLUMN description;
+
+COMMIT;
\ No newline at end of file
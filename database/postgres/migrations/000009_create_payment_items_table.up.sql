@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE payments ADD COLUMN description TEXT DEFAULT NULL;
+ALTER TABLE payments ADD COLUMN customer_name VARCHAR(255) DEFAULT NULL;
+ALTER TABLE payments ADD COLUMN customer_phone VARCHAR(50) DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS payment_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID REFERENCES payments(id) ON DELETE CASCADE NOT NULL,
+    reference_id VARCHAR(255) DEFAULT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    description TEXT DEFAULT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_price NUMERIC(12, 2) NOT NULL,
+    discount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    amount NUMERIC(12, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX idx_payment_items_payment_id ON payment_items(payment_id);
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE payments DROP COLUMN total_amount;
+ALTER TABLE payments DROP COLUMN tax_amount;
+ALTER TABLE payments DROP COLUMN subtotal;
+
+ALTER TABLE bookings DROP COLUMN tax_amount;
+ALTER TABLE bookings DROP COLUMN tax_inclusive;
+ALTER TABLE bookings DROP COLUMN tax_rate;
+ALTER TABLE bookings DROP COLUMN subtotal;
+
+ALTER TABLE locations DROP COLUMN tax_inclusive;
+ALTER TABLE locations DROP COLUMN tax_rate;
+
+COMMIT;
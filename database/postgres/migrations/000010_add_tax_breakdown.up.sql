@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE locations ADD COLUMN tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate BETWEEN 0 AND 100);
+ALTER TABLE locations ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE bookings ADD COLUMN subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE bookings ADD COLUMN tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+UPDATE bookings SET subtotal = total_price;
+
+ALTER TABLE payments ADD COLUMN subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN total_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+UPDATE payments p
+SET subtotal = b.total_price,
+    total_amount = b.total_price
+FROM bookings b
+WHERE b.id = p.booking_id;
+
+COMMIT;
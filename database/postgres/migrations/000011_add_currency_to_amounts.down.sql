@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE payments DROP COLUMN currency;
+ALTER TABLE bookings DROP COLUMN currency;
+ALTER TABLE locations DROP COLUMN currency;
+ALTER TABLE fields DROP COLUMN currency;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Amounts stay NUMERIC(12, 2) in major units (e.g. 150000.00 IDR); the currency column
+-- tells the application how many minor units each value has.
+ALTER TABLE fields ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE locations ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE bookings ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE payments ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
+
+COMMIT;
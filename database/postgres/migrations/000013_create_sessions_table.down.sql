@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_session;
+
+DROP TABLE IF EXISTS sessions;
+
+COMMIT;
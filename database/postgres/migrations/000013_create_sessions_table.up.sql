@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    device VARCHAR(255),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    last_seen_at TIMESTAMP DEFAULT now(),
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- Every refresh token family becomes a session, keeping logins issued before this migration alive
+INSERT INTO sessions (id, user_id, last_seen_at, revoked_at, created_at)
+SELECT family_id, (array_agg(user_id))[1], MAX(created_at), MAX(revoked_at), MIN(created_at)
+FROM refresh_tokens
+GROUP BY family_id;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
+
+COMMIT;
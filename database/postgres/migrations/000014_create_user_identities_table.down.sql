@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS google_id VARCHAR(255) UNIQUE;
+CREATE INDEX IF NOT EXISTS idx_users_google_id ON users(google_id);
+
+UPDATE users SET google_id = user_identities.subject
+FROM user_identities
+WHERE user_identities.user_id = users.id AND user_identities.provider = 'google';
+
+DROP TABLE IF EXISTS user_identities;
+
+COMMIT;
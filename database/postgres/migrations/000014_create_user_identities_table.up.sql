@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+INSERT INTO user_identities (user_id, provider, subject, email)
+SELECT id, 'google', google_id, email FROM users WHERE google_id IS NOT NULL AND google_id <> '';
+
+DROP INDEX IF EXISTS idx_users_google_id;
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Hashed tokens cannot be recovered, outstanding links stop working
+DELETE FROM email_verifications;
+DELETE FROM password_resets;
+
+DROP INDEX IF EXISTS idx_email_verifications_user_id;
+DROP INDEX IF EXISTS idx_password_resets_user_id;
+
+ALTER TABLE email_verifications ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE email_verifications RENAME COLUMN token_hash TO token;
+
+ALTER TABLE password_resets ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE password_resets RENAME COLUMN token_hash TO token;
+
+COMMIT;
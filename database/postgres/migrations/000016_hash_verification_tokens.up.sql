@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Tokens are stored as SHA-256 hex digests, hashing the existing ones keeps their links working
+ALTER TABLE email_verifications RENAME COLUMN token TO token_hash;
+UPDATE email_verifications SET token_hash = encode(sha256(token_hash::bytea), 'hex');
+ALTER TABLE email_verifications ALTER COLUMN token_hash TYPE VARCHAR(64);
+
+ALTER TABLE password_resets RENAME COLUMN token TO token_hash;
+UPDATE password_resets SET token_hash = encode(sha256(token_hash::bytea), 'hex');
+ALTER TABLE password_resets ALTER COLUMN token_hash TYPE VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user_id ON email_verifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets(user_id);
+
+COMMIT;
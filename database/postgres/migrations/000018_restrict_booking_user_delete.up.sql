@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Bookings are accounting records, deleting a user must not take them along. Accounts are
+-- soft deleted and anonymised instead.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_user_id_fkey;
+ALTER TABLE bookings ADD CONSTRAINT bookings_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Custom roles have no level and fall back to a regular user
+ALTER TABLE users ADD COLUMN level CHAR(1) NOT NULL DEFAULT '1';
+UPDATE users SET level = CASE role WHEN 'admin' THEN '9' WHEN 'staff' THEN '2' ELSE '1' END;
+
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN role;
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+
+COMMIT;
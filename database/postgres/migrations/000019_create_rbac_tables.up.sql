@@ -0,0 +1,54 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    -- System roles are relied on by the application and cannot be deleted
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE NOT NULL,
+    permission VARCHAR(100) REFERENCES permissions(name) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'List and view users'),
+    ('users:write', 'Change the role of users and unlock them'),
+    ('roles:manage', 'Create, change and delete roles'),
+    ('locations:write', 'Create, change and delete locations'),
+    ('fields:write', 'Create, change and delete fields and their images'),
+    ('bookings:read_any', 'List all bookings and view the receipt of any booking'),
+    ('bookings:cancel_any', 'Cancel the booking of any user'),
+    ('bookings:cash_payment', 'Create bookings paid in cash'),
+    ('payments:read', 'List all payments');
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('user', 'Customers booking fields', TRUE),
+    ('staff', 'Venue staff handling bookings and payments', TRUE),
+    ('admin', 'Full access', TRUE);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('staff', 'bookings:read_any'),
+    ('staff', 'bookings:cancel_any'),
+    ('staff', 'bookings:cash_payment'),
+    ('staff', 'payments:read');
+
+INSERT INTO role_permissions (role, permission) SELECT 'admin', name FROM permissions;
+
+-- Levels "1", "2" and "9" become the user, staff and admin roles
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user' REFERENCES roles(name) ON UPDATE CASCADE;
+UPDATE users SET role = CASE level WHEN '9' THEN 'admin' WHEN '2' THEN 'staff' ELSE 'user' END;
+ALTER TABLE users DROP COLUMN level;
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM permissions WHERE name IN ('locations:any', 'locations:staff');
+
+DROP INDEX IF EXISTS idx_location_staff_user_id;
+DROP TABLE IF EXISTS location_staff;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS location_staff (
+    location_id UUID REFERENCES locations(id) ON DELETE CASCADE NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (location_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_staff_user_id ON location_staff(user_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('locations:any', 'Manage every location instead of only the assigned ones'),
+    ('locations:staff', 'Assign staff to locations');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'locations:any'),
+    ('admin', 'locations:staff');
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+DELETE FROM permissions WHERE name = 'organizations:manage';
+
+DROP INDEX IF EXISTS idx_users_organization_id;
+DROP INDEX IF EXISTS idx_payments_organization_id;
+DROP INDEX IF EXISTS idx_bookings_organization_id;
+DROP INDEX IF EXISTS idx_fields_organization_id;
+DROP INDEX IF EXISTS idx_locations_organization_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE payments DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE fields DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE locations DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
+
+COMMIT;
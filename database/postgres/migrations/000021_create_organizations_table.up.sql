@@ -0,0 +1,48 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    -- Xendit credentials sealed with TENANT_ENCRYPTION_KEY, the global ones are used when unset
+    xendit_api_key TEXT DEFAULT NULL,
+    xendit_callback_token TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+-- Everything created before organisations belongs to the default one
+INSERT INTO organizations (slug, name) VALUES ('default', 'Default');
+
+ALTER TABLE locations ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE fields ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE bookings ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE payments ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+
+UPDATE locations SET organization_id = (SELECT id FROM organizations WHERE slug = 'default');
+UPDATE fields SET organization_id = (SELECT id FROM organizations WHERE slug = 'default');
+UPDATE bookings SET organization_id = (SELECT id FROM organizations WHERE slug = 'default');
+UPDATE payments SET organization_id = (SELECT id FROM organizations WHERE slug = 'default');
+
+ALTER TABLE locations ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE fields ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE bookings ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE payments ALTER COLUMN organization_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_locations_organization_id ON locations(organization_id);
+CREATE INDEX IF NOT EXISTS idx_fields_organization_id ON fields(organization_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_organization_id ON bookings(organization_id);
+CREATE INDEX IF NOT EXISTS idx_payments_organization_id ON payments(organization_id);
+
+-- Staff belong to one organisation and only have their permissions there. Users without one are
+-- customers, or platform admins when their role says so.
+ALTER TABLE users ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_organization_id ON users(organization_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('organizations:manage', 'Create and change organisations, their members and payment credentials');
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'organizations:manage');
+
+COMMIT;
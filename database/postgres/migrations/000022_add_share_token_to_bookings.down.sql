@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_bookings_share_token_hash;
+ALTER TABLE bookings DROP COLUMN share_token_hash;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE bookings ADD COLUMN share_token_hash VARCHAR(64) DEFAULT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_share_token_hash ON bookings(share_token_hash);
+
+COMMIT;
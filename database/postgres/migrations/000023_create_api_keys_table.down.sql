@@ -0,0 +1,10 @@
+BEGIN;
+
+DELETE FROM permissions WHERE name = 'api_keys:manage';
+
+ALTER TABLE bookings DROP COLUMN api_key_id;
+
+DROP INDEX IF EXISTS idx_api_keys_organization_id;
+DROP TABLE IF EXISTS api_keys;
+
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE NOT NULL,
+    -- Keys act as the user who created them
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    -- The start of the key, to tell keys apart without storing them
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    -- Requests allowed per minute
+    rate_limit INTEGER NOT NULL DEFAULT 60,
+    last_used_at TIMESTAMP DEFAULT NULL,
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_organization_id ON api_keys(organization_id);
+
+ALTER TABLE bookings ADD COLUMN api_key_id UUID REFERENCES api_keys(id) ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('api_keys:manage', 'Create, list and revoke API keys of the organisation');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'api_keys:manage');
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DELETE FROM permissions WHERE name = 'audit_logs:read';
+
+DROP INDEX IF EXISTS idx_audit_logs_entity;
+DROP INDEX IF EXISTS idx_audit_logs_organization_id_created_at;
+DROP TABLE IF EXISTS audit_logs;
+
+COMMIT;
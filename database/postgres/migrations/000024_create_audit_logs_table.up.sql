@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    -- No foreign keys on the actor, the trail outlives the users and keys
+    actor_user_id UUID DEFAULT NULL,
+    actor_api_key_id UUID DEFAULT NULL,
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    -- Changed fields as {"field": {"before": ..., "after": ...}}
+    changes JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR(100) DEFAULT NULL,
+    ip_address VARCHAR(45) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_organization_id_created_at ON audit_logs(organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs(entity_type, entity_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit_logs:read', 'Read the audit log of privileged actions');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'audit_logs:read');
+
+COMMIT;
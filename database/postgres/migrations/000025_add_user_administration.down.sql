@@ -0,0 +1,7 @@
+BEGIN;
+
+DELETE FROM permissions WHERE name = 'users:impersonate';
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS impersonator_user_id;
+
+COMMIT;
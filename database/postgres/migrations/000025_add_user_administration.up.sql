@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Set on entries of requests an admin made while impersonating the actor
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS impersonator_user_id UUID DEFAULT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Act as another user for support');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:impersonate');
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(16) DEFAULT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Anyone can enter a number, only its verified owner holds it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_verified_phone ON users(phone) WHERE phone_verified AND deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_bookings_guest_email;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_email;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_phone;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_name;
+ALTER TABLE bookings DROP COLUMN IF EXISTS created_by;
+
+COMMIT;
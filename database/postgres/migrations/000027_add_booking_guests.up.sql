@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_name VARCHAR(255) DEFAULT NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_phone VARCHAR(16) DEFAULT NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_email VARCHAR(255) DEFAULT NULL;
+
+-- Until now every booking was made by the customer it belongs to
+UPDATE bookings SET created_by = user_id WHERE created_by IS NULL;
+
+-- Guest bookings are attached by email when the guest registers
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_email ON bookings(lower(guest_email)) WHERE user_id IS NULL;
+
+COMMIT;
@@ -6,6 +6,7 @@ require (
 	github.com/air-verse/air v1.61.7
 	github.com/aws/aws-sdk-go v1.55.7
 	github.com/caarlos0/env/v11 v11.3.1
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/go-playground/validator/v10 v10.25.0
 	github.com/gofiber/fiber/v2 v2.52.6
 	github.com/gofiber/swagger v1.1.1
@@ -5,12 +5,14 @@ import (
 	"github.com/robfig/cron/v3"
 	"github.com/savioruz/goth/config"
 	"github.com/savioruz/goth/internal/domains/bookings/service"
+	userService "github.com/savioruz/goth/internal/domains/user/service"
 	"github.com/savioruz/goth/pkg/logger"
 	"github.com/savioruz/goth/pkg/postgres"
 )
 
 func Cron(db postgres.PgxIface, cfg *config.Config, l logger.Interface) {
 	schedulerService := service.NewSchedulerService(db, cfg)
+	userSchedulerService := userService.NewSchedulerService(db, l)
 
 	c := cron.New(cron.WithSeconds())
 
@@ -28,5 +30,19 @@ func Cron(db postgres.PgxIface, cfg *config.Config, l logger.Interface) {
 		return
 	}
 
+	_, err = c.AddFunc(cfg.Schedule.TokensCleanup, func() {
+		ctx := context.WithoutCancel(context.Background())
+
+		if err := userSchedulerService.CleanupExpiredTokens(ctx); err != nil {
+			l.Error("Cron job - CleanupExpiredTokens failed: %v", err)
+		}
+	})
+
+	if err != nil {
+		l.Error("Cron job - AddFunc failed: %v", err)
+
+		return
+	}
+
 	c.Start()
 }
@@ -4,6 +4,7 @@
 package app
 
 import (
+	"cmp"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
@@ -38,14 +39,33 @@ import (
 	paymentRepository "github.com/savioruz/goth/internal/domains/payments/repository"
 	paymentService "github.com/savioruz/goth/internal/domains/payments/service"
 
+	apiKeyHandler "github.com/savioruz/goth/internal/domains/apikeys/handler"
+	apiKeyRepository "github.com/savioruz/goth/internal/domains/apikeys/repository"
+	apiKeyService "github.com/savioruz/goth/internal/domains/apikeys/service"
+	auditLogHandler "github.com/savioruz/goth/internal/domains/auditlogs/handler"
+	auditLogRepository "github.com/savioruz/goth/internal/domains/auditlogs/repository"
+	auditLogService "github.com/savioruz/goth/internal/domains/auditlogs/service"
+
+	organizationHandler "github.com/savioruz/goth/internal/domains/organizations/handler"
+	organizationRepository "github.com/savioruz/goth/internal/domains/organizations/repository"
+	organizationService "github.com/savioruz/goth/internal/domains/organizations/service"
+
+	"github.com/savioruz/goth/pkg/apikey"
+	"github.com/savioruz/goth/pkg/audit"
 	"github.com/savioruz/goth/pkg/httpserver"
 	"github.com/savioruz/goth/pkg/jwt"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/loginguard"
 	"github.com/savioruz/goth/pkg/mail"
 	"github.com/savioruz/goth/pkg/oauth"
+	"github.com/savioruz/goth/pkg/otp"
 	"github.com/savioruz/goth/pkg/postgres"
 	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/secret"
+	"github.com/savioruz/goth/pkg/session"
+	"github.com/savioruz/goth/pkg/sms"
 	"github.com/savioruz/goth/pkg/supabase"
+	"github.com/savioruz/goth/pkg/tenant"
 )
 
 // Application represents the dependency-injected app
@@ -117,7 +137,43 @@ var paymentDomain = wire.NewSet(
 	paymentHandler.New,
 )
 
+func provideOrganizationQuerier() organizationRepository.Querier {
+	return organizationRepository.New()
+}
+
+var organizationDomain = wire.NewSet(
+	provideOrganizationQuerier,
+	organizationService.New,
+	organizationHandler.New,
+	wire.Bind(new(tenant.Resolver), new(organizationService.OrganizationService)),
+)
+
+func provideAPIKeyQuerier() apiKeyRepository.Querier {
+	return apiKeyRepository.New()
+}
+
+var apiKeyDomain = wire.NewSet(
+	provideAPIKeyQuerier,
+	apiKeyService.New,
+	apiKeyHandler.New,
+	wire.Bind(new(apikey.Authenticator), new(apiKeyService.APIKeyService)),
+)
+
+func provideAuditLogQuerier() auditLogRepository.Querier {
+	return auditLogRepository.New()
+}
+
+var auditLogDomain = wire.NewSet(
+	provideAuditLogQuerier,
+	auditLogService.New,
+	auditLogHandler.New,
+	wire.Bind(new(audit.Recorder), new(auditLogService.AuditLogService)),
+)
+
 var domains = wire.NewSet(
+	auditLogDomain,
+	organizationDomain,
+	apiKeyDomain,
 	userDomain,
 	authDomain,
 	oauthDomain,
@@ -137,9 +193,15 @@ func InitializeApp(cfg *config.Config) (*Application, error) {
 		provideRedis,
 		provideRedisCache,
 		provideJWT,
-		provideGoogleOAuth,
+		session.NewRedisRevoker,
+		provideLoginGuard,
+		provideOAuthProviders,
 		provideSupabaseClient,
+		wire.Bind(new(supabase.Storage), new(*supabase.Client)),
 		provideMailService,
+		provideSMSSender,
+		provideOTPService,
+		provideSecretBox,
 
 		domains,
 
@@ -159,6 +221,10 @@ func InitializeApp(cfg *config.Config) (*Application, error) {
 func provideRouter(
 	cfg *config.Config,
 	l logger.Interface,
+	revoker session.Revoker,
+	resolver tenant.Resolver,
+	authenticator apikey.Authenticator,
+	recorder audit.Recorder,
 	h http.Handlers,
 ) *fiber.App {
 	app := fiber.New()
@@ -167,6 +233,10 @@ func provideRouter(
 		app,
 		cfg,
 		l,
+		revoker,
+		resolver,
+		authenticator,
+		recorder,
 		h,
 	)
 
@@ -208,17 +278,64 @@ func provideValidator() *validator.Validate {
 	return validator.New(validator.WithRequiredStructEnabled())
 }
 
-func provideGoogleOAuth(cfg *config.Config) oauth.GoogleProviderIface {
-	return oauth.NewGoogleProvider(cfg.OAuth.Google.ClientID, cfg.OAuth.Google.ClientSecret, cfg.OAuth.Google.RedirectURL)
+const appleIssuerURL = "https://appleid.apple.com"
+
+func provideOAuthProviders(cfg *config.Config) *oauth.Registry {
+	providers := []oauth.Provider{
+		oauth.NewGoogleProvider(cfg.OAuth.Google.ClientID, cfg.OAuth.Google.ClientSecret, cfg.OAuth.Google.RedirectURL),
+	}
+
+	if github := cfg.OAuth.GitHub; github.ClientID != "" {
+		providers = append(providers, oauth.NewGitHubProvider(github.ClientID, github.ClientSecret, github.RedirectURL))
+	}
+
+	if apple := cfg.OAuth.Apple; apple.ClientID != "" {
+		if len(apple.Scopes) == 0 {
+			apple.Scopes = []string{"openid", "email", "name"}
+		}
+
+		providers = append(providers, oauth.NewOIDCProvider(oauth.OIDCConfig{
+			Name:         "apple",
+			IssuerURL:    cmp.Or(apple.IssuerURL, appleIssuerURL),
+			ClientID:     apple.ClientID,
+			ClientSecret: apple.ClientSecret,
+			RedirectURL:  apple.RedirectURL,
+			Scopes:       apple.Scopes,
+			FormPost:     true,
+		}))
+	}
+
+	if oidc := cfg.OAuth.OIDC; oidc.ClientID != "" {
+		providers = append(providers, oauth.NewOIDCProvider(oauth.OIDCConfig{
+			Name:         cmp.Or(oidc.Name, "oidc"),
+			IssuerURL:    oidc.IssuerURL,
+			ClientID:     oidc.ClientID,
+			ClientSecret: oidc.ClientSecret,
+			RedirectURL:  oidc.RedirectURL,
+			Scopes:       oidc.Scopes,
+		}))
+	}
+
+	return oauth.NewRegistry(providers...)
+}
+
+func provideLoginGuard(cfg *config.Config, cache redis.IRedisCache) loginguard.Guard {
+	return loginguard.NewRedisGuard(cache, loginguard.Config{
+		FreeAttempts:    cfg.Login.FreeAttempts,
+		MaxAttempts:     cfg.Login.MaxAttempts,
+		IPMaxAttempts:   cfg.Login.IPMaxAttempts,
+		LockoutDuration: cfg.Login.LockoutDuration,
+	})
 }
 
 func provideSupabaseClient(cfg *config.Config, l logger.Interface) (*supabase.Client, error) {
 	return supabase.NewClient(supabase.Config{
-		AccessKeyID:     cfg.Supabase.AccessKeyID,
-		SecretAccessKey: cfg.Supabase.SecretAccessKey,
-		EndpointURL:     cfg.Supabase.EndpointURL,
-		Region:          cfg.Supabase.Region,
-		BucketName:      cfg.Supabase.BucketName,
+		AccessKeyID:       cfg.Supabase.AccessKeyID,
+		SecretAccessKey:   cfg.Supabase.SecretAccessKey,
+		EndpointURL:       cfg.Supabase.EndpointURL,
+		Region:            cfg.Supabase.Region,
+		BucketName:        cfg.Supabase.BucketName,
+		PrivateBucketName: cfg.Supabase.PrivateBucketName,
 	})
 }
 
@@ -234,6 +351,28 @@ func provideMailService(cfg *config.Config) mail.Service {
 	})
 }
 
+func provideSMSSender(cfg *config.Config, l logger.Interface) (sms.Sender, error) {
+	switch cfg.SMS.Driver {
+	case "log":
+		return sms.NewLogSender(l), nil
+	default:
+		return nil, fmt.Errorf("unknown sms driver %q", cfg.SMS.Driver)
+	}
+}
+
+func provideOTPService(cfg *config.Config, cache redis.IRedisCache, sender sms.Sender) otp.Service {
+	return otp.NewRedisService(cache, sender, otp.Config{
+		AppName:        cfg.App.Name,
+		TTL:            cfg.OTP.TTL,
+		MaxAttempts:    cfg.OTP.MaxAttempts,
+		ResendCooldown: cfg.OTP.ResendCooldown,
+	})
+}
+
+func provideSecretBox(cfg *config.Config) (*secret.Box, error) {
+	return secret.New(cfg.Tenant.EncryptionKey)
+}
+
 func provideHTTPServer(cfg *config.Config, app *fiber.App) *httpserver.Server {
 	return httpserver.New(
 		httpserver.Port(cfg.HTTP.Port),
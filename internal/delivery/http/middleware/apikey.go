@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/pkg/apikey"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+)
+
+// Authenticate lets a request through with a bearer token like Jwt, or with an API key holding the
+// scope. Routes only accept API keys when they use it instead of Jwt.
+func (a *Auth) Authenticate(scope string) fiber.Handler {
+	jwt := a.Jwt()
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(constant.RequestHeaderAPIKey)
+		if key == "" {
+			return jwt(c)
+		}
+
+		principal, err := a.authenticator.Authenticate(c.UserContext(), key)
+		if err != nil {
+			return response.WithError(c, err)
+		}
+
+		if !slices.Contains(principal.Scopes, scope) {
+			return response.WithError(c, failure.Forbidden("api key is missing the "+scope+" scope"))
+		}
+
+		c.Locals(constant.JwtFieldUser, principal.UserID)
+		c.Locals(constant.JwtFieldEmail, principal.Email)
+		c.Locals(constant.JwtFieldPermissions, principal.Scopes)
+		c.Locals(constant.LocalsAPIKey, principal.KeyID.String())
+		c.SetUserContext(apikey.NewContext(c.UserContext(), principal.KeyID))
+		c.SetUserContext(audit.WithUser(c.UserContext(), principal.UserID, principal.KeyID.String()))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/pkg/audit"
+)
+
+// Audit puts the request ID and client address in the user context, for services to record with
+// their privileged actions. Auth.Jwt and Auth.Authenticate add the user once they know it.
+func Audit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID, _ := c.Locals(string(RequestIDKey)).(string)
+
+		c.SetUserContext(audit.NewContext(c.UserContext(), audit.Actor{
+			RequestID: requestID,
+			IPAddress: c.IP(),
+		}))
+
+		return c.Next()
+	}
+}
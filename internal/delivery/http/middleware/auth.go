@@ -3,14 +3,35 @@ package middleware
 import (
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/pkg/apikey"
+	"github.com/savioruz/goth/pkg/audit"
 	"github.com/savioruz/goth/pkg/jwt"
+	"github.com/savioruz/goth/pkg/session"
 )
 
-func Jwt() fiber.Handler {
+// Auth authenticates the requests of the routes that need a user.
+type Auth struct {
+	revoker       session.Revoker
+	recorder      audit.Recorder
+	authenticator apikey.Authenticator
+}
+
+// NewAuth makes the Jwt middleware reject access tokens of revoked sessions and record the requests
+// made while impersonating a user, and lets Authenticate check API keys with the authenticator.
+func NewAuth(rv session.Revoker, r audit.Recorder, a apikey.Authenticator) *Auth {
+	return &Auth{
+		revoker:       rv,
+		recorder:      r,
+		authenticator: a,
+	}
+}
+
+func (a *Auth) Jwt() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -33,12 +54,87 @@ func Jwt() fiber.Handler {
 			return response.WithError(c, err)
 		}
 
+		// Refresh tokens are only good for /auth/refresh, never as bearer credentials
+		if claims.TokenType != jwt.TokenTypeAccess {
+			err := failure.Unauthorized("invalid token type")
+
+			return response.WithError(c, err)
+		}
+
+		revoked, err := a.revoker.IsRevoked(c.UserContext(), claims)
+		if err != nil {
+			return response.WithError(c, failure.InternalError(err))
+		}
+
+		if revoked {
+			err := failure.Unauthorized("session has been revoked")
+
+			return response.WithError(c, err)
+		}
+
+		if claims.Impersonator != "" {
+			// Deactivating or logging out the admin everywhere ends their impersonations too
+			impersonator := *claims
+			impersonator.ID = claims.Impersonator
+
+			revoked, err := a.revoker.IsRevoked(c.UserContext(), &impersonator)
+			if err != nil {
+				return response.WithError(c, failure.InternalError(err))
+			}
+
+			if revoked {
+				err := failure.Unauthorized("session has been revoked")
+
+				return response.WithError(c, err)
+			}
+		}
+
 		if claims != nil {
 			c.Locals(constant.JwtFieldUser, claims.ID)
 			c.Locals(constant.JwtFieldEmail, claims.Email)
-			c.Locals(constant.JwtFieldLevel, claims.Level)
+			c.Locals(constant.JwtFieldRole, claims.Role)
+			c.Locals(constant.JwtFieldPermissions, tenantPermissions(c, claims))
+			c.Locals(constant.JwtFieldSession, claims.SessionID)
+			c.SetUserContext(audit.WithUser(c.UserContext(), claims.ID, ""))
+		}
+
+		if claims.Impersonator == "" {
+			return c.Next()
 		}
 
-		return c.Next()
+		c.Locals(constant.JwtFieldImpersonator, claims.Impersonator)
+		c.SetUserContext(audit.WithImpersonator(c.UserContext(), claims.Impersonator))
+
+		err = c.Next()
+
+		// Everything done while impersonating is traced back to the admin
+		a.recorder.Record(c.UserContext(), audit.Entry{
+			Action:     constant.AuditActionUserImpersonatedRequest,
+			EntityType: constant.AuditEntityUser,
+			EntityID:   claims.ID,
+			After: map[string]any{
+				"method": c.Method(),
+				"path":   c.Path(),
+				"status": c.Response().StatusCode(),
+			},
+		})
+
+		return err
+	}
+}
+
+// tenantPermissions limits staff of an organisation to its own requests. Elsewhere they are
+// treated as customers, and platform wide permissions are never theirs.
+func tenantPermissions(c *fiber.Ctx, claims *jwt.Claims) []string {
+	if claims.Organization == "" {
+		return claims.Permissions
+	}
+
+	if tenantID, _ := c.Locals(constant.LocalsTenant).(string); tenantID != claims.Organization {
+		return []string{}
 	}
+
+	return slices.DeleteFunc(slices.Clone(claims.Permissions), func(p string) bool {
+		return slices.Contains(constant.PlatformPermissions, p)
+	})
 }
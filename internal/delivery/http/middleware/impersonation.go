@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+)
+
+// NoImpersonation protects routes that change how an account is signed in to or whether it exists,
+// so support staff acting as a user cannot keep access after the impersonation token expires.
+// It must run after Jwt, which marks impersonated requests.
+func NoImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals(constant.JwtFieldImpersonator) != nil {
+			err := failure.Forbidden("not allowed while impersonating")
+
+			return response.WithError(c, err)
+		}
+
+		return c.Next()
+	}
+}
@@ -1,39 +1,57 @@
 package middleware
 
 import (
+	"slices"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/savioruz/goth/internal/delivery/http/response"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 )
 
-func CheckRole(allowedRoles ...string) fiber.Handler {
+// RequirePermission protects routes for users whose role has all the given permissions.
+// It must run after Jwt, which puts the permissions of the token in the context.
+func RequirePermission(permissions ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		role, ok := c.Locals(constant.JwtFieldLevel).(string)
+		granted, ok := c.Locals(constant.JwtFieldPermissions).([]string)
 		if !ok {
-			err := failure.Unauthorized("role information not found")
+			err := failure.Unauthorized("permission information not found")
 
 			return response.WithError(c, err)
 		}
 
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
-				return c.Next()
+		for _, permission := range permissions {
+			if !slices.Contains(granted, permission) {
+				err := failure.Forbidden("insufficient permissions")
+
+				return response.WithError(c, err)
 			}
 		}
 
-		err := failure.Forbidden("insufficient permissions")
-
-		return response.WithError(c, err)
+		return c.Next()
 	}
 }
 
-// AdminOnly protects routes for admin role.
-func AdminOnly() fiber.Handler {
-	return CheckRole(constant.UserRoleAdmin)
+// HasPermission reports whether the authenticated user's role has the permission, for handlers
+// whose behaviour depends on it rather than being denied.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	granted, _ := c.Locals(constant.JwtFieldPermissions).([]string)
+
+	return slices.Contains(granted, permission)
 }
 
-// StaffOrAdmin protects routes for staff or admin roles.
-func StaffOrAdmin() fiber.Handler {
-	return CheckRole(constant.UserRoleStaff, constant.UserRoleAdmin)
+// Principal returns the ID and permissions of the authenticated user, for handlers whose
+// services scope what the user can reach.
+func Principal(c *fiber.Ctx) (userID string, permissions []string, err error) {
+	userID, ok := c.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		return "", nil, failure.Unauthorized("user not authenticated")
+	}
+
+	permissions, ok = c.Locals(constant.JwtFieldPermissions).([]string)
+	if !ok {
+		return "", nil, failure.Unauthorized("permission information not found")
+	}
+
+	return userID, permissions, nil
 }
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+// Tenant resolves the organisation a request is for from the X-Tenant header or else the
+// subdomain of the base domain, falling back to the default organisation, and puts it in the
+// user context for the services to scope their queries by. The resolver looks the organisations up.
+func Tenant(cfg *config.Config, resolver tenant.Resolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		slug := tenantSlug(c, cfg.Tenant)
+
+		organizationID, err := resolver.Resolve(c.UserContext(), slug)
+		if err != nil {
+			return response.WithError(c, err)
+		}
+
+		c.Locals(constant.LocalsTenant, organizationID.String())
+		c.SetUserContext(tenant.NewContext(c.UserContext(), organizationID))
+
+		return c.Next()
+	}
+}
+
+func tenantSlug(c *fiber.Ctx, cfg config.Tenant) string {
+	if slug := strings.TrimSpace(c.Get(constant.RequestHeaderTenant)); slug != "" {
+		return strings.ToLower(slug)
+	}
+
+	if cfg.BaseDomain != "" {
+		host := strings.ToLower(c.Hostname())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if sub, ok := strings.CutSuffix(host, "."+cfg.BaseDomain); ok && sub != "" && !strings.Contains(sub, ".") {
+			return sub
+		}
+	}
+
+	return cfg.Default
+}
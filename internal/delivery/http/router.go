@@ -5,26 +5,36 @@ import (
 	"github.com/gofiber/swagger"
 	"github.com/savioruz/goth/config"
 	_ "github.com/savioruz/goth/docs" // Swagger docs
+	apiKeyHandler "github.com/savioruz/goth/internal/domains/apikeys/handler"
+	auditLogHandler "github.com/savioruz/goth/internal/domains/auditlogs/handler"
 	authHandler "github.com/savioruz/goth/internal/domains/auth/handler"
 	bookingHandler "github.com/savioruz/goth/internal/domains/bookings/handler"
 	fieldHandler "github.com/savioruz/goth/internal/domains/fields/handler"
 	locationHandler "github.com/savioruz/goth/internal/domains/locations/handler"
 	oauthHandler "github.com/savioruz/goth/internal/domains/oauth/handler"
+	organizationHandler "github.com/savioruz/goth/internal/domains/organizations/handler"
 	paymentHandler "github.com/savioruz/goth/internal/domains/payments/handler"
 	userHandler "github.com/savioruz/goth/internal/domains/user/handler"
 
 	"github.com/savioruz/goth/internal/delivery/http/middleware"
+	"github.com/savioruz/goth/pkg/apikey"
+	"github.com/savioruz/goth/pkg/audit"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/session"
+	"github.com/savioruz/goth/pkg/tenant"
 )
 
 type Handlers struct {
-	Auth     *authHandler.Handler
-	OAuth    *oauthHandler.Handler
-	User     *userHandler.Handler
-	Location *locationHandler.Handler
-	Field    *fieldHandler.Handler
-	Booking  *bookingHandler.Handler
-	Payment  *paymentHandler.Handler
+	Auth         *authHandler.Handler
+	OAuth        *oauthHandler.Handler
+	User         *userHandler.Handler
+	Organization *organizationHandler.Handler
+	APIKey       *apiKeyHandler.Handler
+	AuditLog     *auditLogHandler.Handler
+	Location     *locationHandler.Handler
+	Field        *fieldHandler.Handler
+	Booking      *bookingHandler.Handler
+	Payment      *paymentHandler.Handler
 }
 
 // NewRouter initializes the HTTP router and registers the routes for the application.
@@ -34,31 +44,44 @@ type Handlers struct {
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func NewRouter(
 	app *fiber.App,
 	cfg *config.Config,
 	l logger.Interface,
+	revoker session.Revoker,
+	resolver tenant.Resolver,
+	authenticator apikey.Authenticator,
+	recorder audit.Recorder,
 	handlers Handlers,
 ) {
 	// Options
 	app.Use(middleware.Logger(l))
 	app.Use(middleware.Recovery(l))
 	app.Use(middleware.RequestID())
+	app.Use(middleware.Audit())
 	app.Use(middleware.CORS(cfg))
 
 	if cfg.Swagger.Enabled {
 		app.Get("/swagger/*", swagger.HandlerDefault)
 	}
 
-	apiV1Group := app.Group("/v1")
+	authn := middleware.NewAuth(revoker, recorder, authenticator)
+
+	apiV1Group := app.Group("/v1", middleware.Tenant(cfg, resolver))
 	{
-		handlers.Auth.RegisterRoutes(apiV1Group)
-		handlers.OAuth.RegisterRoutes(apiV1Group)
-		handlers.User.RegisterRoutes(apiV1Group)
-		handlers.Location.RegisterRoutes(apiV1Group)
-		handlers.Field.RegisterRoutes(apiV1Group)
-		handlers.Booking.RegisterRoutes(apiV1Group)
-		handlers.Payment.RegisterRoutes(apiV1Group)
+		handlers.Auth.RegisterRoutes(apiV1Group, authn)
+		handlers.OAuth.RegisterRoutes(apiV1Group, authn)
+		handlers.User.RegisterRoutes(apiV1Group, authn)
+		handlers.Organization.RegisterRoutes(apiV1Group, authn)
+		handlers.APIKey.RegisterRoutes(apiV1Group, authn)
+		handlers.AuditLog.RegisterRoutes(apiV1Group, authn)
+		handlers.Location.RegisterRoutes(apiV1Group, authn)
+		handlers.Field.RegisterRoutes(apiV1Group, authn)
+		handlers.Booking.RegisterRoutes(apiV1Group, authn)
+		handlers.Payment.RegisterRoutes(apiV1Group, authn)
 	}
 
 	app.Use("*", func(c *fiber.Ctx) error {
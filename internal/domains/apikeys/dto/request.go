@@ -0,0 +1,9 @@
+package dto
+
+// CreateAPIKeyRequest creates a key acting as the requesting user. Scopes beyond bookings:read and
+// bookings:write delegate permissions, which the user must hold themselves.
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" validate:"required,max=255" example:"Front desk kiosk"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,required" example:"bookings:read,bookings:write"`
+	RateLimit int      `json:"rate_limit" validate:"omitempty,min=1,max=10000" example:"60"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"github.com/savioruz/goth/internal/domains/apikeys/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+type APIKeyResponse struct {
+	ID     string   `json:"id"`
+	UserID string   `json:"user_id"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// RateLimit is the number of requests allowed per minute
+	RateLimit  int    `json:"rate_limit"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (a APIKeyResponse) FromModel(model repository.ApiKey) APIKeyResponse {
+	res := APIKeyResponse{
+		ID:        model.ID.String(),
+		UserID:    model.UserID.String(),
+		Name:      model.Name,
+		Prefix:    model.Prefix,
+		Scopes:    model.Scopes,
+		RateLimit: int(model.RateLimit),
+		CreatedAt: model.CreatedAt.Time.Format(constant.FullDateFormat),
+	}
+
+	if model.LastUsedAt.Valid {
+		res.LastUsedAt = model.LastUsedAt.Time.Format(constant.FullDateFormat)
+	}
+
+	if model.RevokedAt.Valid {
+		res.RevokedAt = model.RevokedAt.Time.Format(constant.FullDateFormat)
+	}
+
+	return res
+}
+
+// CreateAPIKeyResponse carries the key itself, which is only ever returned here.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type PaginatedAPIKeyResponse struct {
+	APIKeys    []APIKeyResponse `json:"api_keys"`
+	TotalItems int              `json:"total_items"`
+	TotalPages int              `json:"total_pages"`
+}
+
+func (a *PaginatedAPIKeyResponse) FromModel(keys []repository.ApiKey, totalItems, limit int) {
+	a.TotalItems = totalItems
+	a.TotalPages = helper.CalculateTotalPages(totalItems, limit)
+
+	a.APIKeys = make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		a.APIKeys[i] = APIKeyResponse{}.FromModel(key)
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/internal/domains/apikeys/dto"
+	"github.com/savioruz/goth/internal/domains/apikeys/service"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/logger"
+)
+
+type Handler struct {
+	service   service.APIKeyService
+	logger    logger.Interface
+	validator *validator.Validate
+}
+
+func New(s service.APIKeyService, l logger.Interface, v *validator.Validate) *Handler {
+	return &Handler{
+		service:   s,
+		logger:    l,
+		validator: v,
+	}
+}
+
+const (
+	identifier = "http - api key - %s"
+
+	routePath = "/api-keys"
+)
+
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
+	keys := r.Group(routePath, authn.Jwt(), middleware.NoImpersonation(), middleware.RequirePermission(constant.PermissionAPIKeysManage))
+
+	keys.Post("/", h.Create)
+	keys.Get("/", h.GetAll)
+	keys.Delete("/:id", h.Revoke)
+}
+
+// Create API Key godoc
+// @Summary Create API key (requires api_keys:manage)
+// @Description Create an API key acting as the requesting user within its scopes. The key is only returned once
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param api_key body dto.CreateAPIKeyRequest true "API key create request"
+// @Success 201 {object} response.Data[dto.CreateAPIKeyResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api-keys/ [post]
+// @Security BearerAuth
+func (h *Handler) Create(ctx *fiber.Ctx) error {
+	var req dto.CreateAPIKeyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "create - body parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "create - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "create - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.Create(ctx.UserContext(), req, userID, permissions)
+	if err != nil {
+		h.logger.Error(identifier, "create - failed to create api key: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusCreated, res)
+}
+
+// GetAll API Keys godoc
+// @Summary Get all API keys (requires api_keys:manage)
+// @Description Get the API keys of the organization, including revoked ones
+// @Tags api-keys
+// @Produce json
+// @Param request query gdto.PaginationRequest false "Pagination request"
+// @Success 200 {object} response.Data[dto.PaginatedAPIKeyResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api-keys/ [get]
+// @Security BearerAuth
+func (h *Handler) GetAll(ctx *fiber.Ctx) error {
+	var req gdto.PaginationRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - query parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetAll(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error(identifier, "get all - failed to get api keys: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// Revoke API Key godoc
+// @Summary Revoke API key (requires api_keys:manage)
+// @Description Revoke an API key, requests made with it are rejected from then on
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api-keys/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) Revoke(ctx *fiber.Ctx) error {
+	id := ctx.Params(constant.RequestParamID)
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		err = failure.BadRequestFromString("invalid api key id format")
+
+		h.logger.Error(identifier, "revoke - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.Revoke(ctx.UserContext(), id); err != nil {
+		h.logger.Error(identifier, "revoke - failed to revoke api key: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "api key revoked")
+}
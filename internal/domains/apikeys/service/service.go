@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/domains/apikeys/dto"
+	"github.com/savioruz/goth/internal/domains/apikeys/repository"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/apikey"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+type APIKeyService interface {
+	Authenticate(ctx context.Context, key string) (apikey.Principal, error)
+	Create(ctx context.Context, req dto.CreateAPIKeyRequest, userID string, permissions []string) (res dto.CreateAPIKeyResponse, err error)
+	GetAll(ctx context.Context, req gdto.PaginationRequest) (res dto.PaginatedAPIKeyResponse, err error)
+	Revoke(ctx context.Context, id string) (err error)
+}
+
+type apiKeyService struct {
+	db       postgres.PgxIface
+	repo     repository.Querier
+	userRepo userRepository.Querier
+	cache    redis.IRedisCache
+	cfg      *config.Config
+	logger   logger.Interface
+	auditor  audit.Recorder
+}
+
+func New(db postgres.PgxIface, repo repository.Querier, u userRepository.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface, auditor audit.Recorder) APIKeyService {
+	return &apiKeyService{
+		db:       db,
+		repo:     repo,
+		userRepo: u,
+		cache:    cache,
+		cfg:      cfg,
+		logger:   l,
+		auditor:  auditor,
+	}
+}
+
+const (
+	keyPrefix        = "gk_"
+	keyLength        = 32
+	displayLength    = len(keyPrefix) + 8
+	defaultRateLimit = 60
+
+	// Rate limits are counted in fixed windows of a minute
+	rateLimitKey    = "api_key:rate:%s"
+	rateLimitWindow = 60
+
+	identifier = "service - api key - %s"
+)
+
+// Authenticate looks the key up by its hash in the request's organisation and counts the request
+// against the key's rate limit.
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (res apikey.Principal, err error) {
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, s.db, repository.GetAPIKeyByHashParams{
+		KeyHash:        helper.HashToken(key),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, failure.Unauthorized("invalid api key")
+		}
+
+		s.logger.Error(identifier, "authenticate - failed to get api key: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	requests, err := s.cache.Incr(ctx, fmt.Sprintf(rateLimitKey, apiKey.ID.String()), rateLimitWindow)
+	if err != nil {
+		s.logger.Error(identifier, "authenticate - failed to count request: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if requests > int64(apiKey.RateLimit) {
+		s.logger.Info(identifier, "authenticate - api key %s - rate limit exceeded", apiKey.ID.String())
+
+		return res, failure.TooManyRequests("api key rate limit exceeded")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, s.db, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, "authenticate - owner of api key %s - not found", apiKey.ID.String())
+
+			return res, failure.Unauthorized("invalid api key")
+		}
+
+		s.logger.Error(identifier, "authenticate - failed to get owner of api key: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	// Roles change after keys are issued, a key never does more than its owner may do now
+	permissions, err := s.userRepo.GetRolePermissions(ctx, s.db, user.Role)
+	if err != nil {
+		s.logger.Error(identifier, "authenticate - failed to get permissions of owner: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	permissions = ownerPermissions(user, apiKey.OrganizationID, permissions)
+
+	go func() {
+		if err := s.repo.TouchAPIKey(context.WithoutCancel(ctx), s.db, apiKey.ID); err != nil {
+			s.logger.Error(identifier, "authenticate - failed to record last use: %w", err)
+		}
+	}()
+
+	return apikey.Principal{
+		KeyID:  apiKey.ID,
+		UserID: user.ID.String(),
+		Email:  user.Email,
+		Scopes: slices.DeleteFunc(slices.Clone(apiKey.Scopes), func(scope string) bool {
+			return delegated(scope) && !slices.Contains(permissions, scope)
+		}),
+	}, nil
+}
+
+// delegated reports whether the scope passes on a permission of the key's owner. Every user may
+// manage their own bookings.
+func delegated(scope string) bool {
+	return scope != constant.APIKeyScopeBookingsRead && scope != constant.APIKeyScopeBookingsWrite
+}
+
+// ownerPermissions limits staff bound to an organisation the same way their access tokens are:
+// nothing outside it, and never platform wide permissions.
+func ownerPermissions(user userRepository.User, organizationID pgtype.UUID, permissions []string) []string {
+	if !user.OrganizationID.Valid {
+		return permissions
+	}
+
+	if user.OrganizationID != organizationID {
+		return []string{}
+	}
+
+	return slices.DeleteFunc(permissions, func(p string) bool {
+		return slices.Contains(constant.PlatformPermissions, p)
+	})
+}
+
+// Create issues a key acting as the user. The key is only returned here, just its hash is stored.
+func (s *apiKeyService) Create(ctx context.Context, req dto.CreateAPIKeyRequest, userID string, permissions []string) (res dto.CreateAPIKeyResponse, err error) {
+	scopes := slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+
+	for _, scope := range scopes {
+		if !slices.Contains(constant.APIKeyScopes, scope) {
+			return res, failure.BadRequestFromString(fmt.Sprintf("unknown scope %s", scope))
+		}
+
+		if delegated(scope) && !slices.Contains(permissions, scope) {
+			s.logger.Error(identifier, "create - user %s cannot delegate %s", userID, scope)
+
+			return res, failure.Forbidden(fmt.Sprintf("you do not hold the %s permission", scope))
+		}
+	}
+
+	rateLimit := req.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultRateLimit
+	}
+
+	token, err := helper.GenerateRandomToken(keyLength)
+	if err != nil {
+		s.logger.Error(identifier, "create - failed to generate key: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	key := keyPrefix + token
+
+	apiKey, err := s.repo.CreateAPIKey(ctx, s.db, repository.CreateAPIKeyParams{
+		OrganizationID: tenant.FromContext(ctx),
+		UserID:         helper.PgUUID(userID),
+		Name:           req.Name,
+		Prefix:         key[:displayLength],
+		KeyHash:        helper.HashToken(key),
+		Scopes:         scopes,
+		RateLimit:      int32(rateLimit),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "create - failed to create api key: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = dto.CreateAPIKeyResponse{
+		APIKeyResponse: dto.APIKeyResponse{}.FromModel(apiKey),
+		Key:            key,
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionAPIKeyCreate,
+		EntityType: constant.AuditEntityAPIKey,
+		EntityID:   res.ID,
+		After:      res.APIKeyResponse,
+	})
+
+	return res, nil
+}
+
+func (s *apiKeyService) GetAll(ctx context.Context, req gdto.PaginationRequest) (res dto.PaginatedAPIKeyResponse, err error) {
+	page, limit := helper.DefaultPagination(req.Page, req.Limit)
+
+	totalItems, err := s.repo.CountAPIKeys(ctx, s.db, tenant.FromContext(ctx))
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to count api keys: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	keys, err := s.repo.GetAPIKeys(ctx, s.db, repository.GetAPIKeysParams{
+		OrganizationID: tenant.FromContext(ctx),
+		Limit:          int32(limit),
+		Offset:         int32(helper.CalculateOffset(page, limit)),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to get api keys: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res.FromModel(keys, int(totalItems), limit)
+
+	return res, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id string) (err error) {
+	revoked, err := s.repo.RevokeAPIKey(ctx, s.db, repository.RevokeAPIKeyParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "revoke - failed to revoke api key: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if revoked == 0 {
+		s.logger.Error(identifier, "revoke - api key %s - not found", id)
+
+		return failure.NotFound(fmt.Sprintf("api key %s - not found", id))
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionAPIKeyRevoke,
+		EntityType: constant.AuditEntityAPIKey,
+		EntityID:   id,
+	})
+
+	return nil
+}
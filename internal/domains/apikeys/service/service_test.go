@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/domains/apikeys/dto"
+	"github.com/savioruz/goth/internal/domains/apikeys/mock"
+	"github.com/savioruz/goth/internal/domains/apikeys/repository"
+	userMock "github.com/savioruz/goth/internal/domains/user/mock"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	auditMock "github.com/savioruz/goth/pkg/audit/mock"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	redis "github.com/savioruz/goth/pkg/redis/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	cfg := &config.Config{}
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockUserQuerier := userMock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRedis := redis.NewMockIRedisCache(ctrl)
+	mockLogger := log.NewMockInterface(ctrl)
+
+	service := New(mockPgx, mockQuerier, mockUserQuerier, mockRedis, cfg, mockLogger, nil)
+
+	key := "gk_secret"
+	apiKey := repository.ApiKey{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Scopes:    []string{constant.APIKeyScopeBookingsRead},
+		RateLimit: 2,
+	}
+	rateKey := fmt.Sprintf(rateLimitKey, apiKey.ID.String())
+
+	mockQuerier.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any(), apiKey.ID).Return(nil).AnyTimes()
+
+	t.Run("success", func(t *testing.T) {
+		mockQuerier.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.GetAPIKeyByHashParams) (repository.ApiKey, error) {
+				assert.Equal(t, helper.HashToken(key), arg.KeyHash)
+
+				return apiKey, nil
+			})
+		mockRedis.EXPECT().Incr(gomock.Any(), rateKey, rateLimitWindow).Return(int64(1), nil)
+		mockUserQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), apiKey.UserID).Return(userRepository.User{
+			ID:    apiKey.UserID,
+			Email: "kiosk@example.com",
+			Role:  constant.UserRoleUser,
+		}, nil)
+		mockUserQuerier.EXPECT().GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleUser).Return(nil, nil)
+
+		principal, err := service.Authenticate(ctx, key)
+
+		assert.NoError(t, err)
+		assert.Equal(t, apiKey.ID, principal.KeyID)
+		assert.Equal(t, apiKey.UserID.String(), principal.UserID)
+		assert.Equal(t, "kiosk@example.com", principal.Email)
+		assert.Equal(t, apiKey.Scopes, principal.Scopes)
+	})
+
+	t.Run("success: drops delegated scopes the owner no longer holds", func(t *testing.T) {
+		staffKey := apiKey
+		staffKey.Scopes = []string{constant.APIKeyScopeBookingsRead, constant.PermissionBookingsCancelAny, constant.PermissionPaymentsRead}
+
+		mockQuerier.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(staffKey, nil)
+		mockRedis.EXPECT().Incr(gomock.Any(), rateKey, rateLimitWindow).Return(int64(1), nil)
+		mockUserQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), apiKey.UserID).Return(userRepository.User{
+			ID:   apiKey.UserID,
+			Role: constant.UserRoleStaff,
+		}, nil)
+		// The role lost bookings:cancel_any after the key was created
+		mockUserQuerier.EXPECT().
+			GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleStaff).
+			Return([]string{constant.PermissionPaymentsRead}, nil)
+
+		principal, err := service.Authenticate(ctx, key)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{constant.APIKeyScopeBookingsRead, constant.PermissionPaymentsRead}, principal.Scopes)
+	})
+
+	t.Run("error: unknown or revoked key", func(t *testing.T) {
+		mockQuerier.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.ApiKey{}, pgx.ErrNoRows)
+
+		_, err := service.Authenticate(ctx, key)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: rate limit exceeded", func(t *testing.T) {
+		mockQuerier.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(apiKey, nil)
+		mockRedis.EXPECT().Incr(gomock.Any(), rateKey, rateLimitWindow).Return(int64(3), nil)
+		mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := service.Authenticate(ctx, key)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, failure.GetCode(err))
+	})
+}
+
+func TestAPIKeyService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockAuditor := auditMock.NewMockRecorder(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, &config.Config{}, mockLogger, mockAuditor)
+
+	userID := uuid.New().String()
+
+	t.Run("success: stores the hash of the key", func(t *testing.T) {
+		mockQuerier.EXPECT().
+			CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CreateAPIKeyParams) (repository.ApiKey, error) {
+				assert.Equal(t, []string{constant.APIKeyScopeBookingsRead, constant.APIKeyScopeBookingsWrite}, arg.Scopes)
+				assert.Equal(t, int32(defaultRateLimit), arg.RateLimit)
+
+				return repository.ApiKey{KeyHash: arg.KeyHash, Prefix: arg.Prefix, Scopes: arg.Scopes}, nil
+			})
+		mockAuditor.EXPECT().Record(gomock.Any(), gomock.Any())
+
+		res, err := service.Create(ctx, dto.CreateAPIKeyRequest{
+			Name:   "Kiosk",
+			Scopes: []string{constant.APIKeyScopeBookingsWrite, constant.APIKeyScopeBookingsRead, constant.APIKeyScopeBookingsRead},
+		}, userID, nil)
+
+		assert.NoError(t, err)
+		assert.Contains(t, res.Key, keyPrefix)
+		assert.Equal(t, res.Key[:displayLength], res.Prefix)
+	})
+
+	t.Run("error: unknown scope", func(t *testing.T) {
+		_, err := service.Create(ctx, dto.CreateAPIKeyRequest{Name: "Kiosk", Scopes: []string{"users:write"}}, userID, []string{"users:write"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: delegating a permission the user does not hold", func(t *testing.T) {
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := service.Create(ctx, dto.CreateAPIKeyRequest{Name: "Kiosk", Scopes: []string{constant.PermissionPaymentsRead}}, userID, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
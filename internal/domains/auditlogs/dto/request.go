@@ -0,0 +1,12 @@
+package dto
+
+type GetAuditLogsRequest struct {
+	Page        int    `json:"page" query:"page" validate:"omitempty,numeric,min=1"`
+	Limit       int    `json:"limit" query:"limit" validate:"omitempty,numeric,min=1,max=100"`
+	ActorUserID string `json:"actor_user_id" query:"actor_user_id" validate:"omitempty,uuid"`
+	Action      string `json:"action" query:"action" validate:"omitempty,max=100" example:"field.update"`
+	EntityType  string `json:"entity_type" query:"entity_type" validate:"omitempty,max=50" example:"field"`
+	EntityID    string `json:"entity_id" query:"entity_id" validate:"omitempty,max=255"`
+	DateFrom    string `json:"date_from" query:"date_from" validate:"omitempty,datetime=2006-01-02" example:"2006-01-02"`
+	DateTo      string `json:"date_to" query:"date_to" validate:"omitempty,datetime=2006-01-02" example:"2006-01-02"`
+}
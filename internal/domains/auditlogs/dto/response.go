@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/savioruz/goth/internal/domains/auditlogs/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+type AuditLogResponse struct {
+	ID                 string                  `json:"id"`
+	ActorUserID        string                  `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID      string                  `json:"actor_api_key_id,omitempty"`
+	ImpersonatorUserID string                  `json:"impersonator_user_id,omitempty"`
+	Action             string                  `json:"action"`
+	EntityType         string                  `json:"entity_type"`
+	EntityID           string                  `json:"entity_id"`
+	Changes            map[string]audit.Change `json:"changes"`
+	RequestID          string                  `json:"request_id,omitempty"`
+	IPAddress          string                  `json:"ip_address,omitempty"`
+	CreatedAt          string                  `json:"created_at"`
+}
+
+func (a AuditLogResponse) FromModel(model repository.AuditLog) AuditLogResponse {
+	res := AuditLogResponse{
+		ID:                 model.ID.String(),
+		ActorUserID:        model.ActorUserID.String(),
+		ActorAPIKeyID:      model.ActorApiKeyID.String(),
+		ImpersonatorUserID: model.ImpersonatorUserID.String(),
+		Action:             model.Action,
+		EntityType:         model.EntityType,
+		EntityID:           model.EntityID,
+		Changes:            map[string]audit.Change{},
+		RequestID:          model.RequestID.String,
+		IPAddress:          model.IpAddress.String,
+		CreatedAt:          model.CreatedAt.Time.Format(constant.FullDateFormat),
+	}
+
+	_ = json.Unmarshal(model.Changes, &res.Changes)
+
+	return res
+}
+
+type PaginatedAuditLogResponse struct {
+	AuditLogs  []AuditLogResponse `json:"audit_logs"`
+	TotalItems int                `json:"total_items"`
+	TotalPages int                `json:"total_pages"`
+}
+
+func (a *PaginatedAuditLogResponse) FromModel(logs []repository.AuditLog, totalItems, limit int) {
+	a.TotalItems = totalItems
+	a.TotalPages = helper.CalculateTotalPages(totalItems, limit)
+
+	a.AuditLogs = make([]AuditLogResponse, len(logs))
+	for i, log := range logs {
+		a.AuditLogs[i] = AuditLogResponse{}.FromModel(log)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/internal/domains/auditlogs/dto"
+	"github.com/savioruz/goth/internal/domains/auditlogs/service"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/logger"
+)
+
+type Handler struct {
+	service   service.AuditLogService
+	logger    logger.Interface
+	validator *validator.Validate
+}
+
+func New(s service.AuditLogService, l logger.Interface, v *validator.Validate) *Handler {
+	return &Handler{
+		service:   s,
+		logger:    l,
+		validator: v,
+	}
+}
+
+const (
+	identifier = "http - audit log - %s"
+
+	routePath = "/admin/audit-logs"
+)
+
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
+	r.Get(routePath, authn.Jwt(), middleware.RequirePermission(constant.PermissionAuditLogsRead), h.GetAll)
+}
+
+// GetAll Audit Logs godoc
+// @Summary Get audit logs (requires audit_logs:read)
+// @Description Get the trail of privileged actions in the organization, newest first
+// @Tags admin
+// @Produce json
+// @Param request query dto.GetAuditLogsRequest false "Audit log filters"
+// @Success 200 {object} response.Data[dto.PaginatedAuditLogResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /admin/audit-logs [get]
+// @Security BearerAuth
+func (h *Handler) GetAll(ctx *fiber.Ctx) error {
+	var req dto.GetAuditLogsRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - query parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetAll(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error(identifier, "get all - failed to get audit logs: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/auditlogs/dto"
+	"github.com/savioruz/goth/internal/domains/auditlogs/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+type AuditLogService interface {
+	audit.Recorder
+	GetAll(ctx context.Context, req dto.GetAuditLogsRequest) (res dto.PaginatedAuditLogResponse, err error)
+}
+
+type auditLogService struct {
+	db     postgres.PgxIface
+	repo   repository.Querier
+	logger logger.Interface
+}
+
+func New(db postgres.PgxIface, repo repository.Querier, l logger.Interface) AuditLogService {
+	return &auditLogService{
+		db:     db,
+		repo:   repo,
+		logger: l,
+	}
+}
+
+const identifier = "service - audit log - %s"
+
+func (s *auditLogService) Record(ctx context.Context, entry audit.Entry) {
+	changes, err := audit.Diff(entry.Before, entry.After)
+	if err != nil {
+		s.logger.Error(identifier, "record - failed to diff %s of %s %s: %w", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		s.logger.Error(identifier, "record - failed to encode changes: %w", err)
+
+		raw = []byte("{}")
+	}
+
+	actor := audit.FromContext(ctx)
+
+	err = s.repo.InsertAuditLog(context.WithoutCancel(ctx), s.db, repository.InsertAuditLogParams{
+		OrganizationID:     tenant.FromContext(ctx),
+		ActorUserID:        helper.PgUUID(actor.UserID),
+		ActorApiKeyID:      helper.PgUUID(actor.APIKeyID),
+		ImpersonatorUserID: helper.PgUUID(actor.ImpersonatorID),
+		Action:             entry.Action,
+		EntityType:         entry.EntityType,
+		EntityID:           entry.EntityID,
+		Changes:            raw,
+		RequestID:          optionalText(actor.RequestID),
+		IpAddress:          optionalText(actor.IPAddress),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "record - failed to store %s of %s %s by %s: %w", entry.Action, entry.EntityType, entry.EntityID, actor.UserID, err)
+	}
+}
+
+func (s *auditLogService) GetAll(ctx context.Context, req dto.GetAuditLogsRequest) (res dto.PaginatedAuditLogResponse, err error) {
+	if req.DateFrom != "" && req.DateTo != "" && req.DateTo < req.DateFrom {
+		return res, failure.BadRequestFromString("date_to must not be before date_from")
+	}
+
+	page, limit := helper.DefaultPagination(req.Page, req.Limit)
+
+	totalItems, err := s.repo.CountAuditLogs(ctx, s.db, repository.CountAuditLogsParams{
+		OrganizationID: tenant.FromContext(ctx),
+		ActorUserID:    helper.PgUUID(req.ActorUserID),
+		Action:         req.Action,
+		EntityType:     req.EntityType,
+		EntityID:       req.EntityID,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to count audit logs: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	logs, err := s.repo.GetAuditLogs(ctx, s.db, repository.GetAuditLogsParams{
+		OrganizationID: tenant.FromContext(ctx),
+		ActorUserID:    helper.PgUUID(req.ActorUserID),
+		Action:         req.Action,
+		EntityType:     req.EntityType,
+		EntityID:       req.EntityID,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
+		LimitCount:     int32(limit),
+		OffsetCount:    int32(helper.CalculateOffset(page, limit)),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to get audit logs: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res.FromModel(logs, int(totalItems), limit)
+
+	return res, nil
+}
+
+func optionalText(s string) (res pgtype.Text) {
+	if s == "" {
+		return res
+	}
+
+	return helper.PgString(s)
+}
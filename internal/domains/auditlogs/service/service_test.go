@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/internal/domains/auditlogs/dto"
+	"github.com/savioruz/goth/internal/domains/auditlogs/mock"
+	"github.com/savioruz/goth/internal/domains/auditlogs/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	"github.com/savioruz/goth/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuditLogService_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+
+	service := New(mockPgx, mockQuerier, mockLogger)
+
+	organizationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	userID := uuid.New().String()
+
+	ctx := tenant.NewContext(context.Background(), organizationID)
+	ctx = audit.NewContext(ctx, audit.Actor{RequestID: "request-1", IPAddress: "10.0.0.1"})
+	ctx = audit.WithUser(ctx, userID, "")
+
+	t.Run("success: stores the actor and the changed fields", func(t *testing.T) {
+		mockQuerier.EXPECT().
+			InsertAuditLog(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.InsertAuditLogParams) error {
+				assert.Equal(t, organizationID, arg.OrganizationID)
+				assert.Equal(t, userID, arg.ActorUserID.String())
+				assert.False(t, arg.ActorApiKeyID.Valid)
+				assert.Equal(t, "request-1", arg.RequestID.String)
+				assert.Equal(t, "10.0.0.1", arg.IpAddress.String)
+				assert.JSONEq(t, `{"role":{"before":"user","after":"staff"}}`, string(arg.Changes))
+
+				return nil
+			})
+
+		service.Record(ctx, audit.Entry{
+			Action:     constant.AuditActionUserRoleUpdate,
+			EntityType: constant.AuditEntityUser,
+			EntityID:   userID,
+			Before:     map[string]string{"role": constant.UserRoleUser, "email": "test@gmail.com"},
+			After:      map[string]string{"role": constant.UserRoleStaff, "email": "test@gmail.com"},
+		})
+	})
+}
+
+func TestAuditLogService_GetAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := New(nil, mock.NewMockQuerier(ctrl), log.NewMockInterface(ctrl))
+
+	t.Run("error: date_to before date_from", func(t *testing.T) {
+		_, err := service.GetAll(context.Background(), dto.GetAuditLogsRequest{DateFrom: "2025-02-01", DateTo: "2025-01-01"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
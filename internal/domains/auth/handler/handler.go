@@ -3,9 +3,12 @@ package handler
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
 	"github.com/savioruz/goth/internal/delivery/http/response"
 	"github.com/savioruz/goth/internal/domains/auth/service"
 	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/logger"
 )
 
@@ -23,15 +26,28 @@ func New(s service.AuthService, l logger.Interface, v *validator.Validate) *Hand
 	}
 }
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	auth := r.Group("/auth")
 
 	auth.Post("/register", h.Register)
 	auth.Post("/login", h.Login)
+	auth.Post("/phone/code", h.SendPhoneLoginCode)
+	auth.Post("/phone/login", h.PhoneLogin)
+	auth.Post("/refresh", h.Refresh)
+	auth.Post("/logout", authn.Jwt(), h.Logout)
 	auth.Get("/verify-email", h.VerifyEmail) // GET with query parameter
+	auth.Post("/resend-verification", h.ResendVerification)
 	auth.Post("/forgot-password", h.ForgotPassword)
 	auth.Get("/reset-password", h.ValidateResetToken) // GET to validate reset token
 	auth.Post("/reset-password", h.ResetPassword)     // POST to actually reset password
+
+	mfa := auth.Group("/mfa")
+	mfa.Post("/verify", h.VerifyMFA)
+	mfa.Post("/setup", h.SetupMFA)
+	mfa.Post("/enroll", authn.Jwt(), middleware.NoImpersonation(), h.EnrollMFA)
+	mfa.Post("/enroll/confirm", authn.Jwt(), middleware.NoImpersonation(), h.ConfirmMFA)
+	mfa.Post("/disable", authn.Jwt(), middleware.NoImpersonation(), h.DisableMFA)
+	mfa.Post("/recovery-codes", authn.Jwt(), middleware.NoImpersonation(), h.RegenerateRecoveryCodes)
 }
 
 // Register godoc
@@ -76,13 +92,15 @@ func (h *Handler) Register(ctx *fiber.Ctx) error {
 
 // Login godoc
 // @Summary Login user
-// @Description Login user with email and password
+// @Description Login user with email and password. Repeated failures delay further attempts and temporarily lock the account. Accounts with two-factor authentication get an mfa_token instead of tokens, to be completed at /auth/mfa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param login body dto.UserLoginRequest true "User login request"
 // @Success 201 {object} response.Data[dto.UserLoginResponse]
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 429 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /auth/login [post]
 func (h *Handler) Login(ctx *fiber.Ctx) error {
@@ -99,6 +117,8 @@ func (h *Handler) Login(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
+	req.ClientInfo = dto.NewClientInfo(ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+
 	data, err := h.service.Login(ctx.UserContext(), req)
 	if err != nil {
 		reqID := "unknown"
@@ -114,6 +134,157 @@ func (h *Handler) Login(ctx *fiber.Ctx) error {
 	return response.WithJSON(ctx, fiber.StatusOK, data)
 }
 
+// SendPhoneLoginCode godoc
+// @Summary Request phone login code
+// @Description Text a login code to a verified phone number. Answers the same whether or not the number belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param phone body dto.PhoneLoginCodeRequest true "Phone login code request"
+// @Success 200 {object} response.Data[dto.PhoneLoginCodeResponse]
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/phone/code [post]
+func (h *Handler) SendPhoneLoginCode(ctx *fiber.Ctx) error {
+	var req dto.PhoneLoginCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - auth - phone-code - body parsing error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - auth - phone-code - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.SendPhoneLoginCode(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error("http - auth - phone-code - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// PhoneLogin godoc
+// @Summary Login with phone
+// @Description Login with the code texted to a verified phone number instead of a password. Users with two-factor authentication get an MFA challenge.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param login body dto.PhoneLoginRequest true "Phone login request"
+// @Success 200 {object} response.Data[dto.UserLoginResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/phone/login [post]
+func (h *Handler) PhoneLogin(ctx *fiber.Ctx) error {
+	var req dto.PhoneLoginRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - auth - phone-login - body parsing error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - auth - phone-login - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	req.ClientInfo = dto.NewClientInfo(ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+
+	data, err := h.service.PhoneLogin(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error("http - auth - phone-login - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token pair. Each refresh token can be used once; reusing one revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body dto.RefreshTokenRequest true "Refresh token request"
+// @Success 200 {object} response.Data[dto.UserLoginResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(ctx *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - auth - refresh - body parsing error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - auth - refresh - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	req.ClientInfo = dto.NewClientInfo(ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+
+	data, err := h.service.Refresh(ctx.UserContext(), req)
+	if err != nil {
+		reqID := "unknown"
+		if id, ok := ctx.Locals("request_id").(string); ok {
+			reqID = id
+		}
+
+		h.logger.Error("http - auth - refresh - request_id: " + reqID + " - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description End the current session. Its refresh token stops working and its access tokens are rejected right away.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Message
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/logout [post]
+// @Security BearerAuth
+func (h *Handler) Logout(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - auth - logout - invalid user type in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	sessionID, _ := ctx.Locals(constant.JwtFieldSession).(string)
+
+	if err := h.service.Logout(ctx.UserContext(), userID, sessionID); err != nil {
+		reqID := "unknown"
+		if id, ok := ctx.Locals("request_id").(string); ok {
+			reqID = id
+		}
+
+		h.logger.Error("http - auth - logout - request_id: " + reqID + " - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "logged out")
+}
+
 // VerifyEmail godoc
 // @Summary Verify email address
 // @Description Verify user's email address using verification token
@@ -154,6 +325,47 @@ func (h *Handler) VerifyEmail(ctx *fiber.Ctx) error {
 	return response.WithJSON(ctx, fiber.StatusOK, data)
 }
 
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new email verification link, invalidating earlier ones. An email can request a link once a minute.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resend body dto.ResendVerificationRequest true "Resend verification request"
+// @Success 200 {object} response.Data[dto.ResendVerificationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/resend-verification [post]
+func (h *Handler) ResendVerification(ctx *fiber.Ctx) error {
+	var req dto.ResendVerificationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - auth - resend-verification - body parsing error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - auth - resend-verification - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.ResendVerification(ctx.UserContext(), req)
+	if err != nil {
+		reqID := "unknown"
+		if id, ok := ctx.Locals("request_id").(string); ok {
+			reqID = id
+		}
+
+		h.logger.Error("http - auth - resend-verification - request_id: " + reqID + " - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
 // ForgotPassword godoc
 // @Summary Request password reset
 // @Description Send password reset email to user
@@ -273,3 +485,221 @@ func (h *Handler) ValidateResetToken(ctx *fiber.Ctx) error {
 
 	return response.WithJSON(ctx, fiber.StatusOK, data)
 }
+
+// VerifyMFA godoc
+// @Summary Verify two-factor code
+// @Description Complete a login that returned an mfa_token with a code from the authenticator app or a recovery code. When the login required enrolment, the code confirms the authenticator from /auth/mfa/setup and the recovery codes are returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verify body dto.MFAVerifyRequest true "MFA verify request"
+// @Success 200 {object} response.Data[dto.UserLoginResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(ctx *fiber.Ctx) error {
+	var req dto.MFAVerifyRequest
+	if err := h.parseAndValidate(ctx, "mfa verify", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.VerifyMFA(ctx.UserContext(), req)
+	if err != nil {
+		h.logError(ctx, "mfa verify", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// SetupMFA godoc
+// @Summary Set up two-factor authentication during login
+// @Description For logins that returned mfa_enrollment_required, returns the authenticator secret and its otpauth:// URI for a QR code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param setup body dto.MFASetupRequest true "MFA setup request"
+// @Success 200 {object} response.Data[dto.MFASetupResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/setup [post]
+func (h *Handler) SetupMFA(ctx *fiber.Ctx) error {
+	var req dto.MFASetupRequest
+	if err := h.parseAndValidate(ctx, "mfa setup", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.SetupMFA(ctx.UserContext(), req)
+	if err != nil {
+		h.logError(ctx, "mfa setup", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// EnrollMFA godoc
+// @Summary Enroll two-factor authentication
+// @Description Returns a new authenticator secret and its otpauth:// URI for a QR code. It is enabled by /auth/mfa/enroll/confirm.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Data[dto.MFASetupResponse]
+// @Failure 401 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/enroll [post]
+// @Security BearerAuth
+func (h *Handler) EnrollMFA(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - auth - mfa enroll - invalid user type in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	data, err := h.service.EnrollMFA(ctx.UserContext(), userID)
+	if err != nil {
+		h.logError(ctx, "mfa enroll", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// ConfirmMFA godoc
+// @Summary Confirm two-factor enrolment
+// @Description Enable two-factor authentication with a code from the newly enrolled authenticator. The recovery codes are only returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param confirm body dto.MFACodeRequest true "MFA code request"
+// @Success 200 {object} response.Data[dto.MFARecoveryCodesResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/enroll/confirm [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmMFA(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - auth - mfa confirm - invalid user type in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	var req dto.MFACodeRequest
+	if err := h.parseAndValidate(ctx, "mfa confirm", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.ConfirmMFA(ctx.UserContext(), userID, req)
+	if err != nil {
+		h.logError(ctx, "mfa confirm", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+// DisableMFA godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a current code or recovery code. Not allowed for roles that require it.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param disable body dto.MFACodeRequest true "MFA code request"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/disable [post]
+// @Security BearerAuth
+func (h *Handler) DisableMFA(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - auth - mfa disable - invalid user type in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	var req dto.MFACodeRequest
+	if err := h.parseAndValidate(ctx, "mfa disable", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.DisableMFA(ctx.UserContext(), userID, req); err != nil {
+		h.logError(ctx, "mfa disable", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes, confirmed with a current code. The new codes are only returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param regenerate body dto.MFACodeRequest true "MFA code request"
+// @Success 200 {object} response.Data[dto.MFARecoveryCodesResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/mfa/recovery-codes [post]
+// @Security BearerAuth
+func (h *Handler) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - auth - mfa recovery codes - invalid user type in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	var req dto.MFACodeRequest
+	if err := h.parseAndValidate(ctx, "mfa recovery codes", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.RegenerateRecoveryCodes(ctx.UserContext(), userID, req)
+	if err != nil {
+		h.logError(ctx, "mfa recovery codes", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, data)
+}
+
+func (h *Handler) parseAndValidate(ctx *fiber.Ctx, op string, req any) error {
+	if err := ctx.BodyParser(req); err != nil {
+		h.logger.Error("http - auth - " + op + " - body parsing error: " + err.Error())
+
+		return err
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - auth - " + op + " - validate error: " + err.Error())
+
+		return failure.BadRequestFromString(err.Error())
+	}
+
+	return nil
+}
+
+func (h *Handler) logError(ctx *fiber.Ctx, op string, err error) {
+	reqID := "unknown"
+	if id, ok := ctx.Locals("request_id").(string); ok {
+		reqID = id
+	}
+
+	h.logger.Error("http - auth - " + op + " - request_id: " + reqID + " - " + err.Error())
+}
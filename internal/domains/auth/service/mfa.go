@@ -0,0 +1,437 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/totp"
+)
+
+const (
+	cacheMFAChallengeKey = "mfa:challenge:%s"
+	cacheMFAAttemptsKey  = "mfa:attempts:%s"
+
+	invalidMFACode = "invalid two-factor code"
+)
+
+// mfaChallenge is a login that passed the password check and waits for the second factor.
+type mfaChallenge struct {
+	UserID    string `json:"user_id"`
+	Device    string `json:"device"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	// Enroll is set when the account has no second factor yet but its role requires one
+	Enroll bool `json:"enroll"`
+}
+
+// CompleteLogin finishes a login whose first factor was checked, asking for the second one when the
+// user has it enabled or their role requires it.
+func (s *authService) CompleteLogin(ctx context.Context, user repository.User, client dto.ClientInfo) (*dto.UserLoginResponse, error) {
+	mfa, err := s.repo.GetUserMFA(ctx, s.db, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("login - service - failed to get mfa: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	enabled := err == nil && mfa.EnabledAt.Valid
+	if !enabled && !s.mfaRequired(user.Role) {
+		return s.IssueTokens(ctx, user, client)
+	}
+
+	token := helper.GenerateStateToken()
+	challenge := mfaChallenge{
+		UserID:    user.ID.String(),
+		Device:    client.Device,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Enroll:    !enabled,
+	}
+
+	err = s.cache.Save(ctx, challengeKey(token), challenge, int(constant.MFAChallengeExpiry.Seconds()))
+	if err != nil {
+		s.logger.Error("login - service - failed to store mfa challenge: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return &dto.UserLoginResponse{MFAToken: token, MFAEnrollmentRequired: !enabled}, nil
+}
+
+// VerifyMFA checks the second factor of a login and issues its tokens. For accounts that had to enrol,
+// the code confirms the authenticator set up through SetupMFA and the recovery codes are returned once.
+func (s *authService) VerifyMFA(ctx context.Context, req dto.MFAVerifyRequest) (*dto.UserLoginResponse, error) {
+	challenge, err := s.getChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(challenge.UserID))
+	if err != nil {
+		s.logger.Error("mfa-verify - service - failed to get user: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	var recoveryCodes []string
+
+	if challenge.Enroll {
+		recoveryCodes, err = s.enableMFA(ctx, user.ID.String(), req.Code)
+	} else {
+		err = s.verifyMFACode(ctx, s.db, user.ID.String(), req.Code)
+	}
+
+	if err != nil {
+		return nil, s.failChallenge(ctx, req.MFAToken, err)
+	}
+
+	// Only one request may redeem the challenge
+	if err = s.cache.GetDel(ctx, challengeKey(req.MFAToken), &challenge); err != nil {
+		s.logger.Error("mfa-verify - service - challenge already used: %w", err)
+
+		return nil, failure.Unauthorized("invalid or expired mfa token")
+	}
+
+	res, err := s.IssueTokens(ctx, user, dto.ClientInfo{
+		Device:    challenge.Device,
+		IPAddress: challenge.IPAddress,
+		UserAgent: challenge.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.RecoveryCodes = recoveryCodes
+
+	return res, nil
+}
+
+// SetupMFA starts enrolment during a login that requires a second factor the account does not have yet.
+func (s *authService) SetupMFA(ctx context.Context, req dto.MFASetupRequest) (dto.MFASetupResponse, error) {
+	challenge, err := s.getChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return dto.MFASetupResponse{}, err
+	}
+
+	if !challenge.Enroll {
+		return dto.MFASetupResponse{}, failure.BadRequestFromString("two-factor authentication is already enabled")
+	}
+
+	return s.EnrollMFA(ctx, challenge.UserID)
+}
+
+// EnrollMFA generates a new authenticator secret for the user. It takes effect once ConfirmMFA
+// receives a code generated from it.
+func (s *authService) EnrollMFA(ctx context.Context, userID string) (dto.MFASetupResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("mfa-enroll - service - failed to get user: %w", err)
+
+		return dto.MFASetupResponse{}, failure.InternalError(err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error("mfa-enroll - service - failed to generate secret: %w", err)
+
+		return dto.MFASetupResponse{}, failure.InternalError(err)
+	}
+
+	rows, err := s.repo.UpsertPendingUserMFA(ctx, s.db, repository.UpsertPendingUserMFAParams{UserID: user.ID, Secret: secret})
+	if err != nil {
+		s.logger.Error("mfa-enroll - service - failed to store secret: %w", err)
+
+		return dto.MFASetupResponse{}, failure.InternalError(err)
+	}
+
+	if rows == 0 {
+		return dto.MFASetupResponse{}, failure.Conflict("two-factor authentication is already enabled")
+	}
+
+	return dto.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, cmp.Or(s.config.MFA.Issuer, s.config.App.Name), user.Email),
+	}, nil
+}
+
+// ConfirmMFA enables the secret from EnrollMFA and returns the recovery codes, which are only shown once.
+func (s *authService) ConfirmMFA(ctx context.Context, userID string, req dto.MFACodeRequest) (dto.MFARecoveryCodesResponse, error) {
+	codes, err := s.enableMFA(ctx, userID, req.Code)
+	if err != nil {
+		return dto.MFARecoveryCodesResponse{}, err
+	}
+
+	return dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA removes the second factor, unless the user's role requires one.
+func (s *authService) DisableMFA(ctx context.Context, userID string, req dto.MFACodeRequest) error {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("mfa-disable - service - failed to get user: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if s.mfaRequired(user.Role) {
+		return failure.Forbidden("two-factor authentication is required for your role")
+	}
+
+	return s.withTx(ctx, "mfa-disable", func(tx pgx.Tx) error {
+		if err := s.verifyMFACode(ctx, tx, userID, req.Code); err != nil {
+			return err
+		}
+
+		if err := s.repo.DeleteMFARecoveryCodes(ctx, tx, user.ID); err != nil {
+			s.logger.Error("mfa-disable - service - failed to delete recovery codes: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		if err := s.repo.DeleteUserMFA(ctx, tx, user.ID); err != nil {
+			s.logger.Error("mfa-disable - service - failed to delete mfa: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, e.g. after most were used.
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID string, req dto.MFACodeRequest) (res dto.MFARecoveryCodesResponse, err error) {
+	err = s.withTx(ctx, "mfa-recovery-codes", func(tx pgx.Tx) error {
+		if err := s.verifyMFACode(ctx, tx, userID, req.Code); err != nil {
+			return err
+		}
+
+		res.RecoveryCodes, err = s.replaceRecoveryCodes(ctx, tx, userID)
+
+		return err
+	})
+
+	return res, err
+}
+
+func (s *authService) enableMFA(ctx context.Context, userID, code string) (codes []string, err error) {
+	err = s.withTx(ctx, "mfa-enable", func(tx pgx.Tx) error {
+		mfa, err := s.repo.GetUserMFA(ctx, tx, helper.PgUUID(userID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return failure.BadRequestFromString("two-factor authentication has not been set up")
+		}
+
+		if err != nil {
+			s.logger.Error("mfa-enable - service - failed to get mfa: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		if mfa.EnabledAt.Valid {
+			return failure.Conflict("two-factor authentication is already enabled")
+		}
+
+		step, ok := totp.Validate(mfa.Secret, code, time.Now())
+		if !ok {
+			return failure.Unauthorized(invalidMFACode)
+		}
+
+		if _, err = s.repo.EnableUserMFA(ctx, tx, repository.EnableUserMFAParams{UserID: mfa.UserID, LastUsedStep: step}); err != nil {
+			s.logger.Error("mfa-enable - service - failed to enable mfa: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		codes, err = s.replaceRecoveryCodes(ctx, tx, userID)
+
+		return err
+	})
+
+	return codes, err
+}
+
+// verifyMFACode accepts a code from the authenticator app, each only once, or an unused recovery code.
+func (s *authService) verifyMFACode(ctx context.Context, db repository.DBTX, userID, code string) error {
+	mfa, err := s.repo.GetUserMFA(ctx, db, helper.PgUUID(userID))
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !mfa.EnabledAt.Valid) {
+		return failure.BadRequestFromString("two-factor authentication is not enabled")
+	}
+
+	if err != nil {
+		s.logger.Error("mfa - service - failed to get mfa: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if step, ok := totp.Validate(mfa.Secret, code, time.Now()); ok {
+		rows, err := s.repo.UseUserMFAStep(ctx, db, repository.UseUserMFAStepParams{UserID: mfa.UserID, LastUsedStep: step})
+		if err != nil {
+			s.logger.Error("mfa - service - failed to use code: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		if rows == 0 {
+			return failure.Unauthorized(invalidMFACode)
+		}
+
+		return nil
+	}
+
+	rows, err := s.repo.UseMFARecoveryCode(ctx, db, repository.UseMFARecoveryCodeParams{
+		UserID:   mfa.UserID,
+		CodeHash: helper.HashToken(normalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		s.logger.Error("mfa - service - failed to use recovery code: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if rows == 0 {
+		return failure.Unauthorized(invalidMFACode)
+	}
+
+	return nil
+}
+
+func (s *authService) replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string) ([]string, error) {
+	codes := make([]string, constant.MFARecoveryCodeCount)
+	hashes := make([]string, constant.MFARecoveryCodeCount)
+
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			s.logger.Error("mfa - service - failed to generate recovery code: %w", err)
+
+			return nil, failure.InternalError(err)
+		}
+
+		codes[i], hashes[i] = code, helper.HashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.repo.DeleteMFARecoveryCodes(ctx, tx, helper.PgUUID(userID)); err != nil {
+		s.logger.Error("mfa - service - failed to delete recovery codes: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	err := s.repo.CreateMFARecoveryCodes(ctx, tx, repository.CreateMFARecoveryCodesParams{UserID: helper.PgUUID(userID), CodeHashes: hashes})
+	if err != nil {
+		s.logger.Error("mfa - service - failed to create recovery codes: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return codes, nil
+}
+
+func (s *authService) getChallenge(ctx context.Context, token string) (mfaChallenge, error) {
+	var challenge mfaChallenge
+
+	err := s.cache.Get(ctx, challengeKey(token), &challenge)
+	if errors.Is(err, goredis.Nil) {
+		s.logger.Error("mfa - service - unknown or expired challenge")
+
+		return challenge, failure.Unauthorized("invalid or expired mfa token")
+	}
+
+	if err != nil {
+		s.logger.Error("mfa - service - failed to get challenge: %w", err)
+
+		return challenge, failure.InternalError(err)
+	}
+
+	return challenge, nil
+}
+
+// failChallenge counts a wrong code and ends the challenge after too many, so codes cannot be guessed.
+func (s *authService) failChallenge(ctx context.Context, token string, cause error) error {
+	if failure.GetCode(cause) != http.StatusUnauthorized {
+		return cause
+	}
+
+	attempts, err := s.cache.Incr(ctx, fmt.Sprintf(cacheMFAAttemptsKey, helper.HashToken(token)), int(constant.MFAChallengeExpiry.Seconds()))
+	if err != nil {
+		s.logger.Error("mfa-verify - service - failed to count attempt: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if attempts >= constant.MFAMaxAttempts {
+		if err = s.cache.Delete(ctx, challengeKey(token)); err != nil {
+			s.logger.Error("mfa-verify - service - failed to end challenge: %w", err)
+		}
+
+		return failure.Unauthorized("too many invalid codes, log in again")
+	}
+
+	return cause
+}
+
+func (s *authService) mfaRequired(role string) bool {
+	return slices.Contains(s.config.MFA.RequiredRoles, role)
+}
+
+// withTx runs fn in a transaction, committing when it succeeds.
+func (s *authService) withTx(ctx context.Context, op string, fn func(tx pgx.Tx) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error(op+" - service - failed to begin transaction: %w", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error(op+" - service - failed to rollback transaction: %w", err)
+		}
+	}(tx, ctx)
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error(op+" - service - failed to commit transaction: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+func challengeKey(token string) string {
+	return fmt.Sprintf(cacheMFAChallengeKey, helper.HashToken(token))
+}
+
+// generateRecoveryCode returns a code like "ABCDEFGH-IJKLMNOP".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.EncodeToString(b)
+
+	return code[:8] + "-" + code[8:], nil
+}
+
+// normalizeRecoveryCode makes recovery codes match however they were typed.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/otp"
+)
+
+const (
+	phoneLoginCodeMessage = "If the number belongs to an account, a login code has been sent"
+	// PhoneLogin answers unknown numbers and wrong codes alike, so it cannot be used to find accounts
+	invalidPhoneCode = "invalid or expired code"
+)
+
+// SendPhoneLoginCode texts a login code to the phone when it is the verified number of an account. It
+// answers the same whether or not it is, including during the resend cooldown.
+func (s *authService) SendPhoneLoginCode(ctx context.Context, req dto.PhoneLoginCodeRequest) (*dto.PhoneLoginCodeResponse, error) {
+	res := &dto.PhoneLoginCodeResponse{Message: phoneLoginCodeMessage}
+
+	_, err := s.repo.GetUserByVerifiedPhone(ctx, s.db, helper.PgString(req.Phone))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, nil
+	}
+
+	if err != nil {
+		s.logger.Error("phone-login - service - failed to get user by phone: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	err = s.otp.Send(ctx, constant.OTPPurposeLogin, req.Phone)
+	if errors.Is(err, otp.ErrCooldown) {
+		s.logger.Info("phone-login - service - code sent recently")
+
+		return res, nil
+	}
+
+	if err != nil {
+		s.logger.Error("phone-login - service - failed to send code: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// PhoneLogin is an alternative to the password for users with a verified phone number. Wrong codes
+// count as failed logins of the number.
+func (s *authService) PhoneLogin(ctx context.Context, req dto.PhoneLoginRequest) (*dto.UserLoginResponse, error) {
+	wait, err := s.guard.Check(ctx, req.Phone, req.IPAddress)
+	if err != nil {
+		s.logger.Error("phone-login - service - failed to check login attempts: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if wait > 0 {
+		s.logger.Error("phone-login - service - too many failed attempts")
+
+		return nil, failure.TooManyRequests(fmt.Sprintf("too many failed login attempts, try again in %s", wait.Round(time.Second)))
+	}
+
+	if err = s.otp.Verify(ctx, constant.OTPPurposeLogin, req.Phone, req.Code); err != nil {
+		if !errors.Is(err, otp.ErrInvalidCode) && !errors.Is(err, otp.ErrTooManyAttempts) {
+			s.logger.Error("phone-login - service - failed to verify code: %w", err)
+
+			return nil, failure.InternalError(err)
+		}
+
+		s.logger.Error("phone-login - service - unauthorized")
+
+		if _, err = s.guard.Fail(ctx, req.Phone, req.IPAddress); err != nil {
+			s.logger.Error("phone-login - service - failed to record failed attempt: %w", err)
+		}
+
+		return nil, failure.Unauthorized(invalidPhoneCode)
+	}
+
+	// The number may have been verified by someone else, or the account deactivated, since the code was sent
+	user, err := s.repo.GetUserByVerifiedPhone(ctx, s.db, helper.PgString(req.Phone))
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("phone-login - service - no user with the verified phone")
+
+		return nil, failure.Unauthorized(invalidPhoneCode)
+	}
+
+	if err != nil {
+		s.logger.Error("phone-login - service - failed to get user by phone: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if _, err = s.repo.UpdateLastLogin(ctx, s.db, user.ID); err != nil {
+		s.logger.Error("phone-login - service - failed to update last login: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if err = s.guard.Succeed(ctx, req.Phone); err != nil {
+		s.logger.Error("phone-login - service - failed to reset login attempts: %w", err)
+	}
+
+	return s.CompleteLogin(ctx, user, req.ClientInfo)
+}
@@ -3,26 +3,46 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/savioruz/goth/config"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/loginguard"
 	"github.com/savioruz/goth/pkg/mail"
+	"github.com/savioruz/goth/pkg/otp"
 	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/session"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
 	"github.com/savioruz/goth/internal/domains/user/dto"
 	"github.com/savioruz/goth/internal/domains/user/repository"
-	"github.com/savioruz/goth/pkg/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req dto.UserRegisterRequest) (res *dto.UserRegisterResponse, err error)
 	Login(ctx context.Context, req dto.UserLoginRequest) (*dto.UserLoginResponse, error)
+	SendPhoneLoginCode(ctx context.Context, req dto.PhoneLoginCodeRequest) (*dto.PhoneLoginCodeResponse, error)
+	PhoneLogin(ctx context.Context, req dto.PhoneLoginRequest) (*dto.UserLoginResponse, error)
+	Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.UserLoginResponse, error)
+	IssueTokens(ctx context.Context, user repository.User, client dto.ClientInfo) (*dto.UserLoginResponse, error)
+	CompleteLogin(ctx context.Context, user repository.User, client dto.ClientInfo) (*dto.UserLoginResponse, error)
+	VerifyMFA(ctx context.Context, req dto.MFAVerifyRequest) (*dto.UserLoginResponse, error)
+	SetupMFA(ctx context.Context, req dto.MFASetupRequest) (dto.MFASetupResponse, error)
+	EnrollMFA(ctx context.Context, userID string) (dto.MFASetupResponse, error)
+	ConfirmMFA(ctx context.Context, userID string, req dto.MFACodeRequest) (dto.MFARecoveryCodesResponse, error)
+	DisableMFA(ctx context.Context, userID string, req dto.MFACodeRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, req dto.MFACodeRequest) (dto.MFARecoveryCodesResponse, error)
+	Logout(ctx context.Context, userID, sessionID string) error
 	VerifyEmail(ctx context.Context, req dto.EmailVerificationRequest) (*dto.EmailVerificationResponse, error)
+	ResendVerification(ctx context.Context, req dto.ResendVerificationRequest) (*dto.ResendVerificationResponse, error)
 	ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) (*dto.ForgotPasswordResponse, error)
 	ValidateResetToken(ctx context.Context, req dto.ValidateResetTokenRequest) (*dto.ValidateResetTokenResponse, error)
 	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) (*dto.ResetPasswordResponse, error)
@@ -31,21 +51,49 @@ type AuthService interface {
 type authService struct {
 	db          postgres.PgxIface
 	repo        repository.Querier
+	bookingRepo bookingRepository.Querier
 	logger      logger.Interface
 	mailService mail.Service
+	revoker     session.Revoker
+	guard       loginguard.Guard
+	cache       redis.IRedisCache
+	config      *config.Config
+	otp         otp.Service
 }
 
-func New(db postgres.PgxIface, r repository.Querier, l logger.Interface, m mail.Service) AuthService {
+func New(
+	db postgres.PgxIface,
+	r repository.Querier,
+	b bookingRepository.Querier,
+	l logger.Interface,
+	m mail.Service,
+	rv session.Revoker,
+	g loginguard.Guard,
+	cache redis.IRedisCache,
+	cfg *config.Config,
+	o otp.Service,
+) AuthService {
 	return &authService{
 		db:          db,
 		repo:        r,
+		bookingRepo: b,
 		logger:      l,
 		mailService: m,
+		revoker:     rv,
+		guard:       g,
+		cache:       cache,
+		config:      cfg,
+		otp:         o,
 	}
 }
 
 const (
 	tokenLength = 32
+	// Login answers unknown emails and wrong passwords alike, so it cannot be used to find accounts
+	invalidCredentials = "invalid email or password"
+	accountDeactivated = "account has been deactivated"
+	// dummyPasswordHash is compared against when there is no password, so those logins take as long as others
+	dummyPasswordHash = "$2a$10$cXpx1p2UltVSm9q0.pTngupitgYnI1UPOOwNDjmywxwfO0zoGKZ4G"
 )
 
 func (s *authService) Register(ctx context.Context, req dto.UserRegisterRequest) (res *dto.UserRegisterResponse, err error) {
@@ -92,7 +140,7 @@ func (s *authService) Register(ctx context.Context, req dto.UserRegisterRequest)
 	newUser, err := s.repo.CreateUser(ctx, tx, repository.CreateUserParams{
 		Email:      req.Email,
 		Password:   helper.PgString(string(password)),
-		Level:      constant.UserRoleUser,
+		Role:       constant.UserRoleUser,
 		FullName:   helper.PgString(req.Name),
 		IsVerified: helper.PgBool(false),
 	})
@@ -112,8 +160,8 @@ func (s *authService) Register(ctx context.Context, req dto.UserRegisterRequest)
 
 	// Create email verification record
 	_, err = s.repo.CreateEmailVerification(ctx, tx, repository.CreateEmailVerificationParams{
-		UserID: newUser.ID,
-		Token:  token,
+		UserID:    newUser.ID,
+		TokenHash: helper.HashToken(token),
 	})
 	if err != nil {
 		s.logger.Error("register - service - failed to create email verification: %w", err)
@@ -140,6 +188,19 @@ func (s *authService) Register(ctx context.Context, req dto.UserRegisterRequest)
 }
 
 func (s *authService) Login(ctx context.Context, req dto.UserLoginRequest) (*dto.UserLoginResponse, error) {
+	wait, err := s.guard.Check(ctx, req.Email, req.IPAddress)
+	if err != nil {
+		s.logger.Error("login - service - failed to check login attempts: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if wait > 0 {
+		s.logger.Error("login - service - too many failed attempts")
+
+		return nil, failure.TooManyRequests(fmt.Sprintf("too many failed login attempts, try again in %s", wait.Round(time.Second)))
+	}
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		s.logger.Error("login - service - failed to begin transaction: %w", err)
@@ -154,29 +215,41 @@ func (s *authService) Login(ctx context.Context, req dto.UserLoginRequest) (*dto
 	}(tx, ctx)
 
 	user, err := s.repo.GetUserByEmail(ctx, tx, req.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deactivated users are told so, but only once they prove who they are
+		user, err = s.repo.GetDeactivatedUserByEmail(ctx, tx, req.Email)
+	}
+
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		s.logger.Error("login - service - failed to get user by email: %w", err)
 
 		return nil, failure.InternalError(err)
 	}
 
-	if user.Email == "" {
-		s.logger.Error("login - service - user not found")
+	passwordHash := dummyPasswordHash
+	if user.Password.Valid {
+		passwordHash = user.Password.String
+	}
 
-		return nil, failure.NotFound("user not found")
+	// Unknown emails and OAuth users, who have no password, are checked against a dummy hash that never matches
+	if err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil || !user.Password.Valid {
+		s.logger.Error("login - service - unauthorized")
+
+		s.recordFailedLogin(ctx, user, req)
+
+		return nil, failure.Unauthorized(invalidCredentials)
 	}
 
-	// Check if user is verified (skip verification for Google OAuth users)
-	if !helper.BoolFromPg(user.IsVerified) && !user.GoogleID.Valid {
-		s.logger.Error("login - service - user is not verified")
+	if user.DeletedAt.Valid {
+		s.logger.Error("login - service - user is deactivated")
 
-		return nil, failure.BadRequestFromString("user is not verified")
+		return nil, failure.Forbidden(accountDeactivated)
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password.String), []byte(req.Password)); err != nil {
-		s.logger.Error("login - service - unauthorized")
+	if !helper.BoolFromPg(user.IsVerified) {
+		s.logger.Error("login - service - user is not verified")
 
-		return nil, failure.Unauthorized("unauthorized")
+		return nil, failure.BadRequestFromString("user is not verified")
 	}
 
 	_, err = s.repo.UpdateLastLogin(ctx, tx, user.ID)
@@ -192,24 +265,31 @@ func (s *authService) Login(ctx context.Context, req dto.UserLoginRequest) (*dto
 		return nil, failure.InternalError(err)
 	}
 
-	accessToken, err := jwt.GenerateAccessToken(user.ID.String(), user.Email, user.Level)
-	if err != nil {
-		s.logger.Error("login - service - failed to generate access token: %w", err)
-
-		return nil, failure.InternalError(err)
+	if err = s.guard.Succeed(ctx, req.Email); err != nil {
+		s.logger.Error("login - service - failed to reset login attempts: %w", err)
 	}
 
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID.String(), user.Email, user.Level)
+	return s.CompleteLogin(ctx, user, req.ClientInfo)
+}
+
+// recordFailedLogin counts the failure against the email and IP, telling the owner when it locks their account.
+func (s *authService) recordFailedLogin(ctx context.Context, user repository.User, req dto.UserLoginRequest) {
+	lockedUntil, err := s.guard.Fail(ctx, req.Email, req.IPAddress)
 	if err != nil {
-		s.logger.Error("login - service - failed to generate refresh token: %w", err)
+		s.logger.Error("login - service - failed to record failed attempt: %w", err)
 
-		return nil, failure.InternalError(err)
+		return
 	}
 
-	return &dto.UserLoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	if lockedUntil.IsZero() || user.Email == "" {
+		return
+	}
+
+	go func() {
+		if err := s.mailService.SendAccountLockedEmail(user.Email, user.FullName.String, lockedUntil); err != nil {
+			s.logger.Error("login - service - failed to send account locked email: %w", err)
+		}
+	}()
 }
 
 func (s *authService) VerifyEmail(ctx context.Context, req dto.EmailVerificationRequest) (*dto.EmailVerificationResponse, error) {
@@ -226,7 +306,7 @@ func (s *authService) VerifyEmail(ctx context.Context, req dto.EmailVerification
 		}
 	}(tx, ctx)
 
-	verification, err := s.repo.GetEmailVerificationByToken(ctx, tx, req.Token)
+	verification, err := s.repo.GetEmailVerificationByTokenHash(ctx, tx, helper.HashToken(req.Token))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.logger.Error("verify-email - service - invalid or expired token")
@@ -239,13 +319,30 @@ func (s *authService) VerifyEmail(ctx context.Context, req dto.EmailVerification
 		return nil, failure.InternalError(err)
 	}
 
-	_, err = s.repo.VerifyEmail(ctx, tx, verification.UserID)
+	user, err := s.repo.VerifyEmail(ctx, tx, verification.UserID)
 	if err != nil {
 		s.logger.Error("verify-email - service - failed to verify email: %w", err)
 
 		return nil, failure.InternalError(err)
 	}
 
+	// Bookings staff made for this email as a walk-in guest belong to its verified owner
+	if _, err = s.bookingRepo.AttachGuestBookings(ctx, tx, bookingRepository.AttachGuestBookingsParams{
+		UserID: user.ID,
+		Email:  user.Email,
+	}); err != nil {
+		s.logger.Error("verify-email - service - failed to attach guest bookings: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	// Verification links are single use
+	if err = s.repo.DeleteEmailVerificationsByUserID(ctx, tx, verification.UserID); err != nil {
+		s.logger.Error("verify-email - service - failed to delete verification tokens: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		s.logger.Error("verify-email - service - failed to commit transaction: %w", err)
 
@@ -285,11 +382,11 @@ func (s *authService) ForgotPassword(ctx context.Context, req dto.ForgotPassword
 		return nil, failure.InternalError(err)
 	}
 
-	// Check if user is a Google OAuth user
-	if user.GoogleID.Valid && user.GoogleID.String != "" {
-		s.logger.Error("forgot-password - service - cannot reset password for Google OAuth user")
+	// Check if user only signs in through an OAuth provider
+	if !user.Password.Valid {
+		s.logger.Error("forgot-password - service - cannot reset password for OAuth user")
 
-		return nil, failure.BadRequestFromString("cannot reset password for Google OAuth account")
+		return nil, failure.BadRequestFromString("cannot reset password for OAuth account")
 	}
 
 	// Generate reset token
@@ -300,10 +397,17 @@ func (s *authService) ForgotPassword(ctx context.Context, req dto.ForgotPassword
 		return nil, failure.InternalError(err)
 	}
 
+	// Only the latest reset link works
+	if err = s.repo.DeletePasswordResetsByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("forgot-password - service - failed to delete previous reset tokens: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
 	// Create password reset record
 	_, err = s.repo.CreatePasswordReset(ctx, tx, repository.CreatePasswordResetParams{
-		UserID: user.ID,
-		Token:  token,
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
 	})
 	if err != nil {
 		s.logger.Error("forgot-password - service - failed to create password reset: %w", err)
@@ -343,7 +447,7 @@ func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRe
 		}
 	}(tx, ctx)
 
-	resetRecord, err := s.repo.GetPasswordResetByToken(ctx, tx, req.Token)
+	resetRecord, err := s.repo.GetPasswordResetByTokenHash(ctx, tx, helper.HashToken(req.Token))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.logger.Error("reset-password - service - invalid or expired token")
@@ -364,10 +468,10 @@ func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRe
 		return nil, failure.InternalError(err)
 	}
 
-	if user.GoogleID.Valid && user.GoogleID.String != "" {
-		s.logger.Error("reset-password - service - cannot reset password for Google OAuth user")
+	if !user.Password.Valid {
+		s.logger.Error("reset-password - service - cannot reset password for OAuth user")
 
-		return nil, failure.BadRequestFromString("cannot reset password for Google OAuth account")
+		return nil, failure.BadRequestFromString("cannot reset password for OAuth account")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -390,12 +494,32 @@ func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRe
 		return nil, failure.InternalError(err)
 	}
 
+	// Reset links are single use
+	if err = s.repo.DeletePasswordResetsByUserID(ctx, tx, resetRecord.UserID); err != nil {
+		s.logger.Error("reset-password - service - failed to delete reset tokens: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	// Whoever knew the old password is logged out
+	if err = s.revokeSessions(ctx, tx, resetRecord.UserID); err != nil {
+		s.logger.Error("reset-password - service - failed to revoke sessions: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		s.logger.Error("reset-password - service - failed to commit transaction: %w", err)
 
 		return nil, failure.InternalError(err)
 	}
 
+	if err = s.revoker.RevokeUser(ctx, resetRecord.UserID.String()); err != nil {
+		s.logger.Error("reset-password - service - failed to revoke user tokens: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
 	return &dto.ResetPasswordResponse{
 		Message: "Password reset successfully",
 	}, nil
@@ -415,7 +539,7 @@ func (s *authService) ValidateResetToken(ctx context.Context, req dto.ValidateRe
 		}
 	}(tx, ctx)
 
-	resetRecord, err := s.repo.GetPasswordResetByToken(ctx, tx, req.Token)
+	resetRecord, err := s.repo.GetPasswordResetByTokenHash(ctx, tx, helper.HashToken(req.Token))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return &dto.ValidateResetTokenResponse{
@@ -437,10 +561,10 @@ func (s *authService) ValidateResetToken(ctx context.Context, req dto.ValidateRe
 		return nil, failure.InternalError(err)
 	}
 
-	if user.GoogleID.Valid && user.GoogleID.String != "" {
+	if !user.Password.Valid {
 		return &dto.ValidateResetTokenResponse{
 			Valid:   false,
-			Message: "Cannot reset password for Google OAuth account",
+			Message: "Cannot reset password for OAuth account",
 		}, nil
 	}
 
@@ -10,19 +10,33 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/config"
+	bookingMock "github.com/savioruz/goth/internal/domains/bookings/mock"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
 	"github.com/savioruz/goth/internal/domains/user/dto"
 	"github.com/savioruz/goth/internal/domains/user/mock"
 	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/jwt"
 	log "github.com/savioruz/goth/pkg/logger/mock"
+	guardMock "github.com/savioruz/goth/pkg/loginguard/mock"
 	mail "github.com/savioruz/goth/pkg/mail/mock"
+	"github.com/savioruz/goth/pkg/otp"
+	otpMock "github.com/savioruz/goth/pkg/otp/mock"
+	redisMock "github.com/savioruz/goth/pkg/redis/mock"
+	sessionMock "github.com/savioruz/goth/pkg/session/mock"
+	"github.com/savioruz/goth/pkg/totp"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
 func init() {
 	jwt.Initialize("test-app", "test-secret-key", time.Hour, time.Hour*24)
 }
@@ -42,7 +56,7 @@ func TestAuthService_Register(t *testing.T) {
 		ID:         pgtype.UUID{Bytes: mockID, Valid: true},
 		Email:      "test@gmail.com",
 		Password:   pgtype.Text{String: "hashedpassword", Valid: true},
-		Level:      "1",
+		Role:       "user",
 		FullName:   pgtype.Text{String: "Test User", Valid: true},
 		IsVerified: pgtype.Bool{Bool: false, Valid: true},
 		CreatedAt:  pgtype.Timestamp{Time: time.Now(), Valid: true},
@@ -56,7 +70,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 		mockPgx.ExpectBegin().WillReturnError(mockError)
@@ -76,7 +91,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
@@ -102,7 +118,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any())
 
@@ -128,7 +145,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
@@ -158,7 +176,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
@@ -192,7 +211,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
@@ -228,7 +248,8 @@ func TestAuthService_Register(t *testing.T) {
 		mockPgx, _ := pgxmock.NewPool()
 		mockLogger := log.NewMockInterface(ctrl)
 		mockMail := mail.NewMockService(ctrl)
-		service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		mockPgx.ExpectBegin()
 
@@ -271,13 +292,20 @@ func TestAuthService_Login(t *testing.T) {
 	mockPgx, _ := pgxmock.NewPool()
 	mockLogger := log.NewMockInterface(ctrl)
 	mockMail := mail.NewMockService(ctrl)
+	mockRevoker := sessionMock.NewMockRevoker(ctrl)
+	mockGuard := guardMock.NewMockGuard(ctrl)
 	mockError := errors.New("error")
 
-	service := New(mockPgx, mockQuerier, mockLogger, mockMail)
+	service := New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, mockGuard, redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 	loginReq := dto.UserLoginRequest{
-		Email:    "test@gmail.com",
-		Password: "password123",
+		Email:      "test@gmail.com",
+		Password:   "password123",
+		ClientInfo: dto.NewClientInfo("127.0.0.1", userAgent),
+	}
+
+	expectAllowed := func() {
+		mockGuard.EXPECT().Check(gomock.Any(), "test@gmail.com", "127.0.0.1").Return(time.Duration(0), nil)
 	}
 
 	mockID := uuid.New()
@@ -286,7 +314,7 @@ func TestAuthService_Login(t *testing.T) {
 			ID:         pgtype.UUID{Bytes: mockID, Valid: true},
 			Email:      "test@gmail.com",
 			Password:   pgtype.Text{String: password, Valid: true},
-			Level:      "1",
+			Role:       "user",
 			FullName:   pgtype.Text{String: "Test User", Valid: true},
 			IsVerified: pgtype.Bool{Bool: false, Valid: true},
 			CreatedAt:  pgtype.Timestamp{Time: time.Now(), Valid: true},
@@ -296,6 +324,8 @@ func TestAuthService_Login(t *testing.T) {
 	}
 
 	t.Run("error: transaction begin failure", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin().WillReturnError(mockError)
@@ -308,6 +338,8 @@ func TestAuthService_Login(t *testing.T) {
 	})
 
 	t.Run("error: failure getting user by email", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin()
@@ -325,6 +357,8 @@ func TestAuthService_Login(t *testing.T) {
 	})
 
 	t.Run("error: user not found", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin()
@@ -332,23 +366,33 @@ func TestAuthService_Login(t *testing.T) {
 
 		mockQuerier.EXPECT().
 			GetUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
-			Return(repository.User{}, nil)
+			Return(repository.User{}, pgx.ErrNoRows)
+		mockQuerier.EXPECT().
+			GetDeactivatedUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
+			Return(repository.User{}, pgx.ErrNoRows)
+
+		// Unknown emails count as failures too, so they cannot be told apart from wrong passwords
+		mockGuard.EXPECT().Fail(gomock.Any(), "test@gmail.com", "127.0.0.1").Return(time.Time{}, nil)
 
 		res, err := service.Login(ctx, loginReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
-		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		assert.Equal(t, invalidCredentials, err.Error())
 	})
 
 	t.Run("error: user not verified", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin()
 		mockPgx.ExpectRollback()
 
-		// Create a user that is not verified
-		unverifiedUser := mockUser("hashedpassword")
+		// Create a user that is not verified, whose password matches
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+		unverifiedUser := mockUser(string(hashedPassword))
 		// IsVerified is already false in mockUser function
 
 		mockQuerier.EXPECT().
@@ -362,7 +406,35 @@ func TestAuthService_Login(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
 	})
 
+	t.Run("error: user deactivated", func(t *testing.T) {
+		expectAllowed()
+
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+		deactivatedUser := mockUser(string(hashedPassword))
+		deactivatedUser.DeletedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+		mockQuerier.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
+			Return(repository.User{}, pgx.ErrNoRows)
+		mockQuerier.EXPECT().
+			GetDeactivatedUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
+			Return(deactivatedUser, nil)
+
+		res, err := service.Login(ctx, loginReq)
+
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
 	t.Run("error: invalid password", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin()
@@ -377,14 +449,70 @@ func TestAuthService_Login(t *testing.T) {
 			GetUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
 			Return(invalidPasswordUser, nil)
 
+		mockGuard.EXPECT().Fail(gomock.Any(), "test@gmail.com", "127.0.0.1").Return(time.Time{}, nil)
+
 		res, err := service.Login(ctx, loginReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
 		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		assert.Equal(t, invalidCredentials, err.Error())
+	})
+
+	t.Run("error: failed attempt locks account", func(t *testing.T) {
+		expectAllowed()
+
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+
+		user := mockUser("hashedpassword")
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		sent := make(chan struct{})
+
+		mockQuerier.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").
+			Return(user, nil)
+
+		mockGuard.EXPECT().Fail(gomock.Any(), "test@gmail.com", "127.0.0.1").Return(lockedUntil, nil)
+
+		mockMail.EXPECT().
+			SendAccountLockedEmail(user.Email, user.FullName.String, lockedUntil).
+			DoAndReturn(func(string, string, time.Time) error {
+				close(sent)
+
+				return nil
+			})
+
+		res, err := service.Login(ctx, loginReq)
+
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("account locked email was not sent")
+		}
+	})
+
+	t.Run("error: too many failed attempts", func(t *testing.T) {
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		mockGuard.EXPECT().Check(gomock.Any(), "test@gmail.com", "127.0.0.1").Return(30*time.Second, nil)
+
+		res, err := service.Login(ctx, loginReq)
+
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusTooManyRequests, failure.GetCode(err))
 	})
 
 	t.Run("error: transaction commit failure", func(t *testing.T) {
+		expectAllowed()
+
 		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
 
 		mockPgx.ExpectBegin()
@@ -412,8 +540,10 @@ func TestAuthService_Login(t *testing.T) {
 	})
 
 	t.Run("success: login", func(t *testing.T) {
+		expectAllowed()
+
 		mockPgx, _ = pgxmock.NewPool()
-		service = New(mockPgx, mockQuerier, mockLogger, mockMail)
+		service = New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, mockGuard, redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
 
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 
@@ -431,6 +561,41 @@ func TestAuthService_Login(t *testing.T) {
 			Return(pgtype.UUID{Bytes: mockID, Valid: true}, nil)
 
 		mockPgx.ExpectCommit()
+
+		mockGuard.EXPECT().Succeed(gomock.Any(), "test@gmail.com").Return(nil)
+
+		mockQuerier.EXPECT().
+			GetUserMFA(gomock.Any(), gomock.Any(), mockUserWithValidPassword.ID).
+			Return(repository.UserMfa{}, pgx.ErrNoRows)
+
+		// The session is created in its own transaction once the login transaction is committed
+		sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+		mockPgx.ExpectBegin()
+
+		mockQuerier.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any(), repository.CreateSessionParams{
+				UserID:    mockUserWithValidPassword.ID,
+				Device:    helper.PgString("Chrome on Windows"),
+				IpAddress: helper.PgString("127.0.0.1"),
+				UserAgent: helper.PgString(userAgent),
+			}).
+			Return(repository.Session{ID: sessionID, UserID: mockUserWithValidPassword.ID}, nil)
+
+		mockQuerier.EXPECT().GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleUser).Return(nil, nil)
+
+		mockQuerier.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CreateRefreshTokenParams) (pgtype.UUID, error) {
+				assert.Equal(t, mockUserWithValidPassword.ID, arg.UserID)
+				assert.Equal(t, sessionID, arg.FamilyID)
+				assert.Len(t, arg.TokenHash, 64)
+
+				return pgtype.UUID{Bytes: uuid.New(), Valid: true}, nil
+			})
+
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
 		mockPgx.ExpectRollback()
 
 		res, err := service.Login(ctx, loginReq)
@@ -439,5 +604,671 @@ func TestAuthService_Login(t *testing.T) {
 		assert.NotNil(t, res)
 		assert.NotEmpty(t, res.AccessToken)
 		assert.NotEmpty(t, res.RefreshToken)
+
+		claims, err := jwt.ValidateToken(res.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, sessionID.String(), claims.SessionID)
+	})
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	ctx := context.Background()
+	mockError := errors.New("error")
+
+	mockID := uuid.New()
+	familyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	mockUser := repository.User{
+		ID:         pgtype.UUID{Bytes: mockID, Valid: true},
+		Email:      "test@gmail.com",
+		Role:       "user",
+		IsVerified: pgtype.Bool{Bool: true, Valid: true},
+	}
+
+	refreshToken, _ := jwt.GenerateRefreshToken(mockID.String(), mockUser.Email, mockUser.Role, familyID.String())
+	storedToken := repository.RefreshToken{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    mockUser.ID,
+		FamilyID:  familyID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	setup := func(t *testing.T) (AuthService, *mock.MockQuerier, pgxmock.PgxPoolIface, *log.MockInterface, *sessionMock.MockRevoker) {
+		ctrl := gomock.NewController(t)
+
+		mockQuerier := mock.NewMockQuerier(ctrl)
+		mockPgx, _ := pgxmock.NewPool()
+		mockLogger := log.NewMockInterface(ctrl)
+		mockMail := mail.NewMockService(ctrl)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+
+		return New(mockPgx, mockQuerier, nil, mockLogger, mockMail, mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil), mockQuerier, mockPgx, mockLogger, mockRevoker
+	}
+
+	expectSessionRevoked := func(mockQuerier *mock.MockQuerier, mockRevoker *sessionMock.MockRevoker) {
+		mockQuerier.EXPECT().
+			RevokeSession(gomock.Any(), gomock.Any(), repository.RevokeSessionParams{ID: familyID, UserID: mockUser.ID}).
+			Return(int64(1), nil)
+
+		mockQuerier.EXPECT().
+			RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), familyID).
+			Return(nil)
+
+		mockRevoker.EXPECT().
+			RevokeSession(gomock.Any(), familyID.String()).
+			Return(nil)
+	}
+
+	t.Run("error: access token is rejected", func(t *testing.T) {
+		service, _, _, mockLogger, _ := setup(t)
+
+		accessToken, _ := jwt.GenerateAccessToken(mockID.String(), mockUser.Email, mockUser.Role, "", nil, familyID.String())
+
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: accessToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: malformed token", func(t *testing.T) {
+		service, _, _, mockLogger, _ := setup(t)
+
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: "not-a-token"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: unknown refresh token", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockLogger, _ := setup(t)
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(repository.RefreshToken{}, pgx.ErrNoRows)
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: revoked session", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockLogger, _ := setup(t)
+
+		revokedToken := storedToken
+		revokedToken.RevokedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(revokedToken, nil)
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: reused refresh token revokes the family", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockLogger, mockRevoker := setup(t)
+
+		usedToken := storedToken
+		usedToken.UsedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(usedToken, nil)
+
+		expectSessionRevoked(mockQuerier, mockRevoker)
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: concurrent use revokes the family", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockLogger, mockRevoker := setup(t)
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(storedToken, nil)
+
+		mockQuerier.EXPECT().
+			UseRefreshToken(gomock.Any(), gomock.Any(), storedToken.ID).
+			Return(int64(0), nil)
+
+		expectSessionRevoked(mockQuerier, mockRevoker)
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: failure using refresh token", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockLogger, _ := setup(t)
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(storedToken, nil)
+
+		mockQuerier.EXPECT().
+			UseRefreshToken(gomock.Any(), gomock.Any(), storedToken.ID).
+			Return(int64(0), mockError)
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusInternalServerError, failure.GetCode(err))
+	})
+
+	t.Run("success: rotates the token within the family", func(t *testing.T) {
+		service, mockQuerier, mockPgx, _, _ := setup(t)
+
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+
+		mockQuerier.EXPECT().
+			GetRefreshTokenByHash(gomock.Any(), gomock.Any(), helper.HashToken(refreshToken)).
+			Return(storedToken, nil)
+
+		mockQuerier.EXPECT().
+			UseRefreshToken(gomock.Any(), gomock.Any(), storedToken.ID).
+			Return(int64(1), nil)
+
+		mockQuerier.EXPECT().
+			GetUserByID(gomock.Any(), gomock.Any(), mockUser.ID).
+			Return(mockUser, nil)
+
+		mockQuerier.EXPECT().
+			TouchSession(gomock.Any(), gomock.Any(), repository.TouchSessionParams{
+				ID:        familyID,
+				IpAddress: helper.PgString("127.0.0.1"),
+				UserAgent: helper.PgString(userAgent),
+			}).
+			Return(nil)
+
+		var newHash string
+
+		mockQuerier.EXPECT().GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleUser).Return(nil, nil)
+
+		mockQuerier.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CreateRefreshTokenParams) (pgtype.UUID, error) {
+				assert.Equal(t, familyID, arg.FamilyID)
+				assert.Equal(t, mockUser.ID, arg.UserID)
+				newHash = arg.TokenHash
+
+				return pgtype.UUID{Bytes: uuid.New(), Valid: true}, nil
+			})
+
+		res, err := service.Refresh(ctx, dto.RefreshTokenRequest{
+			RefreshToken: refreshToken,
+			ClientInfo:   dto.NewClientInfo("127.0.0.1", userAgent),
+		})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.NotEqual(t, refreshToken, res.RefreshToken)
+		assert.Equal(t, helper.HashToken(res.RefreshToken), newHash)
+
+		claims, err := jwt.ValidateToken(res.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, jwt.TokenTypeAccess, claims.TokenType)
+		assert.Equal(t, familyID.String(), claims.SessionID)
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctx := context.Background()
+	mockError := errors.New("error")
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	setup := func(t *testing.T) (AuthService, *mock.MockQuerier, *log.MockInterface, *sessionMock.MockRevoker) {
+		ctrl := gomock.NewController(t)
+
+		mockQuerier := mock.NewMockQuerier(ctrl)
+		mockPgx, _ := pgxmock.NewPool()
+		mockLogger := log.NewMockInterface(ctrl)
+		mockRevoker := sessionMock.NewMockRevoker(ctrl)
+
+		return New(mockPgx, mockQuerier, nil, mockLogger, mail.NewMockService(ctrl), mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil), mockQuerier, mockLogger, mockRevoker
+	}
+
+	t.Run("error: token without session", func(t *testing.T) {
+		service, _, mockLogger, _ := setup(t)
+
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		err := service.Logout(ctx, userID.String(), "")
+
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+
+	t.Run("error: failure revoking access tokens", func(t *testing.T) {
+		service, mockQuerier, mockLogger, mockRevoker := setup(t)
+
+		mockQuerier.EXPECT().
+			RevokeSession(gomock.Any(), gomock.Any(), repository.RevokeSessionParams{ID: sessionID, UserID: userID}).
+			Return(int64(1), nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), sessionID).Return(nil)
+		mockRevoker.EXPECT().RevokeSession(gomock.Any(), sessionID.String()).Return(mockError)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.Logout(ctx, userID.String(), sessionID.String())
+
+		assert.Equal(t, http.StatusInternalServerError, failure.GetCode(err))
+	})
+
+	t.Run("success: revokes the session and its tokens", func(t *testing.T) {
+		service, mockQuerier, _, mockRevoker := setup(t)
+
+		mockQuerier.EXPECT().
+			RevokeSession(gomock.Any(), gomock.Any(), repository.RevokeSessionParams{ID: sessionID, UserID: userID}).
+			Return(int64(1), nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), sessionID).Return(nil)
+		mockRevoker.EXPECT().RevokeSession(gomock.Any(), sessionID.String()).Return(nil)
+
+		err := service.Logout(ctx, userID.String(), sessionID.String())
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestAuthService_MFA(t *testing.T) {
+	ctx := context.Background()
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	secret, _ := totp.GenerateSecret()
+	cfg := &config.Config{MFA: config.MFA{RequiredRoles: []string{"staff", "admin"}}}
+
+	setup := func(t *testing.T) (AuthService, *mock.MockQuerier, pgxmock.PgxPoolIface, *redisMock.MockIRedisCache, *log.MockInterface) {
+		ctrl := gomock.NewController(t)
+
+		mockQuerier := mock.NewMockQuerier(ctrl)
+		mockPgx, _ := pgxmock.NewPool()
+		mockCache := redisMock.NewMockIRedisCache(ctrl)
+		mockLogger := log.NewMockInterface(ctrl)
+
+		service := New(mockPgx, mockQuerier, nil, mockLogger, mail.NewMockService(ctrl), sessionMock.NewMockRevoker(ctrl), guardMock.NewMockGuard(ctrl), mockCache, cfg, nil)
+
+		return service, mockQuerier, mockPgx, mockCache, mockLogger
+	}
+
+	expectChallenge := func(mockCache *redisMock.MockIRedisCache, challenge mfaChallenge) {
+		mockCache.EXPECT().
+			Get(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, value any) error {
+				*value.(*mfaChallenge) = challenge
+
+				return nil
+			})
+	}
+
+	t.Run("success: required role without mfa must enroll", func(t *testing.T) {
+		service, mockQuerier, _, mockCache, _ := setup(t)
+
+		user := repository.User{ID: userID, Email: "admin@gmail.com", Role: "admin"}
+
+		mockQuerier.EXPECT().GetUserMFA(gomock.Any(), gomock.Any(), userID).Return(repository.UserMfa{}, pgx.ErrNoRows)
+		mockCache.EXPECT().
+			Save(gomock.Any(), gomock.Any(), gomock.Any(), int(constant.MFAChallengeExpiry.Seconds())).
+			DoAndReturn(func(_ context.Context, _ string, value any, _ int) error {
+				assert.Equal(t, mfaChallenge{UserID: userID.String(), Enroll: true}, value)
+
+				return nil
+			})
+
+		res, err := service.CompleteLogin(ctx, user, dto.ClientInfo{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.MFAToken)
+		assert.True(t, res.MFAEnrollmentRequired)
+		assert.Empty(t, res.AccessToken)
+	})
+
+	t.Run("error: wrong code is counted", func(t *testing.T) {
+		service, mockQuerier, _, mockCache, _ := setup(t)
+
+		expectChallenge(mockCache, mfaChallenge{UserID: userID.String()})
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID}, nil)
+		mockQuerier.EXPECT().
+			GetUserMFA(gomock.Any(), gomock.Any(), userID).
+			Return(repository.UserMfa{UserID: userID, Secret: secret, EnabledAt: pgtype.Timestamp{Time: time.Now(), Valid: true}}, nil)
+		mockQuerier.EXPECT().UseMFARecoveryCode(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil)
+		mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(constant.MFAMaxAttempts), nil)
+		mockCache.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := service.VerifyMFA(ctx, dto.MFAVerifyRequest{MFAToken: "token", Code: "AAAAAAAA-BBBBBBBB"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		assert.Equal(t, "too many invalid codes, log in again", err.Error())
+	})
+
+	t.Run("success: valid code issues tokens", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockCache, _ := setup(t)
+
+		code, _ := totp.Code(secret, time.Now())
+		sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+		expectChallenge(mockCache, mfaChallenge{UserID: userID.String()})
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Role: "admin"}, nil)
+		mockQuerier.EXPECT().
+			GetUserMFA(gomock.Any(), gomock.Any(), userID).
+			Return(repository.UserMfa{UserID: userID, Secret: secret, EnabledAt: pgtype.Timestamp{Time: time.Now(), Valid: true}}, nil)
+		mockQuerier.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil)
+		mockCache.EXPECT().GetDel(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.Session{ID: sessionID, UserID: userID}, nil)
+		mockQuerier.EXPECT().GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleAdmin).Return([]string{constant.PermissionUsersRead}, nil)
+		mockQuerier.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(pgtype.UUID{Bytes: uuid.New(), Valid: true}, nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+
+		res, err := service.VerifyMFA(ctx, dto.MFAVerifyRequest{MFAToken: "token", Code: code})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.AccessToken)
+		assert.NotEmpty(t, res.RefreshToken)
+	})
+
+	t.Run("error: required role cannot disable mfa", func(t *testing.T) {
+		service, mockQuerier, _, _, _ := setup(t)
+
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Role: "staff"}, nil)
+
+		err := service.DisableMFA(ctx, userID.String(), dto.MFACodeRequest{Code: "123456"})
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+func TestAuthService_ResendVerification(t *testing.T) {
+	ctx := context.Background()
+	req := dto.ResendVerificationRequest{Email: "test@gmail.com"}
+
+	setup := func(t *testing.T) (AuthService, *mock.MockQuerier, pgxmock.PgxPoolIface, *redisMock.MockIRedisCache, *mail.MockService) {
+		ctrl := gomock.NewController(t)
+
+		mockQuerier := mock.NewMockQuerier(ctrl)
+		mockPgx, _ := pgxmock.NewPool()
+		mockCache := redisMock.NewMockIRedisCache(ctrl)
+		mockMail := mail.NewMockService(ctrl)
+
+		service := New(mockPgx, mockQuerier, nil, log.NewMockInterface(ctrl), mockMail, sessionMock.NewMockRevoker(ctrl), guardMock.NewMockGuard(ctrl), mockCache, &config.Config{}, nil)
+
+		return service, mockQuerier, mockPgx, mockCache, mockMail
+	}
+
+	expectCooldownStarted := func(mockCache *redisMock.MockIRedisCache) {
+		mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), int(constant.VerificationResendCooldown.Seconds())).Return(int64(1), nil)
+	}
+
+	t.Run("error: cooldown", func(t *testing.T) {
+		service, _, _, mockCache, _ := setup(t)
+
+		mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), int(constant.VerificationResendCooldown.Seconds())).Return(int64(2), nil)
+
+		res, err := service.ResendVerification(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusTooManyRequests, failure.GetCode(err))
+	})
+
+	t.Run("success: unknown email gets the same answer", func(t *testing.T) {
+		service, mockQuerier, _, mockCache, _ := setup(t)
+
+		expectCooldownStarted(mockCache)
+		mockQuerier.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any(), req.Email).Return(repository.User{}, pgx.ErrNoRows)
+
+		res, err := service.ResendVerification(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, resendVerificationMessage, res.Message)
+	})
+
+	t.Run("success: replaces previous tokens", func(t *testing.T) {
+		service, mockQuerier, mockPgx, mockCache, mockMail := setup(t)
+
+		user := repository.User{
+			ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Email:      req.Email,
+			Password:   pgtype.Text{String: "hash", Valid: true},
+			IsVerified: pgtype.Bool{Bool: false, Valid: true},
+		}
+		sent := make(chan string)
+
+		expectCooldownStarted(mockCache)
+		mockQuerier.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any(), req.Email).Return(user, nil)
+
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().DeleteEmailVerificationsByUserID(gomock.Any(), gomock.Any(), user.ID).Return(nil)
+
+		var tokenHash string
+
+		mockQuerier.EXPECT().
+			CreateEmailVerification(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CreateEmailVerificationParams) (repository.EmailVerification, error) {
+				tokenHash = arg.TokenHash
+
+				return repository.EmailVerification{}, nil
+			})
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+
+		mockMail.EXPECT().
+			SendVerificationEmail(user.Email, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_, _, token string) error {
+				sent <- token
+
+				return nil
+			})
+
+		res, err := service.ResendVerification(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, resendVerificationMessage, res.Message)
+
+		select {
+		case token := <-sent:
+			// Only the hash is stored, the plain token is emailed
+			assert.Equal(t, helper.HashToken(token), tokenHash)
+		case <-time.After(time.Second):
+			t.Fatal("verification email was not sent")
+		}
+	})
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockBookingQuerier := bookingMock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+
+	service := New(mockPgx, mockQuerier, mockBookingQuerier, mockLogger, mail.NewMockService(ctrl), sessionMock.NewMockRevoker(ctrl), guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
+
+	req := dto.EmailVerificationRequest{Token: "token"}
+	user := repository.User{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Email:      "guest@gmail.com",
+		IsVerified: pgtype.Bool{Bool: true, Valid: true},
+	}
+
+	t.Run("error: invalid or expired token", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any(), helper.HashToken(req.Token)).Return(repository.EmailVerification{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any())
+		mockPgx.ExpectRollback()
+
+		res, err := service.VerifyEmail(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("success: attaches guest bookings made for the email", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().GetEmailVerificationByTokenHash(gomock.Any(), gomock.Any(), helper.HashToken(req.Token)).Return(repository.EmailVerification{UserID: user.ID}, nil)
+		mockQuerier.EXPECT().VerifyEmail(gomock.Any(), gomock.Any(), user.ID).Return(user, nil)
+		mockBookingQuerier.EXPECT().AttachGuestBookings(gomock.Any(), gomock.Any(), bookingRepository.AttachGuestBookingsParams{
+			UserID: user.ID,
+			Email:  user.Email,
+		}).Return(int64(2), nil)
+		mockQuerier.EXPECT().DeleteEmailVerificationsByUserID(gomock.Any(), gomock.Any(), user.ID).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+
+		res, err := service.VerifyEmail(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Email verified successfully", res.Message)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+	})
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRevoker := sessionMock.NewMockRevoker(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, log.NewMockInterface(ctrl), mail.NewMockService(ctrl), mockRevoker, guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	req := dto.ResetPasswordRequest{Token: "token", Password: "newpassword"}
+
+	t.Run("success: logs out every session", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().GetPasswordResetByTokenHash(gomock.Any(), gomock.Any(), helper.HashToken(req.Token)).Return(repository.PasswordReset{UserID: userID}, nil)
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Password: helper.PgString("hash")}, nil)
+		mockQuerier.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.User{ID: userID}, nil)
+		mockQuerier.EXPECT().DeletePasswordResetsByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().RevokeOtherSessions(gomock.Any(), gomock.Any(), repository.RevokeOtherSessionsParams{UserID: userID}).Return([]pgtype.UUID{sessionID}, nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), sessionID).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+		mockRevoker.EXPECT().RevokeUser(gomock.Any(), userID.String()).Return(nil)
+
+		res, err := service.ResetPassword(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Password reset successfully", res.Message)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+	})
+}
+
+func TestAuthService_PhoneLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockGuard := guardMock.NewMockGuard(ctrl)
+	mockOTP := otpMock.NewMockService(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, mockLogger, mail.NewMockService(ctrl), sessionMock.NewMockRevoker(ctrl), mockGuard, redisMock.NewMockIRedisCache(ctrl), &config.Config{}, mockOTP)
+
+	req := dto.PhoneLoginRequest{
+		Phone:      "+6281234567890",
+		Code:       "123456",
+		ClientInfo: dto.NewClientInfo("127.0.0.1", userAgent),
+	}
+
+	t.Run("error: wrong code counts as a failed login", func(t *testing.T) {
+		mockGuard.EXPECT().Check(gomock.Any(), req.Phone, "127.0.0.1").Return(time.Duration(0), nil)
+		mockOTP.EXPECT().Verify(gomock.Any(), constant.OTPPurposeLogin, req.Phone, req.Code).Return(otp.ErrInvalidCode)
+		mockLogger.EXPECT().Error(gomock.Any())
+		mockGuard.EXPECT().Fail(gomock.Any(), req.Phone, "127.0.0.1").Return(time.Time{}, nil)
+
+		res, err := service.PhoneLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		assert.Equal(t, invalidPhoneCode, err.Error())
+	})
+
+	t.Run("error: number no longer verified by the account", func(t *testing.T) {
+		mockGuard.EXPECT().Check(gomock.Any(), req.Phone, "127.0.0.1").Return(time.Duration(0), nil)
+		mockOTP.EXPECT().Verify(gomock.Any(), constant.OTPPurposeLogin, req.Phone, req.Code).Return(nil)
+		mockQuerier.EXPECT().GetUserByVerifiedPhone(gomock.Any(), gomock.Any(), helper.PgString(req.Phone)).Return(repository.User{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any())
+
+		res, err := service.PhoneLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+	})
+}
+
+func TestAuthService_SendPhoneLoginCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockOTP := otpMock.NewMockService(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, mockLogger, mail.NewMockService(ctrl), sessionMock.NewMockRevoker(ctrl), guardMock.NewMockGuard(ctrl), redisMock.NewMockIRedisCache(ctrl), &config.Config{}, mockOTP)
+
+	req := dto.PhoneLoginCodeRequest{Phone: "+6281234567890"}
+
+	t.Run("success: unknown number is not texted", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByVerifiedPhone(gomock.Any(), gomock.Any(), helper.PgString(req.Phone)).Return(repository.User{}, pgx.ErrNoRows)
+
+		res, err := service.SendPhoneLoginCode(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, phoneLoginCodeMessage, res.Message)
+	})
+
+	t.Run("success: cooldown answers the same", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByVerifiedPhone(gomock.Any(), gomock.Any(), helper.PgString(req.Phone)).Return(repository.User{}, nil)
+		mockOTP.EXPECT().Send(gomock.Any(), constant.OTPPurposeLogin, req.Phone).Return(otp.ErrCooldown)
+		mockLogger.EXPECT().Info(gomock.Any())
+
+		res, err := service.SendPhoneLoginCode(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, phoneLoginCodeMessage, res.Message)
 	})
 }
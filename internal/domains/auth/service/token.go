@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/jwt"
+)
+
+// IssueTokens starts a new session for the user and returns its first token pair.
+// The session ID doubles as the refresh token family.
+func (s *authService) IssueTokens(ctx context.Context, user repository.User, client dto.ClientInfo) (*dto.UserLoginResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to begin transaction: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("issue-tokens - service - failed to rollback transaction: %w", err)
+		}
+	}(tx, ctx)
+
+	session, err := s.repo.CreateSession(ctx, tx, repository.CreateSessionParams{
+		UserID:    user.ID,
+		Device:    helper.PgString(client.Device),
+		IpAddress: helper.PgString(client.IPAddress),
+		UserAgent: helper.PgString(client.UserAgent),
+	})
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to create session: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	res, err := s.issueTokens(ctx, tx, user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("issue-tokens - service - failed to commit transaction: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return res, nil
+}
+
+func (s *authService) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.UserLoginResponse, error) {
+	claims, err := jwt.ValidateToken(req.RefreshToken)
+	if err != nil || claims.TokenType != jwt.TokenTypeRefresh {
+		s.logger.Error("refresh - service - invalid refresh token")
+
+		return nil, failure.Unauthorized("invalid refresh token")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("refresh - service - failed to begin transaction: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("refresh - service - failed to rollback transaction: %w", err)
+		}
+	}(tx, ctx)
+
+	stored, err := s.repo.GetRefreshTokenByHash(ctx, tx, helper.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("refresh - service - refresh token not found")
+
+			return nil, failure.Unauthorized("invalid refresh token")
+		}
+
+		s.logger.Error("refresh - service - failed to get refresh token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if stored.RevokedAt.Valid {
+		s.logger.Error("refresh - service - refresh token has been revoked")
+
+		return nil, failure.Unauthorized("session has been revoked")
+	}
+
+	if stored.UsedAt.Valid {
+		return nil, s.revokeFamily(ctx, stored)
+	}
+
+	// A concurrent refresh may have used the token between the lookup and here
+	used, err := s.repo.UseRefreshToken(ctx, tx, stored.ID)
+	if err != nil {
+		s.logger.Error("refresh - service - failed to use refresh token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if used == 0 {
+		return nil, s.revokeFamily(ctx, stored)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, tx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("refresh - service - user not found")
+
+			return nil, failure.Unauthorized("invalid refresh token")
+		}
+
+		s.logger.Error("refresh - service - failed to get user: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	err = s.repo.TouchSession(ctx, tx, repository.TouchSessionParams{
+		ID:        stored.FamilyID,
+		IpAddress: helper.PgString(req.IPAddress),
+		UserAgent: helper.PgString(req.UserAgent),
+	})
+	if err != nil {
+		s.logger.Error("refresh - service - failed to touch session: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	res, err := s.issueTokens(ctx, tx, user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("refresh - service - failed to commit transaction: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return res, nil
+}
+
+// revokeFamily ends the session of a refresh token presented twice, since either the client or an
+// attacker holds a stolen copy.
+// It runs outside the caller's transaction so the revocation survives the rollback.
+func (s *authService) revokeFamily(ctx context.Context, stored repository.RefreshToken) error {
+	s.logger.Error("refresh - service - refresh token reuse detected, revoking family %s", stored.FamilyID.String())
+
+	if err := s.revokeSession(ctx, stored.UserID, stored.FamilyID); err != nil {
+		return err
+	}
+
+	return failure.Unauthorized("refresh token has already been used")
+}
+
+func (s *authService) Logout(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		s.logger.Error("logout - service - token has no session")
+
+		return failure.Unauthorized("token has no session, log in again")
+	}
+
+	return s.revokeSession(ctx, helper.PgUUID(userID), helper.PgUUID(sessionID))
+}
+
+// revokeSession ends a session in the database and rejects its outstanding access tokens.
+func (s *authService) revokeSession(ctx context.Context, userID, sessionID pgtype.UUID) error {
+	if _, err := s.repo.RevokeSession(ctx, s.db, repository.RevokeSessionParams{ID: sessionID, UserID: userID}); err != nil {
+		s.logger.Error("revoke-session - service - failed to revoke session: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, s.db, sessionID); err != nil {
+		s.logger.Error("revoke-session - service - failed to revoke refresh token family: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err := s.revoker.RevokeSession(ctx, sessionID.String()); err != nil {
+		s.logger.Error("revoke-session - service - failed to revoke access tokens: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+// revokeSessions ends every session of the user in the database, including their refresh token
+// families. The caller rejects outstanding access tokens with the revoker once the change is committed.
+func (s *authService) revokeSessions(ctx context.Context, db repository.DBTX, userID pgtype.UUID) error {
+	sessionIDs, err := s.repo.RevokeOtherSessions(ctx, db, repository.RevokeOtherSessionsParams{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err = s.repo.RevokeRefreshTokenFamily(ctx, db, sessionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *authService) issueTokens(ctx context.Context, db repository.DBTX, user repository.User, familyID pgtype.UUID) (*dto.UserLoginResponse, error) {
+	// Permissions are resolved on every issue, so a refresh picks up changes to the role
+	permissions, err := s.repo.GetRolePermissions(ctx, db, user.Role)
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to get role permissions: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	accessToken, err := jwt.GenerateAccessToken(user.ID.String(), user.Email, user.Role, user.OrganizationID.String(), permissions, familyID.String())
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to generate access token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	refreshToken, err := jwt.GenerateRefreshToken(user.ID.String(), user.Email, user.Role, familyID.String())
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to generate refresh token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	_, err = s.repo.CreateRefreshToken(ctx, db, repository.CreateRefreshTokenParams{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: helper.HashToken(refreshToken),
+		Ttl:       helper.PgInterval(jwt.RefreshTokenExpiry()),
+	})
+	if err != nil {
+		s.logger.Error("issue-tokens - service - failed to store refresh token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return new(dto.UserLoginResponse).ToLoginResponse(accessToken, refreshToken), nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+const (
+	cacheVerificationCooldownKey = "verification:cooldown:%s"
+
+	resendVerificationMessage = "If the email belongs to an unverified account, a new verification link has been sent"
+)
+
+// ResendVerification sends a new verification link and invalidates the earlier ones. It answers the same
+// whether or not the email belongs to an unverified account, and the cooldown applies to any email.
+func (s *authService) ResendVerification(ctx context.Context, req dto.ResendVerificationRequest) (*dto.ResendVerificationResponse, error) {
+	if err := s.startVerificationCooldown(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	res := &dto.ResendVerificationResponse{Message: resendVerificationMessage}
+
+	user, err := s.repo.GetUserByEmail(ctx, s.db, req.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, nil
+	}
+
+	if err != nil {
+		s.logger.Error("resend-verification - service - failed to get user by email: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	// OAuth users are verified by their provider
+	if helper.BoolFromPg(user.IsVerified) || !user.Password.Valid {
+		return res, nil
+	}
+
+	token, err := helper.GenerateRandomToken(tokenLength)
+	if err != nil {
+		s.logger.Error("resend-verification - service - failed to generate verification token: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	err = s.withTx(ctx, "resend-verification", func(tx pgx.Tx) error {
+		if err := s.repo.DeleteEmailVerificationsByUserID(ctx, tx, user.ID); err != nil {
+			s.logger.Error("resend-verification - service - failed to delete previous tokens: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		_, err := s.repo.CreateEmailVerification(ctx, tx, repository.CreateEmailVerificationParams{
+			UserID:    user.ID,
+			TokenHash: helper.HashToken(token),
+		})
+		if err != nil {
+			s.logger.Error("resend-verification - service - failed to create email verification: %w", err)
+
+			return failure.InternalError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := s.mailService.SendVerificationEmail(user.Email, user.FullName.String, token); err != nil {
+			s.logger.Error("resend-verification - service - failed to send verification email: %w", err)
+		}
+	}()
+
+	return res, nil
+}
+
+// startVerificationCooldown counts the requests for the email atomically, so concurrent requests cannot
+// both pass the check before either starts the cooldown.
+func (s *authService) startVerificationCooldown(ctx context.Context, email string) error {
+	key := fmt.Sprintf(cacheVerificationCooldownKey, helper.HashToken(strings.ToLower(strings.TrimSpace(email))))
+
+	requests, err := s.cache.Incr(ctx, key, int(constant.VerificationResendCooldown.Seconds()))
+	if err != nil {
+		s.logger.Error("resend-verification - service - failed to start cooldown: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if requests > 1 {
+		return failure.TooManyRequests("please wait before requesting another verification email")
+	}
+
+	return nil
+}
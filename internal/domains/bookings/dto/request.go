@@ -5,11 +5,24 @@ import (
 )
 
 type CreateBookingRequest struct {
-	FieldID   uuid.UUID `json:"field_id" validate:"required,uuid"`
-	Date      string    `json:"date" validate:"required,datetime=2006-01-02" example:"2006-01-02"`
-	StartTime string    `json:"start_time" validate:"required,datetime=15:04" example:"15:04"`
-	Duration  int       `json:"duration" validate:"required"`
-	Cash      *bool     `json:"cash" validate:"required"`
+	FieldID        uuid.UUID `json:"field_id" validate:"required,uuid"`
+	Date           string    `json:"date" validate:"required,datetime=2006-01-02" example:"2006-01-02"`
+	StartTime      string    `json:"start_time" validate:"required,datetime=15:04" example:"15:04"`
+	Duration       int       `json:"duration" validate:"required"`
+	Cash           *bool     `json:"cash" validate:"required"`
+	PaymentChannel string    `json:"payment_channel" validate:"omitempty,oneof=INVOICE QRIS VIRTUAL_ACCOUNT EWALLET" example:"QRIS"`
+	ChannelCode    string    `json:"channel_code" validate:"required_if=PaymentChannel VIRTUAL_ACCOUNT,required_if=PaymentChannel EWALLET" example:"BCA"`
+	MobileNumber   string    `json:"mobile_number" validate:"omitempty,e164" example:"+628123456789"`
+	// CustomerID and Guest let staff book a cash booking for someone else
+	CustomerID string        `json:"customer_id" validate:"omitempty,uuid,excluded_with=Guest"`
+	Guest      *GuestRequest `json:"guest" validate:"omitempty"`
+}
+
+// GuestRequest is a walk-in customer without an account, reachable by phone or email.
+type GuestRequest struct {
+	Name  string `json:"name" validate:"required,max=255"`
+	Phone string `json:"phone" validate:"required_without=Email,omitempty,e164" example:"+628123456789"`
+	Email string `json:"email" validate:"required_without=Phone,omitempty,email,max=255"`
 }
 
 type GetBookedSlotsRequest struct {
@@ -20,4 +33,6 @@ type GetBookedSlotsRequest struct {
 type CancelUserBookingRequest struct {
 	BookingID string `json:"booking_id" validate:"required,uuid" swaggerignore:"true"`
 	UserID    string `json:"user_id" validate:"required,uuid" swaggerignore:"true"`
+	// Permissions decide whether staff may cancel bookings of other users at their locations
+	Permissions []string `json:"-" swaggerignore:"true"`
 }
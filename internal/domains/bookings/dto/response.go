@@ -4,38 +4,85 @@ import (
 	"github.com/savioruz/goth/internal/domains/bookings/repository"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
 )
 
 type BookingResponse struct {
-	ID          string `json:"id"`
-	FieldID     string `json:"field_id"`
-	FieldName   string `json:"field_name,omitempty"`
+	ID           string      `json:"id"`
+	FieldID      string      `json:"field_id"`
+	FieldName    string      `json:"field_name,omitempty"`
+	BookingDate  string      `json:"booking_date"`
+	StartTime    string      `json:"start_time"`
+	EndTime      string      `json:"end_time"`
+	Subtotal     money.Money `json:"subtotal"`
+	TaxRate      float64     `json:"tax_rate"`
+	TaxInclusive bool        `json:"tax_inclusive"`
+	TaxAmount    money.Money `json:"tax_amount"`
+	TotalPrice   money.Money `json:"total_price"`
+	Status       string      `json:"status"`
+	APIKeyID     string      `json:"api_key_id,omitempty"`
+	UserID       string      `json:"user_id,omitempty"`
+	CreatedBy    string      `json:"created_by,omitempty"`
+	GuestName    string      `json:"guest_name,omitempty"`
+	GuestPhone   string      `json:"guest_phone,omitempty"`
+	GuestEmail   string      `json:"guest_email,omitempty"`
+	CreatedAt    string      `json:"created_at"`
+	UpdatedAt    string      `json:"updated_at"`
+}
+
+func (b BookingResponse) FromModel(model repository.Booking) BookingResponse {
+	startTime, _ := helper.PgTimeToString(model.StartTime)
+	endTime, _ := helper.PgTimeToString(model.EndTime)
+
+	return BookingResponse{
+		ID:           model.ID.String(),
+		FieldID:      model.FieldID.String(),
+		BookingDate:  model.BookingDate.Time.Format(constant.DateFormat),
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Subtotal:     money.FromPgOrZero(model.Subtotal, model.Currency),
+		TaxRate:      helper.Float64FromPg(model.TaxRate),
+		TaxInclusive: model.TaxInclusive,
+		TaxAmount:    money.FromPgOrZero(model.TaxAmount, model.Currency),
+		TotalPrice:   money.FromPgOrZero(model.TotalPrice, model.Currency),
+		Status:       model.Status,
+		APIKeyID:     model.ApiKeyID.String(),
+		UserID:       model.UserID.String(),
+		CreatedBy:    model.CreatedBy.String(),
+		GuestName:    model.GuestName.String,
+		GuestPhone:   model.GuestPhone.String,
+		GuestEmail:   model.GuestEmail.String,
+		CreatedAt:    model.CreatedAt.Time.Format(constant.FullDateFormat),
+		UpdatedAt:    model.UpdatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
+
+// SharedBookingResponse is the redacted view of a booking behind a share link: no customer, no price.
+type SharedBookingResponse struct {
+	FieldName   string `json:"field_name"`
 	BookingDate string `json:"booking_date"`
 	StartTime   string `json:"start_time"`
 	EndTime     string `json:"end_time"`
-	TotalPrice  int64  `json:"total_price"`
 	Status      string `json:"status"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
 }
 
-func (b BookingResponse) FromModel(model repository.Booking) BookingResponse {
+func (b SharedBookingResponse) FromModel(model repository.Booking, fieldName string) SharedBookingResponse {
 	startTime, _ := helper.PgTimeToString(model.StartTime)
 	endTime, _ := helper.PgTimeToString(model.EndTime)
 
-	return BookingResponse{
-		ID:          model.ID.String(),
-		FieldID:     model.FieldID.String(),
+	return SharedBookingResponse{
+		FieldName:   fieldName,
 		BookingDate: model.BookingDate.Time.Format(constant.DateFormat),
 		StartTime:   startTime,
 		EndTime:     endTime,
-		TotalPrice:  helper.Int64FromPg(model.TotalPrice),
 		Status:      model.Status,
-		CreatedAt:   model.CreatedAt.Time.Format(constant.FullDateFormat),
-		UpdatedAt:   model.UpdatedAt.Time.Format(constant.FullDateFormat),
 	}
 }
 
+type BookingShareResponse struct {
+	Token string `json:"token"`
+}
+
 type GetBookingsResponse struct {
 	Bookings   []BookingResponse `json:"bookings"`
 	TotalItems int               `json:"total_items"`
@@ -13,6 +13,7 @@ import (
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/gdto"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/receipt"
 )
 
 type Handler struct {
@@ -35,30 +36,37 @@ const (
 	routepath = "/bookings"
 )
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	bookings := r.Group(routepath)
 
-	bookings.Post("/", middleware.Jwt(), h.CreateBooking)
-	bookings.Get("/:id", h.GetBookingByID)
+	bookings.Post("/", authn.Authenticate(constant.APIKeyScopeBookingsWrite), h.CreateBooking)
+	bookings.Get("/shared/:token", h.GetSharedBooking)
+	bookings.Get("/:id", authn.Authenticate(constant.APIKeyScopeBookingsRead), h.GetBookingByID)
+	bookings.Post("/:id/share", authn.Jwt(), h.ShareBooking)
+	bookings.Delete("/:id/share", authn.Jwt(), h.UnshareBooking)
+	bookings.Get("/:id/receipt", authn.Authenticate(constant.APIKeyScopeBookingsRead), h.GetBookingReceipt)
 	bookings.Post("/slots", h.GetBookedSlots)
-	bookings.Put("/:id/cancel", middleware.Jwt(), h.CancelUserBooking)
-	bookings.Get("/", middleware.Jwt(), middleware.StaffOrAdmin(), h.GetAllBookings)
+	bookings.Put("/:id/cancel", authn.Authenticate(constant.APIKeyScopeBookingsWrite), h.CancelUserBooking)
+	bookings.Get("/", authn.Authenticate(constant.APIKeyScopeBookingsRead), middleware.RequirePermission(constant.PermissionBookingsReadAny), h.GetAllBookings)
 
-	r.Get("/users/bookings", middleware.Jwt(), h.GetUserBookings)
+	r.Get("/users/bookings", authn.Authenticate(constant.APIKeyScopeBookingsRead), h.GetUserBookings)
 }
 
 // CreateBooking godoc
 // @Summary Create new booking
-// @Description Create new booking
+// @Description Create new booking. Staff taking cash at the counter may book for an existing customer (customer_id) or a walk-in guest without an account
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param booking body dto.CreateBookingRequest true "Create booking request"
 // @Success 201 {object} response.Data[string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /bookings/ [post]
 // @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) CreateBooking(ctx *fiber.Ctx) error {
 	var req dto.CreateBookingRequest
 	if err := ctx.BodyParser(&req); err != nil {
@@ -104,21 +112,14 @@ func (h *Handler) CreateBooking(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, constant.ErrInvalidContextUserType)
 	}
 
-	roleRaw := ctx.Locals(constant.JwtFieldLevel)
-	if roleRaw == nil {
-		h.logger.Error(identifier, "role not found in context")
-
-		return response.WithError(ctx, failure.Unauthorized("role information not found"))
-	}
-
-	userRole, ok := roleRaw.(string)
+	permissions, ok := ctx.Locals(constant.JwtFieldPermissions).([]string)
 	if !ok {
-		h.logger.Error(identifier, "invalid role type in context")
+		h.logger.Error(identifier, "permissions not found in context")
 
-		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+		return response.WithError(ctx, failure.Unauthorized("permission information not found"))
 	}
 
-	res, err := h.service.CreateBooking(ctx.Context(), req, user, email, userRole)
+	res, err := h.service.CreateBooking(ctx.UserContext(), req, user, email, permissions)
 	if err != nil {
 		h.logger.Error(identifier, "error creating booking: "+err.Error())
 
@@ -130,17 +131,20 @@ func (h *Handler) CreateBooking(ctx *fiber.Ctx) error {
 
 // GetBookingByID godoc
 // @Summary Get booking by ID
-// @Description Get booking by ID
+// @Description Get booking by ID, available to its owner and staff of its location
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path string true "Booking ID"
 // @Success 200 {object} response.Data[dto.BookingResponse]
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /bookings/{id} [get]
 // @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) GetBookingByID(ctx *fiber.Ctx) error {
 	id := ctx.Params(constant.RequestParamID)
 	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
@@ -151,7 +155,14 @@ func (h *Handler) GetBookingByID(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	res, err := h.service.GetBookingByID(ctx.Context(), id)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "get - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetBookingByID(ctx.UserContext(), id, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, "error getting booking by id: %w", err)
 
@@ -161,6 +172,176 @@ func (h *Handler) GetBookingByID(ctx *fiber.Ctx) error {
 	return response.WithJSON(ctx, fiber.StatusOK, res)
 }
 
+// ShareBooking godoc
+// @Summary Create booking share link
+// @Description Issue a share link token for the booking, replacing any earlier one. The token is only returned once
+// @Tags bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 201 {object} response.Data[dto.BookingShareResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /bookings/{id}/share [post]
+// @Security BearerAuth
+func (h *Handler) ShareBooking(ctx *fiber.Ctx) error {
+	id := ctx.Params(constant.RequestParamID)
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		err = failure.BadRequestFromString("invalid booking id format")
+
+		h.logger.Error(identifier, "share - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "share - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.ShareBooking(ctx.UserContext(), id, userID, permissions)
+	if err != nil {
+		h.logger.Error(identifier, "error sharing booking: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusCreated, res)
+}
+
+// UnshareBooking godoc
+// @Summary Revoke booking share link
+// @Description Revoke the share link of the booking
+// @Tags bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /bookings/{id}/share [delete]
+// @Security BearerAuth
+func (h *Handler) UnshareBooking(ctx *fiber.Ctx) error {
+	id := ctx.Params(constant.RequestParamID)
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		err = failure.BadRequestFromString("invalid booking id format")
+
+		h.logger.Error(identifier, "unshare - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "unshare - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.UnshareBooking(ctx.UserContext(), id, userID, permissions); err != nil {
+		h.logger.Error(identifier, "error unsharing booking: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "booking share link revoked")
+}
+
+// GetSharedBooking godoc
+// @Summary Get shared booking
+// @Description Get the field, time and status of a booking through its share link token
+// @Tags bookings
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} response.Data[dto.SharedBookingResponse]
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /bookings/shared/{token} [get]
+func (h *Handler) GetSharedBooking(ctx *fiber.Ctx) error {
+	token := ctx.Params("token")
+	if err := h.validator.Var(token, "required,hexadecimal"); err != nil {
+		err = failure.BadRequestFromString("invalid share token")
+
+		h.logger.Error(identifier, "shared - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetSharedBooking(ctx.UserContext(), token)
+	if err != nil {
+		h.logger.Error(identifier, "error getting shared booking: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// GetBookingReceipt godoc
+// @Summary Download booking receipt
+// @Description Download the PDF receipt of a paid booking, available to its owner and staff
+// @Tags bookings
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /bookings/{id}/receipt [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+func (h *Handler) GetBookingReceipt(ctx *fiber.Ctx) error {
+	id := ctx.Params(constant.RequestParamID)
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		err = failure.BadRequestFromString("invalid booking id format")
+
+		h.logger.Error(identifier, "receipt - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	userRaw := ctx.Locals(constant.JwtFieldUser)
+	if userRaw == nil {
+		h.logger.Error(identifier, "user not found in context")
+
+		return response.WithError(ctx, failure.Unauthorized("user not authenticated"))
+	}
+
+	user, ok := userRaw.(string)
+	if !ok {
+		h.logger.Error(identifier, "invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	permissions, ok := ctx.Locals(constant.JwtFieldPermissions).([]string)
+	if !ok {
+		h.logger.Error(identifier, "permissions not found in context")
+
+		return response.WithError(ctx, failure.Unauthorized("permission information not found"))
+	}
+
+	res, err := h.service.GetBookingReceipt(ctx.UserContext(), id, user, permissions)
+	if err != nil {
+		h.logger.Error(identifier, "error getting booking receipt: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, receipt.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", receipt.Filename(id)))
+
+	return ctx.Status(fiber.StatusOK).Send(res)
+}
+
 // GetUserBookings godoc
 // @Summary Get user bookings
 // @Description Get bookings for the authenticated user
@@ -172,6 +353,8 @@ func (h *Handler) GetBookingByID(ctx *fiber.Ctx) error {
 // @Failure 400 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /users/bookings [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) GetUserBookings(ctx *fiber.Ctx) error {
 	userRaw := ctx.Locals(constant.JwtFieldUser)
 	if userRaw == nil {
@@ -194,7 +377,7 @@ func (h *Handler) GetUserBookings(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	res, err := h.service.GetUserBookings(ctx.Context(), user, req)
+	res, err := h.service.GetUserBookings(ctx.UserContext(), user, req)
 	if err != nil {
 		h.logger.Error(identifier, "error getting user bookings: "+err.Error())
 
@@ -232,7 +415,7 @@ func (h *Handler) GetBookedSlots(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, transformErr)
 	}
 
-	res, err := h.service.GetBookedSlots(ctx.Context(), req)
+	res, err := h.service.GetBookedSlots(ctx.UserContext(), req)
 	if err != nil {
 		h.logger.Error(identifier, "error getting booked slots: "+err.Error())
 
@@ -254,6 +437,7 @@ func (h *Handler) GetBookedSlots(ctx *fiber.Ctx) error {
 // @Failure 500 {object} response.Error
 // @Router /bookings/{id}/cancel [put]
 // @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) CancelUserBooking(ctx *fiber.Ctx) error {
 	id := ctx.Params(constant.RequestParamID)
 	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
@@ -278,12 +462,16 @@ func (h *Handler) CancelUserBooking(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, constant.ErrInvalidContextUserType)
 	}
 
+	// Staff may cancel bookings of other users, the service checks their locations
+	permissions, _ := ctx.Locals(constant.JwtFieldPermissions).([]string)
+
 	req := dto.CancelUserBookingRequest{
-		BookingID: id,
-		UserID:    user,
+		BookingID:   id,
+		UserID:      user,
+		Permissions: permissions,
 	}
 
-	err := h.service.CancelUserBooking(ctx.Context(), req)
+	err := h.service.CancelUserBooking(ctx.UserContext(), req)
 	if err != nil {
 		h.logger.Error(identifier, "error canceling booking: %w", err)
 
@@ -296,8 +484,8 @@ func (h *Handler) CancelUserBooking(ctx *fiber.Ctx) error {
 }
 
 // GetAllBookings godoc
-// @Summary Get all bookings (Admin/Staff only)
-// @Description Get all bookings with pagination for admin and staff users
+// @Summary Get all bookings (requires bookings:read_any)
+// @Description Get all bookings with pagination for admin and staff users. Staff only see the bookings at the locations they are assigned to
 // @Tags bookings
 // @Accept json
 // @Produce json
@@ -310,6 +498,7 @@ func (h *Handler) CancelUserBooking(ctx *fiber.Ctx) error {
 // @Failure 500 {object} response.Error
 // @Router /bookings [get]
 // @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) GetAllBookings(ctx *fiber.Ctx) error {
 	var req gdto.PaginationRequest
 	if err := ctx.QueryParser(&req); err != nil {
@@ -318,7 +507,14 @@ func (h *Handler) GetAllBookings(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	res, err := h.service.GetAllBookings(ctx.Context(), req)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "get all bookings - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetAllBookings(ctx.UserContext(), req, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, "get all bookings - error: %w", err)
 
@@ -3,50 +3,67 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/savioruz/goth/config"
 	"github.com/savioruz/goth/internal/domains/bookings/dto"
 	"github.com/savioruz/goth/internal/domains/bookings/repository"
 	fieldRepo "github.com/savioruz/goth/internal/domains/fields/repository"
+	locationRepo "github.com/savioruz/goth/internal/domains/locations/repository"
+	locationService "github.com/savioruz/goth/internal/domains/locations/service"
 	paymentDto "github.com/savioruz/goth/internal/domains/payments/dto"
 	"github.com/savioruz/goth/internal/domains/payments/service"
+	userRepo "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/apikey"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/gdto"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/money"
 	"github.com/savioruz/goth/pkg/postgres"
 	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/tenant"
 )
 
 type BookingService interface {
-	CreateBooking(ctx context.Context, req dto.CreateBookingRequest, userID, email, userRole string) (paymentDto.CreatePaymentInvoiceResponse, error)
-	GetBookingByID(ctx context.Context, id string) (dto.BookingResponse, error)
+	CreateBooking(ctx context.Context, req dto.CreateBookingRequest, userID, email string, permissions []string) (paymentDto.CreatePaymentInvoiceResponse, error)
+	GetBookingByID(ctx context.Context, id, userID string, permissions []string) (dto.BookingResponse, error)
+	ShareBooking(ctx context.Context, id, userID string, permissions []string) (dto.BookingShareResponse, error)
+	UnshareBooking(ctx context.Context, id, userID string, permissions []string) error
+	GetSharedBooking(ctx context.Context, token string) (dto.SharedBookingResponse, error)
 	GetUserBookings(ctx context.Context, userID string, req gdto.PaginationRequest) (dto.GetBookingsResponse, error)
 	CountUserBookings(ctx context.Context, userID string, req gdto.PaginationRequest) (int, error)
-	GetAllBookings(ctx context.Context, req gdto.PaginationRequest) (dto.GetBookingsResponse, error)
+	GetAllBookings(ctx context.Context, req gdto.PaginationRequest, userID string, permissions []string) (dto.GetBookingsResponse, error)
 	CountAllBookings(ctx context.Context, req gdto.PaginationRequest) (int, error)
 	GetBookedSlots(ctx context.Context, req dto.GetBookedSlotsRequest) (dto.GetBookedSlotsResponse, error)
 	CancelUserBooking(ctx context.Context, req dto.CancelUserBookingRequest) error
+	GetBookingReceipt(ctx context.Context, id, userID string, permissions []string) ([]byte, error)
 }
 
 type bookingService struct {
 	db             postgres.PgxIface
 	repo           repository.Querier
 	fieldRepo      fieldRepo.Querier
+	locationRepo   locationRepo.Querier
+	userRepo       userRepo.Querier
 	paymentService service.PaymentService
 	cache          redis.IRedisCache
 	cfg            *config.Config
 	logger         logger.Interface
 }
 
-func New(db postgres.PgxIface, r repository.Querier, f fieldRepo.Querier, p service.PaymentService, c redis.IRedisCache, cfg *config.Config, l logger.Interface) BookingService {
+func New(db postgres.PgxIface, r repository.Querier, f fieldRepo.Querier, lr locationRepo.Querier, u userRepo.Querier, p service.PaymentService, c redis.IRedisCache, cfg *config.Config, l logger.Interface) BookingService {
 	return &bookingService{
 		db:             db,
 		repo:           r,
 		fieldRepo:      f,
+		locationRepo:   lr,
+		userRepo:       u,
 		paymentService: p,
 		cache:          c,
 		cfg:            cfg,
@@ -59,10 +76,12 @@ const (
 	cacheCountBookingsKey = "bookings:count"
 	cacheGetBookingsKey   = "bookings"
 
+	shareTokenLength = 32
+
 	identifier = "service - booking - %s"
 )
 
-func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookingRequest, userID, email, userRole string) (res paymentDto.CreatePaymentInvoiceResponse, err error) {
+func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookingRequest, userID, email string, permissions []string) (res paymentDto.CreatePaymentInvoiceResponse, err error) {
 	isValid, err := helper.IsBookingTimeValid(req.Date, req.StartTime)
 	if err != nil {
 		s.logger.Error(identifier, "error validating booking time: "+err.Error())
@@ -76,6 +95,13 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 		return res, failure.BadRequestFromString("booking time cannot be in the past")
 	}
 
+	// Bookings for someone else are paid at the counter, an invoice would go to the wrong person
+	if (req.CustomerID != "" || req.Guest != nil) && !*req.Cash {
+		s.logger.Error(identifier, "booking on behalf of a customer without cash payment by user: "+userID)
+
+		return res, failure.BadRequestFromString("only cash bookings can be made for a customer or guest")
+	}
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		s.logger.Error(identifier, "error starting transaction: "+err.Error())
@@ -104,10 +130,11 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 	endTime := helper.PgTimeFromTime(endTimeObj)
 
 	overlaps, err := s.repo.CountOverlaps(ctx, tx, repository.CountOverlapsParams{
-		FieldID:     fieldID,
-		BookingDate: helper.PgDate(req.Date),
-		Column3:     startTime,
-		Column4:     endTime,
+		FieldID:        fieldID,
+		BookingDate:    helper.PgDate(req.Date),
+		Column3:        startTime,
+		Column4:        endTime,
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "error checking booking overlaps: "+err.Error())
@@ -121,7 +148,10 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 		return res, failure.Conflict("there are already bookings for this field at this time")
 	}
 
-	field, err := s.fieldRepo.GetFieldById(ctx, tx, fieldID)
+	field, err := s.fieldRepo.GetFieldById(ctx, tx, fieldRepo.GetFieldByIdParams{
+		ID:             fieldID,
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.logger.Error(identifier, "field not found with ID: "+fieldID.String())
@@ -134,6 +164,59 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 		return res, err
 	}
 
+	location, err := s.locationRepo.GetLocationById(ctx, tx, locationRepo.GetLocationByIdParams{
+		ID:             field.LocationID,
+		OrganizationID: field.OrganizationID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, "location not found for field ID: "+fieldID.String())
+
+			return res, failure.NotFound("location not found")
+		}
+
+		s.logger.Error(identifier, "error getting location by ID: "+err.Error())
+
+		return res, err
+	}
+
+	if location.Currency != field.Currency {
+		s.logger.Error(identifier, "field currency "+field.Currency+" does not match location currency "+location.Currency)
+
+		return res, failure.BadRequestFromString("field and location are priced in different currencies")
+	}
+
+	if *req.Cash {
+		if !slices.Contains(permissions, constant.PermissionBookingsCashPayment) {
+			s.logger.Error(identifier, "unauthorized cash payment attempt by user: %s", userID)
+
+			return res, failure.Forbidden("you are not allowed to create cash payments")
+		}
+
+		if err = s.authorizeLocation(ctx, tx, "create booking", location.ID, userID, permissions); err != nil {
+			return res, err
+		}
+	}
+
+	customer, err := s.bookingCustomer(ctx, tx, req, userID)
+	if err != nil {
+		return res, err
+	}
+
+	price, err := money.FromPg(field.Price, money.ParseCurrency(field.Currency))
+	if err != nil {
+		s.logger.Error(identifier, "invalid price of field "+field.ID.String()+": "+err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
+	fees, err := bookingFees(location, *req.Cash)
+	if err != nil {
+		s.logger.Error(identifier, "invalid fees of location "+location.ID.String()+": "+err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
 	var status string
 	if *req.Cash {
 		status = constant.BookingStatusPaid
@@ -141,16 +224,41 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 		status = constant.BookingStatusPending
 	}
 
-	totalPrice := helper.CalculateTotalPrice(helper.Int64FromPg(field.Price), req.Duration)
+	items := []paymentDto.InvoiceItem{{
+		ReferenceID: field.ID.String(),
+		Name:        field.Name,
+		Category:    field.Type,
+		Date:        req.Date,
+		StartTime:   req.StartTime,
+		Hours:       req.Duration,
+		UnitPrice:   price,
+		Discount:    money.Zero(price.Currency),
+	}}
+	description := fmt.Sprintf("Booking %s at %s on %s %s-%s", field.Name, location.Name, req.Date, req.StartTime, endTimeObj.Format(constant.HoursFormat))
+
+	taxRate := helper.Float64FromPg(location.TaxRate)
+	itemsTotal := paymentDto.CreatePaymentInvoice{Amount: money.Zero(price.Currency), Items: items, Fees: fees}.ItemsTotal()
+	subtotal, taxAmount, totalPrice := itemsTotal.SplitTax(taxRate, location.TaxInclusive)
 
 	booking, err := s.repo.InsertBooking(ctx, tx, repository.InsertBookingParams{
-		UserID:      helper.PgUUID(userID),
-		FieldID:     field.ID,
-		BookingDate: helper.PgDate(req.Date),
-		StartTime:   startTime,
-		EndTime:     endTime,
-		TotalPrice:  helper.PgInt64(totalPrice),
-		Status:      status,
+		UserID:         customer.UserID,
+		FieldID:        field.ID,
+		BookingDate:    helper.PgDate(req.Date),
+		StartTime:      startTime,
+		EndTime:        endTime,
+		TotalPrice:     totalPrice.Pg(),
+		Status:         status,
+		Subtotal:       subtotal.Pg(),
+		TaxRate:        location.TaxRate,
+		TaxInclusive:   location.TaxInclusive,
+		TaxAmount:      taxAmount.Pg(),
+		Currency:       string(totalPrice.Currency),
+		OrganizationID: field.OrganizationID,
+		ApiKeyID:       apikey.FromContext(ctx),
+		CreatedBy:      helper.PgUUID(userID),
+		GuestName:      customer.GuestName,
+		GuestPhone:     customer.GuestPhone,
+		GuestEmail:     customer.GuestEmail,
 	})
 	if err != nil {
 		s.logger.Error(identifier, "error inserting booking: "+err.Error())
@@ -165,20 +273,18 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 	}
 
 	if *req.Cash {
-		// Check if user has permission to create cash payments (staff or admin only)
-		if userRole != constant.UserRoleAdmin && userRole != constant.UserRoleStaff {
-			s.logger.Error(identifier, "unauthorized cash payment attempt by user role: %s", userRole)
-
-			return res, failure.Forbidden("only staff and admin can create cash payments")
-		}
-
 		transactionID := "cash-" + booking.String()
 
 		id, err := s.paymentService.CreatePayments(ctx, paymentDto.CreatePaymentRequest{
-			BookingID:     booking.String(),
-			PaymentMethod: constant.PaymentCashMethod,
-			TransactionID: transactionID,
-			Amount:        totalPrice,
+			BookingID:      booking.String(),
+			PaymentMethod:  constant.PaymentCashMethod,
+			PaymentChannel: constant.PaymentChannelCash,
+			TransactionID:  transactionID,
+			Amount:         totalPrice,
+			Description:    description,
+			Items:          items,
+			Fees:           fees,
+			TaxAmount:      taxAmount,
 		})
 		if err != nil {
 			s.logger.Error(identifier, "error creating cash payment: "+err.Error())
@@ -191,14 +297,24 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 			OrderID:    booking.String(),
 			Amount:     totalPrice,
 			Status:     constant.PaymentStatusPaid,
+			Channel:    constant.PaymentChannelCash,
 			ExpiryDate: nil,
 			PaymentURL: nil,
 		}
 	} else {
 		res, err = s.paymentService.CreateInvoice(ctx, paymentDto.CreatePaymentInvoice{
-			OrderID:    booking.String(),
-			Amount:     totalPrice,
-			PayerEmail: email,
+			OrderID:      booking.String(),
+			Amount:       totalPrice,
+			PayerEmail:   email,
+			Description:  description,
+			Items:        items,
+			Fees:         fees,
+			TaxRate:      taxRate,
+			TaxInclusive: location.TaxInclusive,
+			TaxAmount:    taxAmount,
+			Channel:      req.PaymentChannel,
+			ChannelCode:  req.ChannelCode,
+			MobileNumber: req.MobileNumber,
 		})
 		if err != nil {
 			s.logger.Error(identifier, "error creating payment invoice: "+err.Error())
@@ -210,11 +326,11 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetBookingsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetBookingsKey, "*")); err != nil {
 			s.logger.Error(identifier, "error clearing bookings cache: "+err.Error())
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountBookingsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountBookingsKey, "*")); err != nil {
 			s.logger.Error(identifier, "error clearing bookings cache: "+err.Error())
 		}
 	}()
@@ -222,33 +338,196 @@ func (s *bookingService) CreateBooking(ctx context.Context, req dto.CreateBookin
 	return res, nil
 }
 
-func (s *bookingService) GetBookingByID(ctx context.Context, id string) (res dto.BookingResponse, err error) {
-	bookingID := helper.PgUUID(id)
+// bookingCustomer is who a booking belongs to: an account, or a guest known only by contact details.
+type bookingCustomer struct {
+	UserID     pgtype.UUID
+	GuestName  pgtype.Text
+	GuestPhone pgtype.Text
+	GuestEmail pgtype.Text
+}
+
+// bookingCustomer resolves the customer staff book for, defaulting to the caller. A guest whose email
+// belongs to a verified account is booked under that account, other guests are attached to the account
+// they verify with the same email later.
+func (s *bookingService) bookingCustomer(ctx context.Context, db repository.DBTX, req dto.CreateBookingRequest, userID string) (bookingCustomer, error) {
+	switch {
+	case req.CustomerID != "":
+		user, err := s.userRepo.GetUserByID(ctx, db, helper.PgUUID(req.CustomerID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Error(identifier, "customer not found: "+req.CustomerID)
+
+				return bookingCustomer{}, failure.NotFound("customer not found")
+			}
+
+			s.logger.Error(identifier, "error getting customer: "+err.Error())
+
+			return bookingCustomer{}, err
+		}
+
+		return bookingCustomer{UserID: user.ID}, nil
+	case req.Guest != nil:
+		if req.Guest.Email != "" {
+			user, err := s.userRepo.GetUserByEmail(ctx, db, req.Guest.Email)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				s.logger.Error(identifier, "error getting guest by email: "+err.Error())
+
+				return bookingCustomer{}, err
+			}
+
+			if err == nil && user.IsVerified.Bool {
+				return bookingCustomer{UserID: user.ID}, nil
+			}
+		}
+
+		return bookingCustomer{
+			GuestName:  helper.PgString(req.Guest.Name),
+			GuestPhone: pgtype.Text{String: req.Guest.Phone, Valid: req.Guest.Phone != ""},
+			GuestEmail: pgtype.Text{String: req.Guest.Email, Valid: req.Guest.Email != ""},
+		}, nil
+	default:
+		return bookingCustomer{UserID: helper.PgUUID(userID)}, nil
+	}
+}
+
+// bookingFees returns the fees configured on the field's location. The convenience fee only
+// applies to online payments, cash bookings at the counter pay the service fee alone.
+func bookingFees(location locationRepo.Location, cash bool) ([]paymentDto.InvoiceFee, error) {
+	currency := money.ParseCurrency(location.Currency)
+
+	serviceFee, err := money.FromPg(location.ServiceFee, currency)
+	if err != nil {
+		return nil, fmt.Errorf("service fee: %w", err)
+	}
 
-	booking, err := s.repo.GetBookingById(ctx, s.db, bookingID)
+	convenienceFee, err := money.FromPg(location.ConvenienceFee, currency)
+	if err != nil {
+		return nil, fmt.Errorf("convenience fee: %w", err)
+	}
+
+	var fees []paymentDto.InvoiceFee
+
+	if serviceFee.IsPositive() {
+		fees = append(fees, paymentDto.InvoiceFee{Type: constant.PaymentFeeService, Value: serviceFee})
+	}
+
+	if convenienceFee.IsPositive() && !cash {
+		fees = append(fees, paymentDto.InvoiceFee{Type: constant.PaymentFeeConvenience, Value: convenienceFee})
+	}
+
+	return fees, nil
+}
+
+// GetBookingByID returns a booking to its owner, or to staff who may read bookings at its location.
+func (s *bookingService) GetBookingByID(ctx context.Context, id, userID string, permissions []string) (res dto.BookingResponse, err error) {
+	booking, err := s.getReadableBooking(ctx, "get", id, userID, permissions)
+	if err != nil {
+		return res, err
+	}
+
+	res = res.FromModel(booking)
+	res.FieldName = s.fieldName(ctx, booking.FieldID)
+
+	return res, nil
+}
+
+// ShareBooking issues a new share link token for the booking, replacing any earlier one. Only the
+// hash is stored, so the token is returned once.
+func (s *bookingService) ShareBooking(ctx context.Context, id, userID string, permissions []string) (res dto.BookingShareResponse, err error) {
+	if _, err = s.getReadableBooking(ctx, "share", id, userID, permissions); err != nil {
+		return res, err
+	}
+
+	token, err := helper.GenerateRandomToken(shareTokenLength)
+	if err != nil {
+		s.logger.Error(identifier, "share - failed to generate token: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = s.setShareTokenHash(ctx, "share", id, helper.PgString(helper.HashToken(token))); err != nil {
+		return res, err
+	}
+
+	return dto.BookingShareResponse{Token: token}, nil
+}
+
+// UnshareBooking revokes the share link of the booking.
+func (s *bookingService) UnshareBooking(ctx context.Context, id, userID string, permissions []string) (err error) {
+	if _, err = s.getReadableBooking(ctx, "unshare", id, userID, permissions); err != nil {
+		return err
+	}
+
+	return s.setShareTokenHash(ctx, "unshare", id, pgtype.Text{})
+}
+
+// GetSharedBooking returns the redacted booking behind a share link.
+func (s *bookingService) GetSharedBooking(ctx context.Context, token string) (res dto.SharedBookingResponse, err error) {
+	booking, err := s.repo.GetBookingByShareTokenHash(ctx, s.db, repository.GetBookingByShareTokenHashParams{
+		ShareTokenHash: helper.PgString(helper.HashToken(token)),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			s.logger.Error(identifier, "booking not found with ID: "+bookingID.String())
+			s.logger.Error(identifier, "shared - booking not found for share token")
 
 			return res, failure.NotFound("booking not found")
 		}
 
-		s.logger.Error(identifier, "error getting booking by ID: "+err.Error())
+		s.logger.Error(identifier, "shared - error getting booking by share token: %w", err)
 
-		return res, err
+		return res, failure.InternalError(err)
 	}
 
-	res = res.FromModel(booking)
+	return res.FromModel(booking, s.fieldName(ctx, booking.FieldID)), nil
+}
 
-	// Get field name
-	field, err := s.fieldRepo.GetFieldById(ctx, s.db, booking.FieldID)
-	if err == nil {
-		res.FieldName = field.Name
-	} else {
-		s.logger.Error(identifier, "error getting field name for ID %s: %w", booking.FieldID.String(), err)
+func (s *bookingService) setShareTokenHash(ctx context.Context, op, id string, hash pgtype.Text) error {
+	updated, err := s.repo.SetBookingShareTokenHash(ctx, s.db, repository.SetBookingShareTokenHashParams{
+		ID:             helper.PgUUID(id),
+		ShareTokenHash: hash,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, op+" - error setting share token: %w", err)
+
+		return failure.InternalError(err)
 	}
 
-	return res, nil
+	if updated == 0 {
+		return failure.NotFound("booking not found")
+	}
+
+	return nil
+}
+
+// fieldName looks up the name of the booked field. Responses are still served without it when the
+// lookup fails.
+func (s *bookingService) fieldName(ctx context.Context, fieldID pgtype.UUID) string {
+	field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepo.GetFieldByIdParams{
+		ID:             fieldID,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "error getting field name for ID %s: %w", fieldID.String(), err)
+
+		return ""
+	}
+
+	return field.Name
+}
+
+func (s *bookingService) GetBookingReceipt(ctx context.Context, id, userID string, permissions []string) (res []byte, err error) {
+	booking, err := s.getReadableBooking(ctx, "receipt", id, userID, permissions)
+	if err != nil {
+		return res, err
+	}
+
+	if booking.Status != constant.BookingStatusPaid && booking.Status != constant.BookingStatusConfirmed {
+		return res, failure.BadRequestFromString("receipt is only available for paid bookings")
+	}
+
+	return s.paymentService.GetReceipt(ctx, id)
 }
 
 func (s *bookingService) GetUserBookings(ctx context.Context, userID string, req gdto.PaginationRequest) (res dto.GetBookingsResponse, err error) {
@@ -258,7 +537,8 @@ func (s *bookingService) GetUserBookings(ctx context.Context, userID string, req
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheGetBookingsKey, helper.GenerateUniqueKey(keyArgs))
+	keyArgs["user_id"] = userID
+	cacheKey := tenant.CacheKey(ctx, cacheGetBookingsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.GetBookingsResponse
 
@@ -281,10 +561,11 @@ func (s *bookingService) GetUserBookings(ctx context.Context, userID string, req
 	offset := helper.CalculateOffset(page, limit)
 
 	bookings, err := s.repo.GetBookingsByUserId(ctx, s.db, repository.GetBookingsByUserIdParams{
-		UserID:  helper.PgUUID(userID),
-		Column2: req.Filter,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		UserID:         helper.PgUUID(userID),
+		Column2:        req.Filter,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "get user bookings - error getting bookings by user ID: %w", err)
@@ -307,7 +588,10 @@ func (s *bookingService) GetUserBookings(ctx context.Context, userID string, req
 	fieldNames := make(map[string]string)
 
 	for fieldID := range fieldIDs {
-		field, err := s.fieldRepo.GetFieldById(ctx, s.db, helper.PgUUID(fieldID))
+		field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepo.GetFieldByIdParams{
+			ID:             helper.PgUUID(fieldID),
+			OrganizationID: tenant.FromContext(ctx),
+		})
 		if err == nil {
 			fieldNames[fieldID] = field.Name
 		} else {
@@ -334,7 +618,8 @@ func (s *bookingService) CountUserBookings(ctx context.Context, userID string, r
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheCountBookingsKey, helper.GenerateUniqueKey(keyArgs))
+	keyArgs["user_id"] = userID
+	cacheKey := tenant.CacheKey(ctx, cacheCountBookingsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes int
 
@@ -346,8 +631,9 @@ func (s *bookingService) CountUserBookings(ctx context.Context, userID string, r
 	}
 
 	totalItems, err := s.repo.CountBookingsByUserId(ctx, s.db, repository.CountBookingsByUserIdParams{
-		UserID:  helper.PgUUID(userID),
-		Column2: req.Filter,
+		UserID:         helper.PgUUID(userID),
+		Column2:        req.Filter,
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "count - error counting user bookings: %s", err.Error())
@@ -366,14 +652,27 @@ func (s *bookingService) CountUserBookings(ctx context.Context, userID string, r
 	return total, nil
 }
 
-func (s *bookingService) GetAllBookings(ctx context.Context, req gdto.PaginationRequest) (res dto.GetBookingsResponse, err error) {
+// GetAllBookings lists the bookings of every user. Staff only see the bookings at the locations
+// they are assigned to.
+func (s *bookingService) GetAllBookings(ctx context.Context, req gdto.PaginationRequest, userID string, permissions []string) (res dto.GetBookingsResponse, err error) {
+	scope, err := locationService.ResolveStaffScope(ctx, s.db, s.locationRepo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, "get all bookings - error resolving staff scope: %s", err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
+	if !scope.All {
+		return s.getLocationBookings(ctx, req, scope.LocationIDs)
+	}
+
 	page, limit := helper.DefaultPagination(req.Page, req.Limit)
 
 	keyArgs := map[string]string{}
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheGetBookingsKey, "all:"+helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheGetBookingsKey, "all:"+helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.GetBookingsResponse
 
@@ -394,9 +693,10 @@ func (s *bookingService) GetAllBookings(ctx context.Context, req gdto.Pagination
 	offset := helper.CalculateOffset(page, limit)
 
 	bookings, err := s.repo.GetAllBookings(ctx, s.db, repository.GetAllBookingsParams{
-		Column1: req.Filter,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		Column1:        req.Filter,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "get all bookings - error getting all bookings: %w", err)
@@ -419,7 +719,10 @@ func (s *bookingService) GetAllBookings(ctx context.Context, req gdto.Pagination
 	fieldNames := make(map[string]string)
 
 	for fieldID := range fieldIDs {
-		field, err := s.fieldRepo.GetFieldById(ctx, s.db, helper.PgUUID(fieldID))
+		field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepo.GetFieldByIdParams{
+			ID:             helper.PgUUID(fieldID),
+			OrganizationID: tenant.FromContext(ctx),
+		})
 		if err == nil {
 			fieldNames[fieldID] = field.Name
 		} else {
@@ -439,6 +742,73 @@ func (s *bookingService) GetAllBookings(ctx context.Context, req gdto.Pagination
 	return res, nil
 }
 
+// getLocationBookings lists the bookings for fields at the given locations. The result is not
+// cached, so a staff member loses access as soon as they are unassigned.
+func (s *bookingService) getLocationBookings(ctx context.Context, req gdto.PaginationRequest, locationIDs []pgtype.UUID) (res dto.GetBookingsResponse, err error) {
+	page, limit := helper.DefaultPagination(req.Page, req.Limit)
+
+	fieldIDs, err := s.fieldRepo.GetFieldIDsByLocationIDs(ctx, s.db, fieldRepo.GetFieldIDsByLocationIDsParams{
+		LocationIds:    locationIDs,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get location bookings - error getting fields of locations: %s", err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
+	totalItems, err := s.repo.CountBookingsByFieldIDs(ctx, s.db, repository.CountBookingsByFieldIDsParams{
+		FieldIds:       fieldIDs,
+		Filter:         req.Filter,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get location bookings - error counting bookings: %s", err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
+	bookings, err := s.repo.GetBookingsByFieldIDs(ctx, s.db, repository.GetBookingsByFieldIDsParams{
+		FieldIds:       fieldIDs,
+		Filter:         req.Filter,
+		LimitCount:     int32(limit),
+		OffsetCount:    int32(helper.CalculateOffset(page, limit)),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get location bookings - error getting bookings: %s", err.Error())
+
+		return res, failure.InternalError(err)
+	}
+
+	res.FromModel(bookings, int(totalItems), limit)
+
+	fieldNames := make(map[string]string)
+
+	for _, booking := range bookings {
+		fieldID := booking.FieldID.String()
+		if _, ok := fieldNames[fieldID]; ok {
+			continue
+		}
+
+		field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepo.GetFieldByIdParams{
+			ID:             booking.FieldID,
+			OrganizationID: tenant.FromContext(ctx),
+		})
+		if err != nil {
+			s.logger.Error(identifier, "get location bookings - error getting field name for ID %s: %w", fieldID, err)
+
+			continue
+		}
+
+		fieldNames[fieldID] = field.Name
+	}
+
+	res.EnrichWithFieldNames(fieldNames)
+
+	return res, nil
+}
+
 func (s *bookingService) CountAllBookings(ctx context.Context, req gdto.PaginationRequest) (total int, err error) {
 	page, limit := helper.DefaultPagination(req.Page, req.Limit)
 
@@ -446,7 +816,7 @@ func (s *bookingService) CountAllBookings(ctx context.Context, req gdto.Paginati
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheCountBookingsKey, "all:"+helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheCountBookingsKey, "all:"+helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes int
 
@@ -457,7 +827,10 @@ func (s *bookingService) CountAllBookings(ctx context.Context, req gdto.Paginati
 		return cacheRes, nil
 	}
 
-	totalItems, err := s.repo.CountAllBookings(ctx, s.db, req.Filter)
+	totalItems, err := s.repo.CountAllBookings(ctx, s.db, repository.CountAllBookingsParams{
+		Column1:        req.Filter,
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		s.logger.Error(identifier, "count all bookings - error counting all bookings: %s", err.Error())
 
@@ -481,7 +854,7 @@ func (s *bookingService) GetBookedSlots(ctx context.Context, req dto.GetBookedSl
 	keyArgs := map[string]string{}
 	keyArgs["field_id"] = fieldID.String()
 	keyArgs["date"] = req.Date
-	cacheKey := helper.BuildCacheKey(cacheGetBookingsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheGetBookingsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.GetBookedSlotsResponse
 
@@ -493,8 +866,9 @@ func (s *bookingService) GetBookedSlots(ctx context.Context, req dto.GetBookedSl
 	}
 
 	slots, err := s.repo.GetBookedTimeSlots(ctx, s.db, repository.GetBookedTimeSlotsParams{
-		FieldID:     fieldID,
-		BookingDate: helper.PgDate(req.Date),
+		FieldID:        fieldID,
+		BookingDate:    helper.PgDate(req.Date),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "get booked slots - error getting booked time slots: %s", err.Error())
@@ -514,32 +888,152 @@ func (s *bookingService) GetBookedSlots(ctx context.Context, req dto.GetBookedSl
 }
 
 func (s *bookingService) CancelUserBooking(ctx context.Context, req dto.CancelUserBookingRequest) (err error) {
-	err = s.repo.CancelBooking(ctx, s.db, repository.CancelBookingParams{
-		ID:         helper.PgUUID(req.BookingID),
-		UserID:     helper.PgUUID(req.UserID),
-		CanceledBy: helper.PgString(constant.BookingCanceledByUser),
-	})
-	if err != nil {
-		s.logger.Error(identifier, "cancel user booking - error canceling booking: %s", err.Error())
+	if slices.Contains(req.Permissions, constant.PermissionBookingsCancelAny) {
+		if err = s.cancelAnyBooking(ctx, req); err != nil {
+			return err
+		}
+	} else {
+		err = s.repo.CancelBooking(ctx, s.db, repository.CancelBookingParams{
+			ID:             helper.PgUUID(req.BookingID),
+			UserID:         helper.PgUUID(req.UserID),
+			CanceledBy:     helper.PgString(constant.BookingCanceledByUser),
+			OrganizationID: tenant.FromContext(ctx),
+		})
+		if err != nil {
+			s.logger.Error(identifier, "cancel user booking - error canceling booking: %s", err.Error())
 
-		return failure.InternalError(err)
+			return failure.InternalError(err)
+		}
 	}
 
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheGetBookingKey, req.BookingID)); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheGetBookingKey, req.BookingID)); err != nil {
 			s.logger.Error(identifier, "cancel user booking - error deleting booking from cache: %s", err.Error())
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetBookingsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetBookingsKey, "*")); err != nil {
 			s.logger.Error(identifier, "cancel user booking - error clearing bookings cache: %s", err.Error())
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountBookingsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountBookingsKey, "*")); err != nil {
 			s.logger.Error(identifier, "cancel user booking - error clearing bookings count cache: %s", err.Error())
 		}
 	}()
 
 	return nil
 }
+
+// cancelAnyBooking cancels a booking regardless of who made it, recording whether its owner or staff did.
+func (s *bookingService) cancelAnyBooking(ctx context.Context, req dto.CancelUserBookingRequest) error {
+	booking, err := s.repo.GetBookingById(ctx, s.db, repository.GetBookingByIdParams{
+		ID:             helper.PgUUID(req.BookingID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, "cancel user booking - booking not found with ID: "+req.BookingID)
+
+			return failure.NotFound("booking not found")
+		}
+
+		s.logger.Error(identifier, "cancel user booking - error getting booking: %s", err.Error())
+
+		return failure.InternalError(err)
+	}
+
+	canceledBy := constant.BookingCanceledByAdmin
+	if booking.UserID.String() == req.UserID {
+		canceledBy = constant.BookingCanceledByUser
+	} else if err = s.authorizeBooking(ctx, "cancel user booking", booking, req.UserID, req.Permissions); err != nil {
+		return err
+	}
+
+	err = s.repo.CancelAnyBooking(ctx, s.db, repository.CancelAnyBookingParams{
+		ID:             booking.ID,
+		CanceledBy:     helper.PgString(canceledBy),
+		OrganizationID: booking.OrganizationID,
+	})
+	if err != nil {
+		s.logger.Error(identifier, "cancel user booking - error canceling booking: %s", err.Error())
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+// getReadableBooking loads a booking the user may read: their own, or any at a location they are
+// assigned to when they hold bookings:read_any.
+func (s *bookingService) getReadableBooking(ctx context.Context, op, id, userID string, permissions []string) (repository.Booking, error) {
+	booking, err := s.repo.GetBookingById(ctx, s.db, repository.GetBookingByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, op+" - booking not found with ID: "+id)
+
+			return booking, failure.NotFound("booking not found")
+		}
+
+		s.logger.Error(identifier, op+" - error getting booking by ID: "+err.Error())
+
+		return booking, failure.InternalError(err)
+	}
+
+	if booking.UserID.String() == userID {
+		return booking, nil
+	}
+
+	if !slices.Contains(permissions, constant.PermissionBookingsReadAny) {
+		s.logger.Error(identifier, op+" - unauthorized access to booking %s by user %s", id, userID)
+
+		return booking, failure.Forbidden("you are not allowed to access this booking")
+	}
+
+	if err = s.authorizeBooking(ctx, op, booking, userID, permissions); err != nil {
+		return booking, err
+	}
+
+	return booking, nil
+}
+
+// authorizeBooking rejects staff handling a booking at a location they are not assigned to.
+func (s *bookingService) authorizeBooking(ctx context.Context, op string, booking repository.Booking, userID string, permissions []string) error {
+	field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepo.GetFieldByIdParams{
+		ID:             booking.FieldID,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, op+" - field not found with ID: "+booking.FieldID.String())
+
+			return failure.NotFound("field not found")
+		}
+
+		s.logger.Error(identifier, op+" - error getting field: %s", err.Error())
+
+		return failure.InternalError(err)
+	}
+
+	return s.authorizeLocation(ctx, s.db, op, field.LocationID, userID, permissions)
+}
+
+func (s *bookingService) authorizeLocation(ctx context.Context, db repository.DBTX, op string, locationID pgtype.UUID, userID string, permissions []string) error {
+	scope, err := locationService.ResolveStaffScope(ctx, db, s.locationRepo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, op+" - error resolving staff scope: %s", err.Error())
+
+		return failure.InternalError(err)
+	}
+
+	if !scope.Allows(locationID) {
+		s.logger.Error(identifier, op+" - user %s is not assigned to location %s", userID, locationID.String())
+
+		return failure.Forbidden("you are not assigned to the location of this booking")
+	}
+
+	return nil
+}
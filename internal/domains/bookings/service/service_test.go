@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/domains/bookings/dto"
+	"github.com/savioruz/goth/internal/domains/bookings/mock"
+	"github.com/savioruz/goth/internal/domains/bookings/repository"
+	fieldMock "github.com/savioruz/goth/internal/domains/fields/mock"
+	fieldRepo "github.com/savioruz/goth/internal/domains/fields/repository"
+	locationMock "github.com/savioruz/goth/internal/domains/locations/mock"
+	userMock "github.com/savioruz/goth/internal/domains/user/mock"
+	userRepo "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/gdto"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	redisMock "github.com/savioruz/goth/pkg/redis/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type testDeps struct {
+	querier  *mock.MockQuerier
+	fields   *fieldMock.MockQuerier
+	location *locationMock.MockQuerier
+	users    *userMock.MockQuerier
+	cache    *redisMock.MockIRedisCache
+}
+
+func setup(t *testing.T) (*bookingService, testDeps) {
+	ctrl := gomock.NewController(t)
+
+	mockPgx, _ := pgxmock.NewPool()
+	deps := testDeps{
+		querier:  mock.NewMockQuerier(ctrl),
+		fields:   fieldMock.NewMockQuerier(ctrl),
+		location: locationMock.NewMockQuerier(ctrl),
+		users:    userMock.NewMockQuerier(ctrl),
+		cache:    redisMock.NewMockIRedisCache(ctrl),
+	}
+
+	mockLogger := log.NewMockInterface(ctrl)
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Debug(gomock.Any()).AnyTimes()
+
+	service := New(mockPgx, deps.querier, deps.fields, deps.location, deps.users, nil, deps.cache, &config.Config{}, mockLogger)
+
+	return service.(*bookingService), deps
+}
+
+func TestBookingService_getReadableBooking(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New().String()
+	staffID := uuid.New().String()
+	bookingID := uuid.New().String()
+	fieldID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	locationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	booking := repository.Booking{ID: pgtype.UUID{Bytes: uuid.MustParse(bookingID), Valid: true}, UserID: pgtype.UUID{Bytes: uuid.MustParse(ownerID), Valid: true}, FieldID: fieldID}
+	staff := []string{constant.PermissionBookingsReadAny}
+
+	expectStaffLocations := func(deps testDeps, locationIDs ...pgtype.UUID) {
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+		deps.fields.EXPECT().GetFieldById(gomock.Any(), gomock.Any(), gomock.Any()).Return(fieldRepo.Field{ID: fieldID, LocationID: locationID}, nil)
+		deps.location.EXPECT().GetStaffLocationIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(locationIDs, nil)
+	}
+
+	t.Run("success: owner", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+
+		res, err := service.getReadableBooking(ctx, "get", bookingID, ownerID, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, booking, res)
+	})
+
+	t.Run("error: not the owner", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+
+		_, err := service.getReadableBooking(ctx, "get", bookingID, uuid.New().String(), nil)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("success: staff assigned to the location", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectStaffLocations(deps, locationID)
+
+		_, err := service.getReadableBooking(ctx, "get", bookingID, staffID, staff)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error: staff of another location", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectStaffLocations(deps, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+
+		_, err := service.getReadableBooking(ctx, "get", bookingID, staffID, staff)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+		assert.Equal(t, "you are not assigned to the location of this booking", err.Error())
+	})
+
+	t.Run("error: booking not found", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.Booking{}, pgx.ErrNoRows)
+
+		_, err := service.getReadableBooking(ctx, "get", bookingID, ownerID, nil)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
+
+func TestBookingService_ShareBooking(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New().String()
+	bookingID := uuid.New().String()
+	booking := repository.Booking{
+		ID:     pgtype.UUID{Bytes: uuid.MustParse(bookingID), Valid: true},
+		UserID: pgtype.UUID{Bytes: uuid.MustParse(ownerID), Valid: true},
+		Status: constant.BookingStatusPaid,
+	}
+
+	t.Run("error: not the owner", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+
+		res, err := service.ShareBooking(ctx, bookingID, uuid.New().String(), nil)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+		assert.Empty(t, res.Token)
+	})
+
+	t.Run("success: link stops working once unshared", func(t *testing.T) {
+		service, deps := setup(t)
+
+		// The stored hash stands in for the share_token_hash column
+		var stored pgtype.Text
+
+		deps.querier.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil).Times(2)
+		deps.querier.EXPECT().
+			SetBookingShareTokenHash(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.SetBookingShareTokenHashParams) (int64, error) {
+				assert.Equal(t, booking.ID, arg.ID)
+				stored = arg.ShareTokenHash
+
+				return 1, nil
+			}).
+			Times(2)
+		deps.querier.EXPECT().
+			GetBookingByShareTokenHash(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.GetBookingByShareTokenHashParams) (repository.Booking, error) {
+				if !stored.Valid || stored != arg.ShareTokenHash {
+					return repository.Booking{}, pgx.ErrNoRows
+				}
+
+				return booking, nil
+			}).
+			Times(3)
+		deps.fields.EXPECT().GetFieldById(gomock.Any(), gomock.Any(), gomock.Any()).Return(fieldRepo.Field{Name: "Court 1"}, nil)
+
+		share, err := service.ShareBooking(ctx, bookingID, ownerID, nil)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, share.Token)
+		assert.NotEqual(t, share.Token, stored.String, "only the hash of the token is stored")
+
+		shared, err := service.GetSharedBooking(ctx, share.Token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Court 1", shared.FieldName)
+		assert.Equal(t, constant.BookingStatusPaid, shared.Status)
+
+		_, err = service.GetSharedBooking(ctx, "another-token")
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+
+		err = service.UnshareBooking(ctx, bookingID, ownerID, nil)
+
+		assert.NoError(t, err)
+
+		_, err = service.GetSharedBooking(ctx, share.Token)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
+
+func TestBookingService_GetUserBookings(t *testing.T) {
+	ctx := context.Background()
+	req := gdto.PaginationRequest{Page: 1, Limit: 10}
+
+	t.Run("success: users do not share cached lists", func(t *testing.T) {
+		service, deps := setup(t)
+
+		var mu sync.Mutex
+
+		cached := map[string]any{}
+		saved := make(chan struct{}, 4)
+
+		deps.cache.EXPECT().
+			Get(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, key string, value any) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				stored, ok := cached[key]
+				if !ok {
+					return goredis.Nil
+				}
+
+				reflect.ValueOf(value).Elem().Set(reflect.ValueOf(stored))
+
+				return nil
+			}).
+			AnyTimes()
+		deps.cache.EXPECT().
+			Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, key string, value any, _ int) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				cached[key] = value
+				saved <- struct{}{}
+
+				return nil
+			}).
+			AnyTimes()
+		deps.querier.EXPECT().CountBookingsByUserId(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(2)
+		deps.querier.EXPECT().
+			GetBookingsByUserId(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.GetBookingsByUserIdParams) ([]repository.Booking, error) {
+				return []repository.Booking{{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, UserID: arg.UserID}}, nil
+			}).
+			Times(2)
+		deps.fields.EXPECT().GetFieldById(gomock.Any(), gomock.Any(), gomock.Any()).Return(fieldRepo.Field{}, nil).AnyTimes()
+
+		first, err := service.GetUserBookings(ctx, uuid.New().String(), req)
+
+		assert.NoError(t, err)
+
+		// The list and its count are cached in the background
+		<-saved
+		<-saved
+
+		second, err := service.GetUserBookings(ctx, uuid.New().String(), req)
+
+		assert.NoError(t, err)
+		assert.Len(t, first.Bookings, 1)
+		assert.Len(t, second.Bookings, 1)
+		assert.NotEqual(t, first.Bookings[0].ID, second.Bookings[0].ID)
+	})
+}
+
+func TestBookingService_CreateBooking(t *testing.T) {
+	ctx := context.Background()
+	online := false
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(constant.DateFormat)
+
+	t.Run("error: booking for a guest must be paid in cash", func(t *testing.T) {
+		service, _ := setup(t)
+
+		req := dto.CreateBookingRequest{
+			FieldID:   uuid.New(),
+			Date:      tomorrow,
+			StartTime: "10:00",
+			Duration:  1,
+			Cash:      &online,
+			Guest:     &dto.GuestRequest{Name: "Guest", Phone: "+628123456789"},
+		}
+
+		_, err := service.CreateBooking(ctx, req, uuid.New().String(), "staff@gmail.com", nil)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		assert.Equal(t, "only cash bookings can be made for a customer or guest", err.Error())
+	})
+
+	t.Run("error: booking for a customer must be paid in cash", func(t *testing.T) {
+		service, _ := setup(t)
+
+		req := dto.CreateBookingRequest{
+			FieldID:    uuid.New(),
+			Date:       tomorrow,
+			StartTime:  "10:00",
+			Duration:   1,
+			Cash:       &online,
+			CustomerID: uuid.New().String(),
+		}
+
+		_, err := service.CreateBooking(ctx, req, uuid.New().String(), "staff@gmail.com", nil)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
+
+func TestBookingService_bookingCustomer(t *testing.T) {
+	ctx := context.Background()
+	staffID := uuid.New().String()
+	accountID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	guest := &dto.GuestRequest{Name: "Guest", Email: "guest@gmail.com"}
+
+	t.Run("success: defaults to the caller", func(t *testing.T) {
+		service, _ := setup(t)
+
+		res, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{}, staffID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, staffID, res.UserID.String())
+	})
+
+	t.Run("success: customer account", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.users.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), accountID).Return(userRepo.User{ID: accountID}, nil)
+
+		res, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{CustomerID: accountID.String()}, staffID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, accountID, res.UserID)
+		assert.False(t, res.GuestName.Valid)
+	})
+
+	t.Run("error: unknown customer", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.users.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), accountID).Return(userRepo.User{}, pgx.ErrNoRows)
+
+		_, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{CustomerID: accountID.String()}, staffID)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+		assert.Equal(t, "customer not found", err.Error())
+	})
+
+	t.Run("success: guest email of a verified account books under the account", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.users.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any(), guest.Email).
+			Return(userRepo.User{ID: accountID, IsVerified: pgtype.Bool{Bool: true, Valid: true}}, nil)
+
+		res, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{Guest: guest}, staffID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, accountID, res.UserID)
+		assert.False(t, res.GuestEmail.Valid)
+	})
+
+	t.Run("success: guest email of an unverified account stays a guest", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.users.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any(), guest.Email).
+			Return(userRepo.User{ID: accountID, IsVerified: pgtype.Bool{Bool: false, Valid: true}}, nil)
+
+		res, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{Guest: guest}, staffID)
+
+		assert.NoError(t, err)
+		assert.False(t, res.UserID.Valid)
+		assert.Equal(t, guest.Name, res.GuestName.String)
+		assert.Equal(t, guest.Email, res.GuestEmail.String)
+		assert.False(t, res.GuestPhone.Valid)
+	})
+
+	t.Run("success: guest without an account", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.users.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any(), guest.Email).Return(userRepo.User{}, pgx.ErrNoRows)
+
+		res, err := service.bookingCustomer(ctx, nil, dto.CreateBookingRequest{Guest: guest}, staffID)
+
+		assert.NoError(t, err)
+		assert.False(t, res.UserID.Valid)
+		assert.Equal(t, guest.Email, res.GuestEmail.String)
+	})
+}
@@ -1,21 +1,24 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"github.com/savioruz/goth/pkg/money"
+)
 
 type FieldCreateRequest struct {
-	LocationID  uuid.UUID `json:"location_id" validate:"required,uuid"`
-	Name        string    `json:"name" validate:"required,min=5,max=255"`
-	Type        string    `json:"type" validate:"required,min=5,max=100"`
-	Price       int64     `json:"price" validate:"numeric,required,min=5000"`
-	Description string    `json:"description" validate:"omitempty"`
-	Images      []string  `json:"images" validate:"omitempty,dive,url"`
+	LocationID  uuid.UUID   `json:"location_id" validate:"required,uuid"`
+	Name        string      `json:"name" validate:"required,min=5,max=255"`
+	Type        string      `json:"type" validate:"required,min=5,max=100"`
+	Price       money.Money `json:"price" validate:"required"`
+	Description string      `json:"description" validate:"omitempty"`
+	Images      []string    `json:"images" validate:"omitempty,dive,url"`
 }
 
 type FieldUpdateRequest struct {
-	LocationID  uuid.UUID `json:"location_id" validate:"omitempty,uuid"`
-	Name        string    `json:"name" validate:"omitempty,min=5,max=255"`
-	Type        string    `json:"type" validate:"omitempty,min=5,max=100"`
-	Price       int64     `json:"price" validate:"omitempty,numeric,min=5000"`
-	Description string    `json:"description" validate:"omitempty"`
-	Images      []string  `json:"images" validate:"omitempty,dive,url"`
+	LocationID  uuid.UUID    `json:"location_id" validate:"omitempty,uuid"`
+	Name        string       `json:"name" validate:"omitempty,min=5,max=255"`
+	Type        string       `json:"type" validate:"omitempty,min=5,max=100"`
+	Price       *money.Money `json:"price" validate:"omitempty"`
+	Description string       `json:"description" validate:"omitempty"`
+	Images      []string     `json:"images" validate:"omitempty,dive,url"`
 }
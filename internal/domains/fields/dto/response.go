@@ -4,18 +4,19 @@ import (
 	"github.com/savioruz/goth/internal/domains/fields/repository"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
 )
 
 type FieldResponse struct {
-	ID          string   `json:"id"`
-	LocationID  string   `json:"location_id"`
-	Name        string   `json:"name"`
-	Type        string   `json:"type"`
-	Price       int64    `json:"price"`
-	Description string   `json:"description"`
-	Images      []string `json:"images"`
-	CreatedAt   string   `json:"created_at"`
-	UpdatedAt   string   `json:"updated_at"`
+	ID          string      `json:"id"`
+	LocationID  string      `json:"location_id"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Price       money.Money `json:"price"`
+	Description string      `json:"description"`
+	Images      []string    `json:"images"`
+	CreatedAt   string      `json:"created_at"`
+	UpdatedAt   string      `json:"updated_at"`
 }
 
 func (f FieldResponse) FromModel(model repository.Field) FieldResponse {
@@ -28,7 +29,7 @@ func (f FieldResponse) FromModel(model repository.Field) FieldResponse {
 		LocationID:  model.LocationID.String(),
 		Name:        model.Name,
 		Type:        model.Type,
-		Price:       helper.Int64FromPg(model.Price),
+		Price:       money.FromPgOrZero(model.Price, model.Currency),
 		Description: model.Description.String,
 		Images:      model.Images,
 		CreatedAt:   model.CreatedAt.Time.Format(constant.FullDateFormat),
@@ -36,24 +36,24 @@ const (
 	routePath = "/fields"
 )
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	fields := r.Group(routePath)
 
-	fields.Post("/", middleware.Jwt(), middleware.AdminOnly(), h.Create)
+	fields.Post("/", authn.Jwt(), middleware.RequirePermission(constant.PermissionFieldsWrite), h.Create)
 	fields.Get("/:id", h.Get)
 	fields.Get("/", h.GetAll)
-	fields.Patch("/:id", middleware.Jwt(), middleware.AdminOnly(), h.Update)
-	fields.Delete("/:id", middleware.Jwt(), middleware.AdminOnly(), h.Delete)
+	fields.Patch("/:id", authn.Jwt(), middleware.RequirePermission(constant.PermissionFieldsWrite), h.Update)
+	fields.Delete("/:id", authn.Jwt(), middleware.RequirePermission(constant.PermissionFieldsWrite), h.Delete)
 
 	// Image upload routes
-	fields.Post("/:id/images", middleware.Jwt(), middleware.AdminOnly(), h.UploadImages)
-	fields.Delete("/:id/images", middleware.Jwt(), middleware.AdminOnly(), h.DeleteImage)
+	fields.Post("/:id/images", authn.Jwt(), middleware.RequirePermission(constant.PermissionFieldsWrite), h.UploadImages)
+	fields.Delete("/:id/images", authn.Jwt(), middleware.RequirePermission(constant.PermissionFieldsWrite), h.DeleteImage)
 
 	r.Get("/locations/:location_id/fields", h.GetByLocationID)
 }
 
 // Create Field godoc
-// @Summary Create new field
+// @Summary Create new field (requires fields:write)
 // @Description Create new field
 // @Tags fields
 // @Accept json
@@ -61,6 +61,7 @@ func (h *Handler) RegisterRoutes(r fiber.Router) {
 // @Param field body dto.FieldCreateRequest true "Field create request"
 // @Success 201 {object} response.Data[string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /fields/ [post]
 // @Security BearerAuth
@@ -81,7 +82,14 @@ func (h *Handler) Create(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, transformErr)
 	}
 
-	data, err := h.service.Create(ctx.UserContext(), req)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "create - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.Create(ctx.UserContext(), req, userID, permissions)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
@@ -174,7 +182,7 @@ func (h *Handler) GetAll(ctx *fiber.Ctx) error {
 }
 
 // Update Field godoc
-// @Summary Update field by ID
+// @Summary Update field by ID (requires fields:write)
 // @Description Update field by ID
 // @Tags fields
 // @Accept json
@@ -183,6 +191,7 @@ func (h *Handler) GetAll(ctx *fiber.Ctx) error {
 // @Param field body dto.FieldUpdateRequest true "Field update request"
 // @Success 200 {object} response.Data[dto.FieldResponse]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /fields/{id} [patch]
@@ -211,7 +220,14 @@ func (h *Handler) Update(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, transformErr)
 	}
 
-	data, err := h.service.Update(ctx.UserContext(), id, req)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "update - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	data, err := h.service.Update(ctx.UserContext(), id, req, userID, permissions)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
@@ -227,7 +243,7 @@ func (h *Handler) Update(ctx *fiber.Ctx) error {
 }
 
 // Delete Field godoc
-// @Summary Delete field by ID
+// @Summary Delete field by ID (requires fields:write)
 // @Description Delete field by ID
 // @Tags fields
 // @Accept json
@@ -235,6 +251,7 @@ func (h *Handler) Update(ctx *fiber.Ctx) error {
 // @Param id path string true "Field ID"
 // @Success 200 {object} response.Message
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /fields/{id} [delete]
@@ -247,7 +264,14 @@ func (h *Handler) Delete(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, failure.BadRequestFromString("id is required"))
 	}
 
-	err := h.service.Delete(ctx.UserContext(), id)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "delete - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	err = h.service.Delete(ctx.UserContext(), id, userID, permissions)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
@@ -317,7 +341,7 @@ func (h *Handler) GetByLocationID(ctx *fiber.Ctx) error {
 }
 
 // UploadImages godoc
-// @Summary Upload images for field
+// @Summary Upload images for field (requires fields:write)
 // @Description Upload multiple images for a field
 // @Tags fields
 // @Accept multipart/form-data
@@ -326,6 +350,7 @@ func (h *Handler) GetByLocationID(ctx *fiber.Ctx) error {
 // @Param images formData file true "Images to upload"
 // @Success 200 {object} response.Data[[]string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /fields/{id}/images [post]
@@ -374,7 +399,14 @@ func (h *Handler) UploadImages(ctx *fiber.Ctx) error {
 		}
 	}
 
-	urls, err := h.service.UploadImages(ctx.UserContext(), fieldID, files)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "uploadImages - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	urls, err := h.service.UploadImages(ctx.UserContext(), fieldID, files, userID, permissions)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
@@ -390,7 +422,7 @@ func (h *Handler) UploadImages(ctx *fiber.Ctx) error {
 }
 
 // DeleteImage godoc
-// @Summary Delete image from field
+// @Summary Delete image from field (requires fields:write)
 // @Description Delete an image from a field
 // @Tags fields
 // @Accept json
@@ -399,6 +431,7 @@ func (h *Handler) UploadImages(ctx *fiber.Ctx) error {
 // @Param imageURL query string true "Image URL to delete"
 // @Success 200 {object} response.Data[string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Security BearerAuth
@@ -421,7 +454,14 @@ func (h *Handler) DeleteImage(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	err := h.service.DeleteImage(ctx.UserContext(), fieldID, imageURL)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "deleteImage - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	err = h.service.DeleteImage(ctx.UserContext(), fieldID, imageURL, userID, permissions)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
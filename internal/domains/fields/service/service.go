@@ -11,48 +11,59 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/savioruz/goth/config"
 	"github.com/savioruz/goth/internal/domains/fields/dto"
 	"github.com/savioruz/goth/internal/domains/fields/repository"
+	locationRepo "github.com/savioruz/goth/internal/domains/locations/repository"
+	locationService "github.com/savioruz/goth/internal/domains/locations/service"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/gdto"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/money"
 	"github.com/savioruz/goth/pkg/postgres"
 	"github.com/savioruz/goth/pkg/redis"
 	"github.com/savioruz/goth/pkg/supabase"
+	"github.com/savioruz/goth/pkg/tenant"
 )
 
 type FieldService interface {
-	Create(ctx context.Context, req dto.FieldCreateRequest) (string, error)
+	Create(ctx context.Context, req dto.FieldCreateRequest, userID string, permissions []string) (string, error)
 	Get(ctx context.Context, id string) (dto.FieldResponse, error)
 	GetAll(ctx context.Context, req gdto.PaginationRequest) (dto.GetFieldsResponse, error)
 	Count(ctx context.Context, req gdto.PaginationRequest) (int, error)
 	GetByLocationID(ctx context.Context, locationID string, req gdto.PaginationRequest) (dto.GetFieldsResponse, error)
 	CountByLocationID(ctx context.Context, locationID string, req gdto.PaginationRequest) (int, error)
-	Update(ctx context.Context, id string, req dto.FieldUpdateRequest) (string, error)
-	Delete(ctx context.Context, id string) error
-	UploadImages(ctx context.Context, fieldID string, files []*multipart.FileHeader) ([]string, error)
-	DeleteImage(ctx context.Context, fieldID, imageURL string) error
+	Update(ctx context.Context, id string, req dto.FieldUpdateRequest, userID string, permissions []string) (string, error)
+	Delete(ctx context.Context, id, userID string, permissions []string) error
+	UploadImages(ctx context.Context, fieldID string, files []*multipart.FileHeader, userID string, permissions []string) ([]string, error)
+	DeleteImage(ctx context.Context, fieldID, imageURL, userID string, permissions []string) error
 }
 
 type fieldService struct {
 	db            postgres.PgxIface
 	repo          repository.Querier
+	locationRepo  locationRepo.Querier
 	cache         redis.IRedisCache
 	cfg           *config.Config
 	logger        logger.Interface
 	storageClient *supabase.Client
+	auditor       audit.Recorder
 }
 
-func New(db postgres.PgxIface, repo repository.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface, storageClient *supabase.Client) FieldService {
+func New(db postgres.PgxIface, repo repository.Querier, lr locationRepo.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface, storageClient *supabase.Client, auditor audit.Recorder) FieldService {
 	return &fieldService{
 		db:            db,
 		repo:          repo,
+		locationRepo:  lr,
 		cache:         cache,
 		cfg:           cfg,
 		logger:        l,
 		storageClient: storageClient,
+		auditor:       auditor,
 	}
 }
 
@@ -68,14 +79,26 @@ const (
 	MaxFilesPerUpload = 10
 )
 
-func (s *fieldService) Create(ctx context.Context, req dto.FieldCreateRequest) (res string, err error) {
+func (s *fieldService) Create(ctx context.Context, req dto.FieldCreateRequest, userID string, permissions []string) (res string, err error) {
+	if err := validatePrice(req.Price); err != nil {
+		s.logger.Error(identifier, "create - invalid price: %w", err)
+
+		return res, err
+	}
+
+	if err := s.authorize(ctx, "create", helper.PgUUID(req.LocationID.String()), userID, permissions); err != nil {
+		return res, err
+	}
+
 	newField, err := s.repo.CreateField(ctx, s.db, repository.CreateFieldParams{
-		LocationID:  helper.PgUUID(req.LocationID.String()),
-		Name:        req.Name,
-		Type:        req.Type,
-		Price:       helper.PgInt64(req.Price),
-		Description: helper.PgString(req.Description),
-		Images:      req.Images,
+		LocationID:     helper.PgUUID(req.LocationID.String()),
+		Name:           req.Name,
+		Type:           req.Type,
+		Price:          req.Price.Pg(),
+		Description:    helper.PgString(req.Description),
+		Images:         req.Images,
+		Currency:       string(req.Price.Currency),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "create - failed to create field: %w", err)
@@ -85,14 +108,21 @@ func (s *fieldService) Create(ctx context.Context, req dto.FieldCreateRequest) (
 
 	res = newField.String()
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionFieldCreate,
+		EntityType: constant.AuditEntityField,
+		EntityID:   res,
+		After:      req,
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "create - failed to delete cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "create - failed to clear cache: %w", err)
 		}
 	}()
@@ -101,13 +131,16 @@ func (s *fieldService) Create(ctx context.Context, req dto.FieldCreateRequest) (
 }
 
 func (s *fieldService) Get(ctx context.Context, id string) (res dto.FieldResponse, err error) {
-	cacheKey := helper.BuildCacheKey(cacheGetFieldKey, id)
+	cacheKey := tenant.CacheKey(ctx, cacheGetFieldKey, id)
 
 	if err = s.cache.Get(ctx, cacheKey, &res); err == nil {
 		return res, nil
 	}
 
-	field, err := s.repo.GetFieldById(ctx, s.db, helper.PgUUID(id))
+	field, err := s.repo.GetFieldById(ctx, s.db, repository.GetFieldByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("field %s - not found", id))
@@ -140,7 +173,7 @@ func (s *fieldService) GetAll(ctx context.Context, req gdto.PaginationRequest) (
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheGetFieldsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheGetFieldsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.GetFieldsResponse
 
@@ -161,9 +194,10 @@ func (s *fieldService) GetAll(ctx context.Context, req gdto.PaginationRequest) (
 	offset := helper.CalculateOffset(page, limit)
 
 	fields, err := s.repo.GetFields(ctx, s.db, repository.GetFieldsParams{
-		Column1: req.Filter,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		Column1:        req.Filter,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "getAll - failed to get fields: %w", err)
@@ -192,7 +226,7 @@ func (s *fieldService) Count(ctx context.Context, req gdto.PaginationRequest) (t
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheCountFieldsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheCountFieldsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes int
 
@@ -203,7 +237,10 @@ func (s *fieldService) Count(ctx context.Context, req gdto.PaginationRequest) (t
 		return cacheRes, nil
 	}
 
-	totalItems, err := s.repo.CountFields(ctx, s.db, req.Filter)
+	totalItems, err := s.repo.CountFields(ctx, s.db, repository.CountFieldsParams{
+		Column1:        req.Filter,
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		s.logger.Error(identifier, "count - failed to count fields: %w", err)
 
@@ -231,7 +268,7 @@ func (s *fieldService) GetByLocationID(ctx context.Context, locationID string, r
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheGetFieldsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheGetFieldsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.GetFieldsResponse
 
@@ -252,10 +289,11 @@ func (s *fieldService) GetByLocationID(ctx context.Context, locationID string, r
 	offset := helper.CalculateOffset(page, limit)
 
 	fields, err := s.repo.GetFieldsByLocationID(ctx, s.db, repository.GetFieldsByLocationIDParams{
-		Column1:    req.Filter,
-		LocationID: helper.PgUUID(locationID),
-		Limit:      int32(limit),
-		Offset:     int32(offset),
+		Column1:        req.Filter,
+		LocationID:     helper.PgUUID(locationID),
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "getByLocationID - failed to get fields: %w", err)
@@ -282,7 +320,7 @@ func (s *fieldService) CountByLocationID(ctx context.Context, locationID string,
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheCountFieldsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheCountFieldsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes int
 
@@ -294,8 +332,9 @@ func (s *fieldService) CountByLocationID(ctx context.Context, locationID string,
 	}
 
 	totalItems, err := s.repo.CountFieldsByLocationID(ctx, s.db, repository.CountFieldsByLocationIDParams{
-		Column1:    req.Filter,
-		LocationID: helper.PgUUID(locationID),
+		Column1:        req.Filter,
+		LocationID:     helper.PgUUID(locationID),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "countByLocationID - failed to count fields: %w", err)
@@ -317,8 +356,11 @@ func (s *fieldService) CountByLocationID(ctx context.Context, locationID string,
 	return res, nil
 }
 
-func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdateRequest) (res string, err error) {
-	existingField, err := s.repo.GetFieldById(ctx, s.db, helper.PgUUID(id))
+func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdateRequest, userID string, permissions []string) (res string, err error) {
+	existingField, err := s.repo.GetFieldById(ctx, s.db, repository.GetFieldByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("fields %s - not found", id))
@@ -329,6 +371,12 @@ func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdat
 		return res, err
 	}
 
+	if err = s.authorize(ctx, "update", existingField.LocationID, userID, permissions); err != nil {
+		return res, err
+	}
+
+	before := dto.FieldResponse{}.FromModel(existingField)
+
 	val := reflect.ValueOf(req)
 	typ := reflect.TypeOf(req)
 
@@ -351,7 +399,15 @@ func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdat
 		case "type":
 			existingField.Type = field.Interface().(string)
 		case "price":
-			existingField.Price = helper.PgInt64(field.Int())
+			price := *field.Interface().(*money.Money)
+			if err := validatePrice(price); err != nil {
+				s.logger.Error(identifier, "update - invalid price: %w", err)
+
+				return res, err
+			}
+
+			existingField.Price = price.Pg()
+			existingField.Currency = string(price.Currency)
 		case "description":
 			existingField.Description = helper.PgString(field.Interface().(string))
 		case "images":
@@ -367,14 +423,23 @@ func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdat
 		return res, err
 	}
 
+	// Moving the field needs access to the location it moves to as well
+	if req.LocationID != uuid.Nil {
+		if err = s.authorize(ctx, "update", existingField.LocationID, userID, permissions); err != nil {
+			return res, err
+		}
+	}
+
 	newField, err := s.repo.UpdateField(ctx, s.db, repository.UpdateFieldParams{
-		ID:          helper.PgUUID(id),
-		LocationID:  existingField.LocationID,
-		Name:        existingField.Name,
-		Type:        existingField.Type,
-		Price:       existingField.Price,
-		Description: existingField.Description,
-		Images:      existingField.Images,
+		ID:             helper.PgUUID(id),
+		LocationID:     existingField.LocationID,
+		Name:           existingField.Name,
+		Type:           existingField.Type,
+		Price:          existingField.Price,
+		Currency:       existingField.Currency,
+		Description:    existingField.Description,
+		Images:         existingField.Images,
+		OrganizationID: existingField.OrganizationID,
 	})
 
 	if err != nil {
@@ -385,18 +450,26 @@ func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdat
 
 	res = newField.String()
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionFieldUpdate,
+		EntityType: constant.AuditEntityField,
+		EntityID:   id,
+		Before:     before,
+		After:      dto.FieldResponse{}.FromModel(existingField),
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheGetFieldKey, id)); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheGetFieldKey, id)); err != nil {
 			s.logger.Error(identifier, "update - failed to delete cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "update - failed to delete cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "update - failed to clear cache: %w", err)
 		}
 	}()
@@ -404,8 +477,11 @@ func (s *fieldService) Update(ctx context.Context, id string, req dto.FieldUpdat
 	return res, nil
 }
 
-func (s *fieldService) Delete(ctx context.Context, id string) (err error) {
-	existingField, err := s.repo.GetFieldById(ctx, s.db, helper.PgUUID(id))
+func (s *fieldService) Delete(ctx context.Context, id, userID string, permissions []string) (err error) {
+	existingField, err := s.repo.GetFieldById(ctx, s.db, repository.GetFieldByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("fields %s - not found", id))
@@ -416,7 +492,14 @@ func (s *fieldService) Delete(ctx context.Context, id string) (err error) {
 		return err
 	}
 
-	err = s.repo.DeleteField(ctx, s.db, helper.PgUUID(id))
+	if err = s.authorize(ctx, "delete", existingField.LocationID, userID, permissions); err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteField(ctx, s.db, repository.DeleteFieldParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
@@ -428,6 +511,13 @@ func (s *fieldService) Delete(ctx context.Context, id string) (err error) {
 		return err
 	}
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionFieldDelete,
+		EntityType: constant.AuditEntityField,
+		EntityID:   id,
+		Before:     dto.FieldResponse{}.FromModel(existingField),
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
@@ -439,15 +529,15 @@ func (s *fieldService) Delete(ctx context.Context, id string) (err error) {
 		}
 
 		// Clear cache
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheGetFieldKey, id)); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheGetFieldKey, id)); err != nil {
 			s.logger.Error(identifier, "delete - failed to delete cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "delete - failed to delete cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "delete - failed to clear cache: %w", err)
 		}
 	}()
@@ -455,7 +545,7 @@ func (s *fieldService) Delete(ctx context.Context, id string) (err error) {
 	return nil
 }
 
-func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files []*multipart.FileHeader) (urls []string, err error) {
+func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files []*multipart.FileHeader, userID string, permissions []string) (urls []string, err error) {
 	if len(files) == 0 {
 		err = failure.BadRequestFromString("no files uploaded")
 		s.logger.Error(identifier, "uploadImages - no files uploaded: %w", err)
@@ -472,7 +562,10 @@ func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files [
 	}
 
 	// Get existing field to append new images
-	existingField, err := s.repo.GetFieldById(ctx, s.db, helper.PgUUID(fieldID))
+	existingField, err := s.repo.GetFieldById(ctx, s.db, repository.GetFieldByIdParams{
+		ID:             helper.PgUUID(fieldID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("field %s - not found", fieldID))
@@ -483,6 +576,10 @@ func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files [
 		return urls, err
 	}
 
+	if err = s.authorize(ctx, "uploadImages", existingField.LocationID, userID, permissions); err != nil {
+		return urls, err
+	}
+
 	var uploadedURLs []string
 
 	for _, file := range files {
@@ -521,13 +618,15 @@ func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files [
 	}
 
 	_, err = s.repo.UpdateField(ctx, s.db, repository.UpdateFieldParams{
-		ID:          existingField.ID,
-		LocationID:  existingField.LocationID,
-		Name:        existingField.Name,
-		Type:        existingField.Type,
-		Price:       existingField.Price,
-		Description: existingField.Description,
-		Images:      existingField.Images,
+		ID:             existingField.ID,
+		LocationID:     existingField.LocationID,
+		Name:           existingField.Name,
+		Type:           existingField.Type,
+		Price:          existingField.Price,
+		Currency:       existingField.Currency,
+		Description:    existingField.Description,
+		Images:         existingField.Images,
+		OrganizationID: existingField.OrganizationID,
 	})
 	if err != nil {
 		s.logger.Error(identifier, "uploadImages - failed to update field with new images: %w", err)
@@ -545,11 +644,11 @@ func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files [
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldKey, "*")); err != nil {
 			s.logger.Error(identifier, "uploadImages - failed to clear cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "uploadImages - failed to clear cache: %w", err)
 		}
 	}()
@@ -557,9 +656,12 @@ func (s *fieldService) UploadImages(ctx context.Context, fieldID string, files [
 	return uploadedURLs, nil
 }
 
-func (s *fieldService) DeleteImage(ctx context.Context, fieldID, imageURL string) error {
+func (s *fieldService) DeleteImage(ctx context.Context, fieldID, imageURL, userID string, permissions []string) error {
 	// Get existing field to remove image from the array
-	existingField, err := s.repo.GetFieldById(ctx, s.db, helper.PgUUID(fieldID))
+	existingField, err := s.repo.GetFieldById(ctx, s.db, repository.GetFieldByIdParams{
+		ID:             helper.PgUUID(fieldID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("field %s - not found", fieldID))
@@ -570,6 +672,10 @@ func (s *fieldService) DeleteImage(ctx context.Context, fieldID, imageURL string
 		return err
 	}
 
+	if err = s.authorize(ctx, "deleteImage", existingField.LocationID, userID, permissions); err != nil {
+		return err
+	}
+
 	// Find and remove the image URL from the array
 	updatedImages := make([]string, 0) // Initialize as empty slice instead of nil
 	found := false
@@ -599,13 +705,15 @@ func (s *fieldService) DeleteImage(ctx context.Context, fieldID, imageURL string
 
 	// Update field with removed image
 	_, err = s.repo.UpdateField(ctx, s.db, repository.UpdateFieldParams{
-		ID:          existingField.ID,
-		LocationID:  existingField.LocationID,
-		Name:        existingField.Name,
-		Type:        existingField.Type,
-		Price:       existingField.Price,
-		Description: existingField.Description,
-		Images:      updatedImages,
+		ID:             existingField.ID,
+		LocationID:     existingField.LocationID,
+		Name:           existingField.Name,
+		Type:           existingField.Type,
+		Price:          existingField.Price,
+		Currency:       existingField.Currency,
+		Description:    existingField.Description,
+		Images:         updatedImages,
+		OrganizationID: existingField.OrganizationID,
 	})
 	if err != nil {
 		s.logger.Error(identifier, "deleteImage - failed to update field after removing image: %w", err)
@@ -613,17 +721,70 @@ func (s *fieldService) DeleteImage(ctx context.Context, fieldID, imageURL string
 		return err
 	}
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionFieldImageDelete,
+		EntityType: constant.AuditEntityField,
+		EntityID:   fieldID,
+		Before:     map[string][]string{"images": existingField.Images},
+		After:      map[string][]string{"images": updatedImages},
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldKey, "*")); err != nil {
 			s.logger.Error(identifier, "deleteImage - failed to clear cache: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetFieldsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetFieldsKey, "*")); err != nil {
 			s.logger.Error(identifier, "deleteImage - failed to clear cache: %w", err)
 		}
 	}()
 
 	return nil
 }
+
+// authorize rejects changes to fields at locations the user is not assigned to.
+func (s *fieldService) authorize(ctx context.Context, op string, locationID pgtype.UUID, userID string, permissions []string) error {
+	scope, err := locationService.ResolveStaffScope(ctx, s.db, s.locationRepo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, op+" - failed to resolve staff scope: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if !scope.Allows(locationID) {
+		s.logger.Error(identifier, op+" - user %s is not assigned to location %s", userID, locationID.String())
+
+		return failure.Forbidden("you are not assigned to this location")
+	}
+
+	// Assigned locations always belong to the organisation, any other location has to be checked
+	if scope.All {
+		_, err = s.locationRepo.GetLocationById(ctx, s.db, locationRepo.GetLocationByIdParams{
+			ID:             locationID,
+			OrganizationID: tenant.FromContext(ctx),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, op+" - location %s - not found", locationID.String())
+
+			return failure.NotFound(fmt.Sprintf("location %s - not found", locationID.String()))
+		}
+
+		if err != nil {
+			s.logger.Error(identifier, op+" - failed to get location by id: %w", err)
+
+			return failure.InternalError(err)
+		}
+	}
+
+	return nil
+}
+
+func validatePrice(price money.Money) error {
+	if price.LessThan(money.FromMajor(constant.FieldMinimumPrice, price.Currency)) {
+		return failure.BadRequestFromString(fmt.Sprintf("price must be at least %s", money.FromMajor(constant.FieldMinimumPrice, price.Currency)))
+	}
+
+	return nil
+}
@@ -1,15 +1,26 @@
 package dto
 
+import "github.com/savioruz/goth/pkg/money"
+
 type CreateLocationRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Latitude    float64 `json:"latitude" validate:"required,latitude"`
-	Longitude   float64 `json:"longitude" validate:"required,longitude"`
-	Description string  `json:"description" validate:"omitempty"`
+	Name           string       `json:"name" validate:"required"`
+	Latitude       float64      `json:"latitude" validate:"required,latitude"`
+	Longitude      float64      `json:"longitude" validate:"required,longitude"`
+	Description    string       `json:"description" validate:"omitempty"`
+	Currency       string       `json:"currency" validate:"omitempty,oneof=IDR" example:"IDR"`
+	ServiceFee     *money.Money `json:"service_fee" validate:"omitempty"`
+	ConvenienceFee *money.Money `json:"convenience_fee" validate:"omitempty"`
+	TaxRate        float64      `json:"tax_rate" validate:"omitempty,min=0,max=100" example:"11"`
+	TaxInclusive   bool         `json:"tax_inclusive" example:"false"`
 }
 
 type UpdateLocationRequest struct {
-	Name        string  `json:"name" validate:"omitempty"`
-	Latitude    float64 `json:"latitude" validate:"omitempty,latitude"`
-	Longitude   float64 `json:"longitude" validate:"omitempty,longitude"`
-	Description string  `json:"description" validate:"omitempty"`
+	Name           string       `json:"name" validate:"omitempty"`
+	Latitude       float64      `json:"latitude" validate:"omitempty,latitude"`
+	Longitude      float64      `json:"longitude" validate:"omitempty,longitude"`
+	Description    string       `json:"description" validate:"omitempty"`
+	ServiceFee     *money.Money `json:"service_fee" validate:"omitempty"`
+	ConvenienceFee *money.Money `json:"convenience_fee" validate:"omitempty"`
+	TaxRate        *float64     `json:"tax_rate" validate:"omitempty,min=0,max=100" example:"11"`
+	TaxInclusive   *bool        `json:"tax_inclusive" example:"false"`
 }
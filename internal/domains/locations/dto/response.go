@@ -4,27 +4,38 @@ import (
 	"github.com/savioruz/goth/internal/domains/locations/repository"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
 )
 
 type LocationResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Latitude    float64 `json:"latitude"`
-	Longitude   float64 `json:"longitude"`
-	Description string  `json:"description,omitempty"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Latitude       float64     `json:"latitude"`
+	Longitude      float64     `json:"longitude"`
+	Description    string      `json:"description,omitempty"`
+	Currency       string      `json:"currency"`
+	ServiceFee     money.Money `json:"service_fee"`
+	ConvenienceFee money.Money `json:"convenience_fee"`
+	TaxRate        float64     `json:"tax_rate"`
+	TaxInclusive   bool        `json:"tax_inclusive"`
+	CreatedAt      string      `json:"created_at"`
+	UpdatedAt      string      `json:"updated_at"`
 }
 
 func (l LocationResponse) FromModel(model repository.Location) LocationResponse {
 	return LocationResponse{
-		ID:          model.ID.String(),
-		Name:        model.Name,
-		Latitude:    model.Latitude,
-		Longitude:   model.Longitude,
-		Description: model.Description.String,
-		CreatedAt:   model.CreatedAt.Time.Format(constant.FullDateFormat),
-		UpdatedAt:   model.UpdatedAt.Time.Format(constant.FullDateFormat),
+		ID:             model.ID.String(),
+		Name:           model.Name,
+		Latitude:       model.Latitude,
+		Longitude:      model.Longitude,
+		Description:    model.Description.String,
+		Currency:       model.Currency,
+		ServiceFee:     money.FromPgOrZero(model.ServiceFee, model.Currency),
+		ConvenienceFee: money.FromPgOrZero(model.ConvenienceFee, model.Currency),
+		TaxRate:        helper.Float64FromPg(model.TaxRate),
+		TaxInclusive:   model.TaxInclusive,
+		CreatedAt:      model.CreatedAt.Time.Format(constant.FullDateFormat),
+		UpdatedAt:      model.UpdatedAt.Time.Format(constant.FullDateFormat),
 	}
 }
 
@@ -50,3 +61,17 @@ func (l *PaginatedLocationResponse) FromModel(locations []repository.Location, t
 		l.Locations[i] = LocationResponse{}.FromModel(location)
 	}
 }
+
+type LocationStaffResponse struct {
+	LocationID string `json:"location_id"`
+	UserID     string `json:"user_id"`
+	AssignedAt string `json:"assigned_at"`
+}
+
+func (l LocationStaffResponse) FromModel(model repository.LocationStaff) LocationStaffResponse {
+	return LocationStaffResponse{
+		LocationID: model.LocationID.String(),
+		UserID:     model.UserID.String(),
+		AssignedAt: model.CreatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
@@ -33,18 +33,24 @@ const (
 	routePath = "/locations"
 )
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	locations := r.Group(routePath)
 
-	locations.Post("/", middleware.Jwt(), middleware.AdminOnly(), h.Create)
+	locations.Post("/", authn.Jwt(), middleware.RequirePermission(constant.PermissionLocationsWrite, constant.PermissionLocationsAny), h.Create)
 	locations.Get("/:id", h.Get)
 	locations.Get("/", h.GetAll)
-	locations.Patch("/:id", middleware.Jwt(), middleware.AdminOnly(), h.Update)
-	locations.Delete("/:id", middleware.Jwt(), middleware.AdminOnly(), h.Delete)
+	locations.Patch("/:id", authn.Jwt(), middleware.RequirePermission(constant.PermissionLocationsWrite), h.Update)
+	locations.Delete("/:id", authn.Jwt(), middleware.RequirePermission(constant.PermissionLocationsWrite), h.Delete)
+
+	staff := locations.Group("/:id/staff", authn.Jwt(), middleware.RequirePermission(constant.PermissionLocationsStaff))
+
+	staff.Get("/", h.GetStaff)
+	staff.Put("/:user_id", h.AssignStaff)
+	staff.Delete("/:user_id", h.UnassignStaff)
 }
 
 // Create Location godoc
-// @Summary Create new location
+// @Summary Create new location (requires locations:write and locations:any)
 // @Description Create new location
 // @Tags locations
 // @Accept json
@@ -155,8 +161,8 @@ func (h *Handler) GetAll(ctx *fiber.Ctx) error {
 }
 
 // Update Location godoc
-// @Summary Update location by id
-// @Description Update location by id
+// @Summary Update location by id (requires locations:write)
+// @Description Update location by id. Staff can only update the locations they are assigned to
 // @Tags locations
 // @Accept json
 // @Produce json
@@ -164,6 +170,7 @@ func (h *Handler) GetAll(ctx *fiber.Ctx) error {
 // @Param location body dto.UpdateLocationRequest true "Location update request"
 // @Success 200 {object} response.Data[string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /locations/{id} [patch]
 // @Security BearerAuth
@@ -202,7 +209,14 @@ func (h *Handler) Update(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	res, err := h.service.Update(ctx.UserContext(), id, req)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "update - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.Update(ctx.UserContext(), id, req, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, "update - failed to update location: %w", err)
 
@@ -213,14 +227,15 @@ func (h *Handler) Update(ctx *fiber.Ctx) error {
 }
 
 // Delete Location godoc
-// @Summary Delete location by id
-// @Description Delete location by id
+// @Summary Delete location by id (requires locations:write)
+// @Description Delete location by id. Staff can only delete the locations they are assigned to
 // @Tags locations
 // @Accept json
 // @Produce json
 // @Param id path string true "Location ID"
 // @Success 200 {object} response.Data[string]
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /locations/{id} [delete]
@@ -243,7 +258,14 @@ func (h *Handler) Delete(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	err := h.service.Delete(ctx.UserContext(), id)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, "delete - principal not found: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	err = h.service.Delete(ctx.UserContext(), id, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, "delete - failed to delete location: %w", err)
 
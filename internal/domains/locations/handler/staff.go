@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+)
+
+// GetStaff godoc
+// @Summary Get location staff (requires locations:staff)
+// @Description List the staff assigned to a location
+// @Tags locations
+// @Produce json
+// @Param id path string true "Location ID"
+// @Success 200 {object} response.Data[[]dto.LocationStaffResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /locations/{id}/staff [get]
+// @Security BearerAuth
+func (h *Handler) GetStaff(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		err = failure.BadRequestFromString("invalid location id format")
+
+		h.logger.Error(identifier, "get staff - invalid location id format: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetStaff(ctx.UserContext(), id)
+	if err != nil {
+		h.logger.Error(identifier, "get staff - failed to get location staff: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// AssignStaff godoc
+// @Summary Assign staff to location (requires locations:staff)
+// @Description Let a user manage the bookings, payments and fields of a location
+// @Tags locations
+// @Produce json
+// @Param id path string true "Location ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /locations/{id}/staff/{user_id} [put]
+// @Security BearerAuth
+func (h *Handler) AssignStaff(ctx *fiber.Ctx) error {
+	id, userID, err := h.staffParams(ctx, "assign staff")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err = h.service.AssignStaff(ctx.UserContext(), id, userID); err != nil {
+		h.logger.Error(identifier, "assign staff - failed to assign staff: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "staff assigned")
+}
+
+// UnassignStaff godoc
+// @Summary Unassign staff from location (requires locations:staff)
+// @Description Remove a user from the staff of a location
+// @Tags locations
+// @Produce json
+// @Param id path string true "Location ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /locations/{id}/staff/{user_id} [delete]
+// @Security BearerAuth
+func (h *Handler) UnassignStaff(ctx *fiber.Ctx) error {
+	id, userID, err := h.staffParams(ctx, "unassign staff")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err = h.service.UnassignStaff(ctx.UserContext(), id, userID); err != nil {
+		h.logger.Error(identifier, "unassign staff - failed to unassign staff: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "staff unassigned")
+}
+
+func (h *Handler) staffParams(ctx *fiber.Ctx, op string) (id, userID string, err error) {
+	id = ctx.Params("id")
+	if err = h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		h.logger.Error(identifier, op+" - invalid location id format: %w", err)
+
+		return "", "", failure.BadRequestFromString("invalid location id format")
+	}
+
+	userID = ctx.Params("user_id")
+	if err = h.validator.Var(userID, constant.RequestValidateUUID); err != nil {
+		h.logger.Error(identifier, op+" - invalid user id format: %w", err)
+
+		return "", "", failure.BadRequestFromString("invalid user id format")
+	}
+
+	return id, userID, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/locations/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+// StaffScope is the set of locations a user manages. Users with the locations:any permission
+// manage every location, everyone else only the locations they are assigned to.
+type StaffScope struct {
+	All         bool
+	LocationIDs []pgtype.UUID
+}
+
+// Allows reports whether the location is within the scope.
+func (s StaffScope) Allows(locationID pgtype.UUID) bool {
+	return s.All || slices.Contains(s.LocationIDs, locationID)
+}
+
+// ResolveStaffScope looks up the locations of the request's organisation the user manages. It is used by the services of the
+// other domains to limit staff to their own venues.
+func ResolveStaffScope(ctx context.Context, db repository.DBTX, repo repository.Querier, userID string, permissions []string) (StaffScope, error) {
+	if slices.Contains(permissions, constant.PermissionLocationsAny) {
+		return StaffScope{All: true}, nil
+	}
+
+	locationIDs, err := repo.GetStaffLocationIDs(ctx, db, repository.GetStaffLocationIDsParams{
+		UserID:         helper.PgUUID(userID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		return StaffScope{}, err
+	}
+
+	return StaffScope{LocationIDs: locationIDs}, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/internal/domains/locations/mock"
+	"github.com/savioruz/goth/internal/domains/locations/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResolveStaffScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	organizationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	ctx := tenant.NewContext(context.Background(), organizationID)
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+
+	userID := uuid.New()
+	assigned := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	other := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("success: locations:any reaches every location", func(t *testing.T) {
+		scope, err := ResolveStaffScope(ctx, mockPgx, mockQuerier, userID.String(), []string{constant.PermissionLocationsAny})
+
+		assert.NoError(t, err)
+		assert.True(t, scope.Allows(other))
+	})
+
+	t.Run("success: staff only reach their assigned locations", func(t *testing.T) {
+		mockQuerier.EXPECT().
+			GetStaffLocationIDs(gomock.Any(), gomock.Any(), repository.GetStaffLocationIDsParams{
+				UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+				OrganizationID: organizationID,
+			}).
+			Return([]pgtype.UUID{assigned}, nil)
+
+		scope, err := ResolveStaffScope(ctx, mockPgx, mockQuerier, userID.String(), []string{constant.PermissionBookingsReadAny})
+
+		assert.NoError(t, err)
+		assert.True(t, scope.Allows(assigned))
+		assert.False(t, scope.Allows(other))
+	})
+}
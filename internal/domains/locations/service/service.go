@@ -9,12 +9,16 @@ import (
 	"github.com/savioruz/goth/config"
 	"github.com/savioruz/goth/internal/domains/locations/dto"
 	"github.com/savioruz/goth/internal/domains/locations/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/gdto"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/money"
 	"github.com/savioruz/goth/pkg/postgres"
 	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/tenant"
 	"reflect"
 	"strconv"
 )
@@ -24,25 +28,30 @@ type LocationService interface {
 	Get(ctx context.Context, id string) (res dto.LocationResponse, err error)
 	Count(ctx context.Context, filter string) (res int, err error)
 	GetAll(ctx context.Context, req gdto.PaginationRequest) (res dto.PaginatedLocationResponse, err error)
-	Update(ctx context.Context, id string, req dto.UpdateLocationRequest) (res string, err error)
-	Delete(ctx context.Context, id string) (err error)
+	Update(ctx context.Context, id string, req dto.UpdateLocationRequest, userID string, permissions []string) (res string, err error)
+	Delete(ctx context.Context, id, userID string, permissions []string) (err error)
+	GetStaff(ctx context.Context, id string) (res []dto.LocationStaffResponse, err error)
+	AssignStaff(ctx context.Context, id, userID string) (err error)
+	UnassignStaff(ctx context.Context, id, userID string) (err error)
 }
 
 type locationService struct {
-	db     postgres.PgxIface
-	repo   repository.Querier
-	cache  redis.IRedisCache
-	cfg    *config.Config
-	logger logger.Interface
+	db      postgres.PgxIface
+	repo    repository.Querier
+	cache   redis.IRedisCache
+	cfg     *config.Config
+	logger  logger.Interface
+	auditor audit.Recorder
 }
 
-func New(db postgres.PgxIface, repo repository.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface) LocationService {
+func New(db postgres.PgxIface, repo repository.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface, auditor audit.Recorder) LocationService {
 	return &locationService{
-		db:     db,
-		repo:   repo,
-		cache:  cache,
-		cfg:    cfg,
-		logger: l,
+		db:      db,
+		repo:    repo,
+		cache:   cache,
+		cfg:     cfg,
+		logger:  l,
+		auditor: auditor,
 	}
 }
 
@@ -55,11 +64,27 @@ const (
 )
 
 func (s *locationService) Create(ctx context.Context, req dto.CreateLocationRequest) (res string, err error) {
+	currency := money.ParseCurrency(req.Currency)
+	serviceFee := feeOrZero(req.ServiceFee, currency)
+	convenienceFee := feeOrZero(req.ConvenienceFee, currency)
+
+	if !money.SameCurrency(currency, serviceFee, convenienceFee) {
+		s.logger.Error(identifier, "create - fees must be in the location currency")
+
+		return res, failure.BadRequestFromString(fmt.Sprintf("fees must be in %s", currency))
+	}
+
 	newLocation, err := s.repo.CreateLocation(ctx, s.db, repository.CreateLocationParams{
-		Name:        req.Name,
-		Latitude:    req.Latitude,
-		Longitude:   req.Longitude,
-		Description: helper.PgString(req.Description),
+		Name:           req.Name,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		Description:    helper.PgString(req.Description),
+		ServiceFee:     serviceFee.Pg(),
+		ConvenienceFee: convenienceFee.Pg(),
+		TaxRate:        helper.PgFloat64(req.TaxRate),
+		TaxInclusive:   req.TaxInclusive,
+		Currency:       string(currency),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "create - failed to create location: %w", err)
@@ -69,14 +94,21 @@ func (s *locationService) Create(ctx context.Context, req dto.CreateLocationRequ
 
 	res = newLocation.ID.String()
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionLocationCreate,
+		EntityType: constant.AuditEntityLocation,
+		EntityID:   res,
+		After:      dto.LocationResponse{}.FromModel(newLocation),
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountLocationsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "create - failed to delete cache for count: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetLocationsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "create - failed to clear cache: %w", err)
 		}
 	}()
@@ -85,7 +117,7 @@ func (s *locationService) Create(ctx context.Context, req dto.CreateLocationRequ
 }
 
 func (s *locationService) Get(ctx context.Context, id string) (res dto.LocationResponse, err error) {
-	cacheKey := helper.BuildCacheKey(cacheGetLocationKey, id)
+	cacheKey := tenant.CacheKey(ctx, cacheGetLocationKey, id)
 
 	var cacheRes dto.LocationResponse
 	err = s.cache.Get(ctx, cacheKey, &cacheRes)
@@ -96,7 +128,10 @@ func (s *locationService) Get(ctx context.Context, id string) (res dto.LocationR
 		return cacheRes, nil
 	}
 
-	location, err := s.repo.GetLocationById(ctx, s.db, helper.PgUUID(id))
+	location, err := s.repo.GetLocationById(ctx, s.db, repository.GetLocationByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if errors.Is(err, pgx.ErrNoRows) {
 		s.logger.Info(identifier, "get - location %s - not found", id)
 
@@ -124,7 +159,7 @@ func (s *locationService) Get(ctx context.Context, id string) (res dto.LocationR
 }
 
 func (s *locationService) Count(ctx context.Context, filter string) (res int, err error) {
-	cacheKey := helper.BuildCacheKey(cacheGetLocationsKey, filter)
+	cacheKey := tenant.CacheKey(ctx, cacheGetLocationsKey, filter)
 
 	var cacheRes int
 	err = s.cache.Get(ctx, cacheKey, &cacheRes)
@@ -135,7 +170,10 @@ func (s *locationService) Count(ctx context.Context, filter string) (res int, er
 		return cacheRes, nil
 	}
 
-	totalItems, err := s.repo.CountLocationsWithFilter(ctx, s.db, filter)
+	totalItems, err := s.repo.CountLocationsWithFilter(ctx, s.db, repository.CountLocationsWithFilterParams{
+		Column1:        filter,
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		s.logger.Error(identifier, "count - failed to count locations: %w", err)
 
@@ -161,7 +199,7 @@ func (s *locationService) GetAll(ctx context.Context, req gdto.PaginationRequest
 	keyArgs["page"] = strconv.Itoa(page)
 	keyArgs["limit"] = strconv.Itoa(limit)
 	keyArgs["filter"] = req.Filter
-	cacheKey := helper.BuildCacheKey(cacheGetLocationsKey, helper.GenerateUniqueKey(keyArgs))
+	cacheKey := tenant.CacheKey(ctx, cacheGetLocationsKey, helper.GenerateUniqueKey(keyArgs))
 
 	var cacheRes dto.PaginatedLocationResponse
 
@@ -182,9 +220,10 @@ func (s *locationService) GetAll(ctx context.Context, req gdto.PaginationRequest
 	offset := helper.CalculateOffset(page, limit)
 
 	locations, err := s.repo.GetLocationsWithFilter(ctx, s.db, repository.GetLocationsWithFilterParams{
-		Column1: req.Filter,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		Column1:        req.Filter,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
 	})
 	if err != nil {
 		s.logger.Error(identifier, "get all - failed to get locations: %w", err)
@@ -204,8 +243,15 @@ func (s *locationService) GetAll(ctx context.Context, req gdto.PaginationRequest
 	return res, nil
 }
 
-func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateLocationRequest) (res string, err error) {
-	existingLocation, err := s.repo.GetLocationById(ctx, s.db, helper.PgUUID(id))
+func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateLocationRequest, userID string, permissions []string) (res string, err error) {
+	if err = s.authorize(ctx, "update", id, userID, permissions); err != nil {
+		return res, err
+	}
+
+	existingLocation, err := s.repo.GetLocationById(ctx, s.db, repository.GetLocationByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("location %s - not found", id))
@@ -216,6 +262,8 @@ func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateL
 		return res, err
 	}
 
+	before := dto.LocationResponse{}.FromModel(existingLocation)
+
 	val := reflect.ValueOf(req)
 	typ := reflect.TypeOf(req)
 
@@ -239,6 +287,23 @@ func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateL
 			existingLocation.Longitude = field.Interface().(float64)
 		case "description":
 			existingLocation.Description = helper.PgString(field.Interface().(string))
+		case "service_fee", "convenience_fee":
+			fee := *field.Interface().(*money.Money)
+			if !money.SameCurrency(money.ParseCurrency(existingLocation.Currency), fee) {
+				s.logger.Error(identifier, "update - fees must be in the location currency")
+
+				return res, failure.BadRequestFromString(fmt.Sprintf("fees must be in %s", existingLocation.Currency))
+			}
+
+			if fieldName == "service_fee" {
+				existingLocation.ServiceFee = fee.Pg()
+			} else {
+				existingLocation.ConvenienceFee = fee.Pg()
+			}
+		case "tax_rate":
+			existingLocation.TaxRate = helper.PgFloat64(*field.Interface().(*float64))
+		case "tax_inclusive":
+			existingLocation.TaxInclusive = *field.Interface().(*bool)
 		}
 	}
 
@@ -251,11 +316,17 @@ func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateL
 	}
 
 	newLocation, err := s.repo.UpdateLocation(ctx, s.db, repository.UpdateLocationParams{
-		ID:          helper.PgUUID(id),
-		Name:        existingLocation.Name,
-		Latitude:    existingLocation.Latitude,
-		Longitude:   existingLocation.Longitude,
-		Description: existingLocation.Description,
+		ID:             helper.PgUUID(id),
+		Name:           existingLocation.Name,
+		Latitude:       existingLocation.Latitude,
+		Longitude:      existingLocation.Longitude,
+		Description:    existingLocation.Description,
+		ServiceFee:     existingLocation.ServiceFee,
+		ConvenienceFee: existingLocation.ConvenienceFee,
+		TaxRate:        existingLocation.TaxRate,
+		TaxInclusive:   existingLocation.TaxInclusive,
+		Currency:       existingLocation.Currency,
+		OrganizationID: existingLocation.OrganizationID,
 	})
 
 	if err != nil {
@@ -266,18 +337,26 @@ func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateL
 
 	res = newLocation.ID.String()
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionLocationUpdate,
+		EntityType: constant.AuditEntityLocation,
+		EntityID:   id,
+		Before:     before,
+		After:      dto.LocationResponse{}.FromModel(newLocation),
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheGetLocationKey, id)); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheGetLocationKey, id)); err != nil {
 			s.logger.Error(identifier, "update - failed to delete cache for location %s: %w", id, err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheCountLocationsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheCountLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "update - failed to delete cache for count: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetLocationsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "update - failed to clear cache: %w", err)
 		}
 	}()
@@ -285,8 +364,29 @@ func (s *locationService) Update(ctx context.Context, id string, req dto.UpdateL
 	return res, nil
 }
 
-func (s *locationService) Delete(ctx context.Context, id string) (err error) {
-	err = s.repo.DeleteLocation(ctx, s.db, helper.PgUUID(id))
+func (s *locationService) Delete(ctx context.Context, id, userID string, permissions []string) (err error) {
+	if err = s.authorize(ctx, "delete", id, userID, permissions); err != nil {
+		return err
+	}
+
+	existingLocation, err := s.repo.GetLocationById(ctx, s.db, repository.GetLocationByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = failure.NotFound(fmt.Sprintf("location %s - not found", id))
+		}
+
+		s.logger.Error(identifier, "delete - failed to get location by id: %w", err)
+
+		return err
+	}
+
+	err = s.repo.DeleteLocation(ctx, s.db, repository.DeleteLocationParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			err = failure.NotFound(fmt.Sprintf("location %s - not found", id))
@@ -297,26 +397,59 @@ func (s *locationService) Delete(ctx context.Context, id string) (err error) {
 			err = failure.Conflict("location used by other entities")
 		}
 
-		s.logger.Error(identifier, "delete - failed to get location by id: %w", err)
+		s.logger.Error(identifier, "delete - failed to delete location: %w", err)
 
 		return err
 	}
 
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionLocationDelete,
+		EntityType: constant.AuditEntityLocation,
+		EntityID:   id,
+		Before:     dto.LocationResponse{}.FromModel(existingLocation),
+	})
+
 	go func() {
 		ctx := context.WithoutCancel(ctx)
 
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheGetLocationKey, id)); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheGetLocationKey, id)); err != nil {
 			s.logger.Error(identifier, "delete - failed to delete cache for location %s: %w", id, err)
 		}
 
-		if err := s.cache.Delete(ctx, helper.BuildCacheKey(cacheCountLocationsKey, "*")); err != nil {
+		if err := s.cache.Delete(ctx, tenant.CacheKey(ctx, cacheCountLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "delete - failed to delete cache for count: %w", err)
 		}
 
-		if err := s.cache.Clear(ctx, helper.BuildCacheKey(cacheGetLocationsKey, "*")); err != nil {
+		if err := s.cache.Clear(ctx, tenant.CacheKey(ctx, cacheGetLocationsKey, "*")); err != nil {
 			s.logger.Error(identifier, "delete - failed to clear cache: %w", err)
 		}
 	}()
 
 	return nil
 }
+
+// authorize rejects changes to locations the user is not assigned to.
+func (s *locationService) authorize(ctx context.Context, op, id, userID string, permissions []string) error {
+	scope, err := ResolveStaffScope(ctx, s.db, s.repo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, op+" - failed to resolve staff scope: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if !scope.Allows(helper.PgUUID(id)) {
+		s.logger.Error(identifier, op+" - user %s is not assigned to location %s", userID, id)
+
+		return failure.Forbidden("you are not assigned to this location")
+	}
+
+	return nil
+}
+
+func feeOrZero(fee *money.Money, currency money.Currency) money.Money {
+	if fee == nil {
+		return money.Zero(currency)
+	}
+
+	return *fee
+}
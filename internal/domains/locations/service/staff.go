@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/savioruz/goth/internal/domains/locations/dto"
+	"github.com/savioruz/goth/internal/domains/locations/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+func (s *locationService) GetStaff(ctx context.Context, id string) (res []dto.LocationStaffResponse, err error) {
+	if err = s.ensureExists(ctx, "get staff", id); err != nil {
+		return res, err
+	}
+
+	staff, err := s.repo.GetLocationStaff(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		s.logger.Error(identifier, "get staff - failed to get location staff: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = make([]dto.LocationStaffResponse, len(staff))
+	for i, member := range staff {
+		res[i] = dto.LocationStaffResponse{}.FromModel(member)
+	}
+
+	return res, nil
+}
+
+func (s *locationService) AssignStaff(ctx context.Context, id, userID string) (err error) {
+	if err = s.ensureExists(ctx, "assign staff", id); err != nil {
+		return err
+	}
+
+	err = s.repo.AssignLocationStaff(ctx, s.db, repository.AssignLocationStaffParams{
+		LocationID: helper.PgUUID(id),
+		UserID:     helper.PgUUID(userID),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			s.logger.Error(identifier, "assign staff - user %s - not found", userID)
+
+			return failure.NotFound(fmt.Sprintf("user %s - not found", userID))
+		}
+
+		s.logger.Error(identifier, "assign staff - failed to assign staff: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionLocationStaffAssign,
+		EntityType: constant.AuditEntityLocation,
+		EntityID:   id,
+		After:      map[string]string{"user_id": userID},
+	})
+
+	return nil
+}
+
+func (s *locationService) UnassignStaff(ctx context.Context, id, userID string) (err error) {
+	if err = s.ensureExists(ctx, "unassign staff", id); err != nil {
+		return err
+	}
+
+	unassigned, err := s.repo.UnassignLocationStaff(ctx, s.db, repository.UnassignLocationStaffParams{
+		LocationID: helper.PgUUID(id),
+		UserID:     helper.PgUUID(userID),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "unassign staff - failed to unassign staff: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if unassigned == 0 {
+		s.logger.Error(identifier, "unassign staff - user %s is not assigned to location %s", userID, id)
+
+		return failure.NotFound("staff assignment not found")
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionLocationStaffUnassign,
+		EntityType: constant.AuditEntityLocation,
+		EntityID:   id,
+		Before:     map[string]string{"user_id": userID},
+	})
+
+	return nil
+}
+
+func (s *locationService) ensureExists(ctx context.Context, op, id string) error {
+	_, err := s.repo.GetLocationById(ctx, s.db, repository.GetLocationByIdParams{
+		ID:             helper.PgUUID(id),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, op+" - location %s - not found", id)
+
+			return failure.NotFound(fmt.Sprintf("location %s - not found", id))
+		}
+
+		s.logger.Error(identifier, op+" - failed to get location by id: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
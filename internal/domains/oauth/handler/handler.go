@@ -1,20 +1,28 @@
 package handler
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"net/url"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
 	"github.com/savioruz/goth/internal/delivery/http/response"
 	"github.com/savioruz/goth/internal/domains/oauth/service"
-	// Register dto for swagger docs
-	_ "github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/logger"
 )
 
+const callbackPath = "/oauth/callback"
+
 var (
-	ErrGoogleLoginCode = errors.New("oauth: google login code required")
+	ErrLoginCode      = errors.New("oauth: login code required")
+	ErrStateRequired  = errors.New("oauth: state parameter is required")
+	ErrProviderDenied = errors.New("oauth: provider denied the login")
 )
 
 type Handler struct {
@@ -33,26 +41,82 @@ func New(s service.OAuthService, l logger.Interface, v *validator.Validate, cfg
 	}
 }
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
-	auth := r.Group("/oauth")
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
+	auth := r.Group("/auth")
 
-	auth.Get("/google/login", h.GoogleLogin)
-	auth.Get("/google/callback", h.GoogleCallback)
+	auth.Get("/providers", h.Providers)
+	auth.Post("/oauth/exchange", h.Exchange)
+	auth.Get("/identities", authn.Jwt(), h.GetIdentities)
+
+	auth.Get("/:provider/login", h.Login)
+	auth.Get("/:provider/callback", h.Callback)
+	// Providers using response_mode=form_post, e.g. Sign in with Apple, POST the callback
+	auth.Post("/:provider/callback", h.Callback)
+	auth.Get("/:provider/link", authn.Jwt(), middleware.NoImpersonation(), h.Link)
+	auth.Post("/:provider/link", authn.Jwt(), middleware.NoImpersonation(), h.ConfirmLink)
+	auth.Delete("/:provider/link", authn.Jwt(), middleware.NoImpersonation(), h.Unlink)
+}
+
+// Providers godoc
+// @Summary List OAuth providers
+// @Description List the names of the OAuth providers users can sign in with
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Data[[]string]
+// @Router /auth/providers [get]
+func (h *Handler) Providers(ctx *fiber.Ctx) error {
+	return response.WithJSON(ctx, fiber.StatusOK, h.service.Providers())
 }
 
-// GoogleLogin godoc
-// @Summary Get Google login URL
-// @Description Returns Google OAuth authorization URL and state parameter for the frontend
+// Login godoc
+// @Summary Get OAuth login URL
+// @Description Returns the provider's authorization URL and state parameter for the frontend. The state is valid for a single callback within 10 minutes.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param provider path string true "OAuth provider, e.g. google, github, apple"
+// @Param redirect_uri query string false "Allowed frontend URL to return to after login, defaults to the configured frontend"
+// @Success 200 {object} response.Data[dto.OauthGetURLResponse]
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/{provider}/login [get]
+func (h *Handler) Login(ctx *fiber.Ctx) error {
+	res, err := h.service.GetAuthURL(ctx.UserContext(), ctx.Params("provider"), ctx.Query("redirect_uri"))
+	if err != nil {
+		h.logger.Error("http - v1 - auth - oauth login - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// Link godoc
+// @Summary Get OAuth link URL
+// @Description Returns the provider's authorization URL that links the provider to the current user's account. The callback redirects to the frontend with a link_code, which the logged-in user confirms at POST /auth/{provider}/link within a minute.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "OAuth provider, e.g. google, github, apple"
+// @Param redirect_uri query string false "Allowed frontend URL to return to, defaults to the configured frontend"
 // @Success 200 {object} response.Data[dto.OauthGetURLResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
-// @Router /oauth/google/login [get]
-func (h *Handler) GoogleLogin(ctx *fiber.Ctx) error {
-	res, err := h.service.GetGoogleAuthURL()
+// @Router /auth/{provider}/link [get]
+// @Security BearerAuth
+func (h *Handler) Link(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - v1 - auth - oauth link - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	res, err := h.service.GetLinkURL(ctx.UserContext(), ctx.Params("provider"), userID, ctx.Query("redirect_uri"))
 	if err != nil {
-		h.logger.Error("http - v1 - auth - google login - " + err.Error())
+		h.logger.Error("http - v1 - auth - oauth link - " + err.Error())
 
 		return response.WithError(ctx, err)
 	}
@@ -60,60 +124,207 @@ func (h *Handler) GoogleLogin(ctx *fiber.Ctx) error {
 	return response.WithJSON(ctx, fiber.StatusOK, res)
 }
 
-// GoogleCallback godoc
-// @Summary Google OAuth callback
-// @Description Handle the Google OAuth callback and redirect to frontend with JWT tokens
+// ConfirmLink godoc
+// @Summary Confirm OAuth link
+// @Description Link the provider to the current user's account with the one-time link_code from the callback redirect. Only the user who started the link flow can redeem it.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param code query string true "Authorization code from Google"
-// @Param state query string true "State parameter for CSRF protection"
-// @Param redirect_uri query string false "Frontend URI to redirect to with tokens"
-// @Success 302 {string} string "Redirect to frontend with tokens"
+// @Param provider path string true "OAuth provider, e.g. google, github, apple"
+// @Param link body dto.OAuthLinkRequest true "OAuth link request"
+// @Success 200 {object} response.Message
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 409 {object} response.Error
 // @Failure 500 {object} response.Error
-// @Router /oauth/google/callback [get]
-func (h *Handler) GoogleCallback(ctx *fiber.Ctx) error {
-	code := ctx.Query("code")
-	if code == "" {
-		h.logger.Error("http - v1 - auth - google callback - code is empty")
+// @Router /auth/{provider}/link [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmLink(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - v1 - auth - oauth confirm link - invalid user type in context")
 
-		return response.WithError(ctx, ErrGoogleLoginCode)
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
 	}
 
-	state := ctx.Query("state")
-	if state == "" {
-		h.logger.Error("http - v1 - auth - google callback - state is empty")
+	var req dto.OAuthLinkRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - v1 - auth - oauth confirm link - body parsing error: " + err.Error())
 
-		return response.WithError(ctx, errors.New("oauth: state parameter is required")) //nolint:err113
+		return response.WithError(ctx, err)
 	}
 
-	// @TODO: Validate state parameter here if needed
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - v1 - auth - oauth confirm link - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.ConfirmLink(ctx.UserContext(), ctx.Params("provider"), userID, req); err != nil {
+		h.logger.Error("http - v1 - auth - oauth confirm link - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "account linked")
+}
+
+// Unlink godoc
+// @Summary Unlink OAuth provider
+// @Description Remove a linked provider from the current user's account. The last way to sign in cannot be removed.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "OAuth provider, e.g. google, github, apple"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/{provider}/link [delete]
+// @Security BearerAuth
+func (h *Handler) Unlink(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - v1 - auth - oauth unlink - invalid user type in context")
 
-	redirectURI := h.cfg.OAuth.Google.FrontendURL
-	if redirectURI == "" {
-		h.logger.Error("http - v1 - auth - google callback - redirect URI is not set")
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
 
-		err := errors.New("redirect URI is not set in configuration") //nolint:err113
+	if err := h.service.Unlink(ctx.UserContext(), userID, ctx.Params("provider")); err != nil {
+		h.logger.Error("http - v1 - auth - oauth unlink - " + err.Error())
 
 		return response.WithError(ctx, err)
 	}
 
-	data, err := h.service.HandleGoogleCallback(ctx.Context(), code)
+	return response.WithMessage(ctx, fiber.StatusOK, "account unlinked")
+}
+
+// GetIdentities godoc
+// @Summary List linked OAuth providers
+// @Description List the providers linked to the current user's account
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Data[[]dto.UserIdentityResponse]
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/identities [get]
+// @Security BearerAuth
+func (h *Handler) GetIdentities(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - v1 - auth - oauth identities - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	res, err := h.service.GetIdentities(ctx.UserContext(), userID)
+	if err != nil {
+		h.logger.Error("http - v1 - auth - oauth identities - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// Callback godoc
+// @Summary OAuth callback
+// @Description Handle the provider's callback and redirect to the frontend chosen at login with a one-time code, which it exchanges for tokens at /auth/oauth/exchange within a minute. Link flows redirect with linked=true and a link_code instead.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "OAuth provider, e.g. google, github, apple"
+// @Param code query string true "Authorization code from the provider"
+// @Param state query string true "State parameter for CSRF protection"
+// @Success 302 {string} string "Redirect to frontend with a one-time code"
+// @Router /auth/{provider}/callback [get]
+func (h *Handler) Callback(ctx *fiber.Ctx) error {
+	// Query parameters for redirects, form values for response_mode=form_post
+	code := cmp.Or(ctx.Query("code"), ctx.FormValue("code"))
+	state := cmp.Or(ctx.Query("state"), ctx.FormValue("state"))
+
+	res, err := h.handleCallback(ctx, code, state)
 	if err != nil {
 		reqID := "unknown"
 		if id, ok := ctx.Locals("request_id").(string); ok {
 			reqID = id
 		}
 
-		h.logger.Error("http - v1 - auth - google callback - request_id: " + reqID + " - " + err.Error())
+		h.logger.Error("http - v1 - auth - oauth callback - request_id: " + reqID + " - " + err.Error())
+
+		redirectURI := res.RedirectURI
+		if redirectURI == "" {
+			redirectURI = h.service.DefaultRedirectURI()
+		}
+
+		return ctx.Redirect(redirectURI + callbackPath + "?" + url.Values{"error": {err.Error()}}.Encode())
+	}
+
+	query := url.Values{"code": {res.Code}, "state": {state}}
+	if res.Linked {
+		query = url.Values{"linked": {"true"}, "link_code": {res.Code}, "provider": {ctx.Params("provider")}, "state": {state}}
+	}
+
+	// A 303 turns the form_post callback into a GET on the frontend
+	return ctx.Redirect(res.RedirectURI+callbackPath+"?"+query.Encode(), fiber.StatusSeeOther)
+}
 
-		return ctx.Redirect(redirectURI + "?error=" + err.Error())
+func (h *Handler) handleCallback(ctx *fiber.Ctx, code, state string) (dto.OAuthCallbackResponse, error) {
+	if errParam := cmp.Or(ctx.Query("error"), ctx.FormValue("error")); errParam != "" {
+		return dto.OAuthCallbackResponse{}, fmt.Errorf("%w: %s", ErrProviderDenied, errParam)
+	}
+
+	if code == "" {
+		return dto.OAuthCallbackResponse{}, ErrLoginCode
+	}
+
+	if state == "" {
+		return dto.OAuthCallbackResponse{}, ErrStateRequired
 	}
 
-	path := "/oauth/callback"
-	redirectURL := fmt.Sprintf("%s%s?access_token=%s&refresh_token=%s&state=%s",
-		redirectURI, path, data.AccessToken, data.RefreshToken, state)
+	return h.service.HandleCallback(ctx.UserContext(), ctx.Params("provider"), code, state)
+}
+
+// Exchange godoc
+// @Summary Exchange OAuth authorization code
+// @Description Exchange the one-time code from the OAuth callback redirect for an access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param exchange body dto.OAuthExchangeRequest true "OAuth exchange request"
+// @Success 200 {object} response.Data[dto.UserLoginResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /auth/oauth/exchange [post]
+func (h *Handler) Exchange(ctx *fiber.Ctx) error {
+	var req dto.OAuthExchangeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error("http - v1 - auth - oauth exchange - body parsing error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - v1 - auth - oauth exchange - validate error: " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
+
+	req.ClientInfo = dto.NewClientInfo(ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+
+	data, err := h.service.Exchange(ctx.UserContext(), req)
+	if err != nil {
+		reqID := "unknown"
+		if id, ok := ctx.Locals("request_id").(string); ok {
+			reqID = id
+		}
+
+		h.logger.Error("http - v1 - auth - oauth exchange - request_id: " + reqID + " - " + err.Error())
+
+		return response.WithError(ctx, err)
+	}
 
-	return ctx.Redirect(redirectURL)
+	return response.WithJSON(ctx, fiber.StatusOK, data)
 }
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/oauth"
+)
+
+// signIn finds the user behind a provider identity. Unknown identities are attached to the account with
+// the same email when the provider has verified that email, otherwise a new account is created.
+func (s *oauthService) signIn(ctx context.Context, provider string, info *oauth.UserInfo) (repository.User, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to begin transaction: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("oauth callback - service - failed to rollback transaction: %w", err)
+		}
+	}(tx, ctx)
+
+	var user repository.User
+
+	identity, err := s.repo.GetUserIdentity(ctx, tx, repository.GetUserIdentityParams{Provider: provider, Subject: info.Subject})
+
+	switch {
+	case err == nil:
+		user, err = s.repo.GetUserByID(ctx, tx, identity.UserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("oauth callback - service - user of identity is deactivated")
+
+			return repository.User{}, failure.Forbidden(accountDeactivated)
+		}
+
+		if err != nil {
+			s.logger.Error("oauth callback - service - failed to get user of identity: %w", err)
+
+			return repository.User{}, failure.InternalError(err)
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		user, err = s.userForNewIdentity(ctx, tx, provider, info)
+		if err != nil {
+			return repository.User{}, err
+		}
+
+		if err = s.createIdentity(ctx, tx, user.ID, provider, info); err != nil {
+			return repository.User{}, err
+		}
+	default:
+		s.logger.Error("oauth callback - service - failed to get identity: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("oauth callback - service - failed to commit transaction: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	return user, nil
+}
+
+func (s *oauthService) userForNewIdentity(ctx context.Context, tx pgx.Tx, provider string, info *oauth.UserInfo) (repository.User, error) {
+	user, err := s.repo.GetUserByEmail(ctx, tx, info.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.createUserUnlessDeactivated(ctx, tx, info)
+	}
+
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to get user by email: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	// Anyone can claim an unverified address at some providers, so that must not take over the account
+	if !info.EmailVerified {
+		s.logger.Error("oauth callback - service - unverified %s email matches an existing account", provider)
+
+		return repository.User{}, failure.Conflict("an account with this email already exists, log in and link " + provider + " from your account")
+	}
+
+	profileImage := user.ProfileImage
+	if !profileImage.Valid || profileImage.String == "" {
+		profileImage = helper.PgString(info.Picture)
+	}
+
+	user, err = s.repo.UpdateUser(ctx, tx, repository.UpdateUserParams{
+		Email:        user.Email,
+		Password:     user.Password,
+		FullName:     user.FullName,
+		ProfileImage: profileImage,
+		IsVerified:   pgtype.Bool{Bool: true, Valid: true},
+		ID:           user.ID,
+	})
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to update user: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	if err = s.attachGuestBookings(ctx, tx, user); err != nil {
+		return repository.User{}, err
+	}
+
+	return user, nil
+}
+
+// createUserUnlessDeactivated creates an account for the email, unless a deactivated account holds it.
+func (s *oauthService) createUserUnlessDeactivated(ctx context.Context, tx pgx.Tx, info *oauth.UserInfo) (repository.User, error) {
+	_, err := s.repo.GetDeactivatedUserByEmail(ctx, tx, info.Email)
+	if err == nil {
+		s.logger.Error("oauth callback - service - email belongs to a deactivated account")
+
+		return repository.User{}, failure.Forbidden(accountDeactivated)
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("oauth callback - service - failed to get deactivated user by email: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	return s.createUser(ctx, tx, info)
+}
+
+func (s *oauthService) createUser(ctx context.Context, tx pgx.Tx, info *oauth.UserInfo) (repository.User, error) {
+	params := repository.CreateUserParams{
+		Email:        info.Email,
+		Password:     pgtype.Text{Valid: false}, // No password for OAuth users
+		Role:         constant.UserRoleUser,
+		FullName:     pgtype.Text{String: info.Name, Valid: true},
+		ProfileImage: pgtype.Text{String: info.Picture, Valid: true},
+		IsVerified:   pgtype.Bool{Bool: info.EmailVerified, Valid: true},
+	}
+
+	user, err := s.repo.CreateUser(ctx, tx, params)
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to create user: %w", err)
+
+		return repository.User{}, failure.InternalError(err)
+	}
+
+	if info.EmailVerified {
+		if err = s.attachGuestBookings(ctx, tx, user); err != nil {
+			return repository.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+// attachGuestBookings gives a user whose email the provider verified the bookings staff made for it as
+// a walk-in guest.
+func (s *oauthService) attachGuestBookings(ctx context.Context, tx pgx.Tx, user repository.User) error {
+	if _, err := s.bookingRepo.AttachGuestBookings(ctx, tx, bookingRepository.AttachGuestBookingsParams{
+		UserID: user.ID,
+		Email:  user.Email,
+	}); err != nil {
+		s.logger.Error("oauth callback - service - failed to attach guest bookings: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+func (s *oauthService) createIdentity(ctx context.Context, db repository.DBTX, userID pgtype.UUID, provider string, info *oauth.UserInfo) error {
+	_, err := s.repo.CreateUserIdentity(ctx, db, repository.CreateUserIdentityParams{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  info.Subject,
+		Email:    info.Email,
+	})
+	if err != nil {
+		s.logger.Error("oauth - service - failed to create identity: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+// link attaches a provider identity to the user who started the link flow.
+func (s *oauthService) link(ctx context.Context, userID, provider string, info *oauth.UserInfo) error {
+	identity, err := s.repo.GetUserIdentity(ctx, s.db, repository.GetUserIdentityParams{Provider: provider, Subject: info.Subject})
+	if err == nil {
+		if identity.UserID.String() == userID {
+			return nil
+		}
+
+		s.logger.Error("oauth link - service - %s identity belongs to another user", provider)
+
+		return failure.Conflict("this " + provider + " account is linked to another user")
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("oauth link - service - failed to get identity: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("oauth link - service - failed to get identities: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	for _, existing := range identities {
+		if existing.Provider == provider {
+			return failure.Conflict("another " + provider + " account is already linked, unlink it first")
+		}
+	}
+
+	return s.createIdentity(ctx, s.db, helper.PgUUID(userID), provider, info)
+}
+
+func (s *oauthService) GetIdentities(ctx context.Context, userID string) ([]dto.UserIdentityResponse, error) {
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("oauth - service - failed to get identities: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	res := make([]dto.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		res[i] = dto.UserIdentityResponse{}.FromModel(identity)
+	}
+
+	return res, nil
+}
+
+// Unlink removes a provider from the user's account, keeping at least one way to sign in.
+func (s *oauthService) Unlink(ctx context.Context, userID, provider string) error {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("oauth unlink - service - failed to get user: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, s.db, user.ID)
+	if err != nil {
+		s.logger.Error("oauth unlink - service - failed to get identities: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if !user.Password.Valid && len(identities) <= 1 {
+		return failure.BadRequestFromString("cannot unlink the only way to sign in, set a password first")
+	}
+
+	deleted, err := s.repo.DeleteUserIdentity(ctx, s.db, repository.DeleteUserIdentityParams{UserID: user.ID, Provider: provider})
+	if err != nil {
+		s.logger.Error("oauth unlink - service - failed to delete identity: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if deleted == 0 {
+		return failure.NotFound("linked account not found")
+	}
+
+	return nil
+}
@@ -3,49 +3,157 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/jackc/pgx/v5"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/config"
+	authService "github.com/savioruz/goth/internal/domains/auth/service"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
-	"github.com/savioruz/goth/pkg/postgres"
-
-	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/savioruz/goth/internal/domains/user/dto"
-	"github.com/savioruz/goth/internal/domains/user/repository"
-	"github.com/savioruz/goth/pkg/jwt"
 	"github.com/savioruz/goth/pkg/oauth"
+	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/redis"
+	"golang.org/x/oauth2"
 )
 
 type OAuthService interface {
-	GetGoogleAuthURL() (dto.OauthGetURLResponse, error)
-	HandleGoogleCallback(ctx context.Context, code string) (res *dto.UserLoginResponse, err error)
+	GetAuthURL(ctx context.Context, provider, redirectURI string) (dto.OauthGetURLResponse, error)
+	GetLinkURL(ctx context.Context, provider, userID, redirectURI string) (dto.OauthGetURLResponse, error)
+	HandleCallback(ctx context.Context, provider, code, state string) (res dto.OAuthCallbackResponse, err error)
+	ConfirmLink(ctx context.Context, provider, userID string, req dto.OAuthLinkRequest) error
+	Exchange(ctx context.Context, req dto.OAuthExchangeRequest) (*dto.UserLoginResponse, error)
+	GetIdentities(ctx context.Context, userID string) ([]dto.UserIdentityResponse, error)
+	Unlink(ctx context.Context, userID, provider string) error
+	Providers() []string
+	DefaultRedirectURI() string
+}
+
+const (
+	cacheOAuthStateKey = "oauth:state:%s"
+	cacheOAuthCodeKey  = "oauth:code:%s"
+	cacheOAuthLinkKey  = "oauth:link:%s"
+
+	accountDeactivated = "account has been deactivated"
+)
+
+// oauthState is what the callback needs to finish a flow started by GetAuthURL or GetLinkURL.
+type oauthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	RedirectURI  string `json:"redirect_uri"`
+	// LinkUserID is set when a logged-in user links the provider instead of signing in.
+	LinkUserID string `json:"link_user_id,omitempty"`
+}
+
+// oauthGrant is what a one-time authorization code handed to the frontend can be exchanged for.
+type oauthGrant struct {
+	UserID string `json:"user_id"`
+}
+
+// oauthLinkGrant is the provider identity a one-time link code from the callback redirect attaches to
+// the user who started the link flow.
+type oauthLinkGrant struct {
+	UserID   string `json:"user_id"`
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
 }
 
 type oauthService struct {
-	db             postgres.PgxIface
-	repo           repository.Querier
-	googleProvider oauth.GoogleProviderIface
-	logger         logger.Interface
+	db          postgres.PgxIface
+	repo        repository.Querier
+	bookingRepo bookingRepository.Querier
+	providers   *oauth.Registry
+	authService authService.AuthService
+	cache       redis.IRedisCache
+	config      *config.Config
+	logger      logger.Interface
 }
 
-func New(db postgres.PgxIface, repo repository.Querier, googleProvider oauth.GoogleProviderIface, l logger.Interface) OAuthService {
+func New(
+	db postgres.PgxIface,
+	repo repository.Querier,
+	b bookingRepository.Querier,
+	providers *oauth.Registry,
+	a authService.AuthService,
+	cache redis.IRedisCache,
+	cfg *config.Config,
+	l logger.Interface,
+) OAuthService {
 	return &oauthService{
-		db:             db,
-		repo:           repo,
-		googleProvider: googleProvider,
-		logger:         l,
+		db:          db,
+		repo:        repo,
+		bookingRepo: b,
+		providers:   providers,
+		authService: a,
+		cache:       cache,
+		config:      cfg,
+		logger:      l,
 	}
 }
 
-func (s *oauthService) GetGoogleAuthURL() (res dto.OauthGetURLResponse, err error) {
+func (s *oauthService) Providers() []string {
+	return s.providers.Names()
+}
+
+// DefaultRedirectURI is the frontend a callback falls back to when the flow cannot be identified.
+func (s *oauthService) DefaultRedirectURI() string {
+	return s.config.OAuth.FrontendURL
+}
+
+// allowedRedirectURI reports whether the frontend may receive authorization codes.
+func (s *oauthService) allowedRedirectURI(uri string) bool {
+	return uri == s.config.OAuth.FrontendURL || slices.Contains(s.config.OAuth.AllowedFrontendURLs, uri)
+}
+
+func (s *oauthService) GetAuthURL(ctx context.Context, provider, redirectURI string) (dto.OauthGetURLResponse, error) {
+	return s.startFlow(ctx, provider, "", redirectURI)
+}
+
+// GetLinkURL starts a flow that adds the provider to the logged-in user's account.
+func (s *oauthService) GetLinkURL(ctx context.Context, provider, userID, redirectURI string) (dto.OauthGetURLResponse, error) {
+	return s.startFlow(ctx, provider, userID, redirectURI)
+}
+
+func (s *oauthService) startFlow(ctx context.Context, provider, linkUserID, redirectURI string) (res dto.OauthGetURLResponse, err error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return res, err
+	}
+
+	if redirectURI == "" {
+		redirectURI = s.DefaultRedirectURI()
+	}
+
+	if !s.allowedRedirectURI(redirectURI) {
+		s.logger.Error("oauth - service - redirect URI not allowed: %s", redirectURI)
+
+		return res, failure.BadRequestFromString("redirect_uri is not allowed")
+	}
+
 	state := helper.GenerateStateToken()
+	verifier := oauth2.GenerateVerifier()
+	flow := oauthState{Provider: provider, CodeVerifier: verifier, RedirectURI: redirectURI, LinkUserID: linkUserID}
+
+	err = s.cache.Save(ctx, fmt.Sprintf(cacheOAuthStateKey, state), flow, int(constant.OAuthStateExpiry.Seconds()))
+	if err != nil {
+		s.logger.Error("oauth - service - failed to store state: %w", err)
 
-	url := s.googleProvider.GetAuthURL(state)
-	if url == "" {
-		s.logger.Error("oauth - service - failed to get Google auth URL")
+		return res, failure.InternalError(err)
+	}
+
+	url, err := p.AuthURL(ctx, state, verifier)
+	if err != nil {
+		s.logger.Error("oauth - service - failed to get %s auth URL: %w", provider, err)
 
-		return res, failure.InternalError(errors.New("failed to get Google auth URL")) //nolint:err113
+		return res, failure.InternalError(err)
 	}
 
 	res = dto.OauthGetURLResponse{
@@ -56,125 +164,187 @@ func (s *oauthService) GetGoogleAuthURL() (res dto.OauthGetURLResponse, err erro
 	return res, nil
 }
 
-func (s *oauthService) HandleGoogleCallback(ctx context.Context, code string) (res *dto.UserLoginResponse, err error) {
-	token, err := s.googleProvider.Exchange(code)
+// HandleCallback signs the provider's user in and returns a one-time code for the frontend to exchange
+// for tokens, so tokens never appear in a redirect URL. For link flows the code is a link code instead,
+// the identity is only attached once the user who started the flow redeems it with ConfirmLink. Nothing
+// ties the callback to that user's browser, so linking here would let anyone who started a link flow
+// attach a victim's identity to their own account. RedirectURI is set as soon as the state is known, also on error.
+func (s *oauthService) HandleCallback(ctx context.Context, provider, code, state string) (res dto.OAuthCallbackResponse, err error) {
+	flow, err := s.consumeState(ctx, state)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to exchange code: %w", err)
+		return res, err
+	}
 
-		return nil, failure.InternalError(err)
+	res.RedirectURI = flow.RedirectURI
+
+	if flow.Provider != provider {
+		s.logger.Error("oauth callback - service - state was issued for %s, not %s", flow.Provider, provider)
+
+		return res, failure.BadRequestFromString("invalid or expired oauth state")
 	}
 
-	userInfo, err := s.googleProvider.GetUserInfo(token)
+	p, err := s.provider(provider)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to get user info: %w", err)
-
-		return nil, failure.InternalError(err)
+		return res, err
 	}
 
-	tx, err := s.db.Begin(ctx)
+	token, err := p.Exchange(ctx, code, flow.CodeVerifier)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to begin transaction: %w", err)
+		s.logger.Error("oauth callback - service - failed to exchange code: %w", err)
 
-		return nil, failure.InternalError(err)
+		return res, failure.InternalError(err)
 	}
 
-	defer func(tx pgx.Tx, ctx context.Context) {
-		err := tx.Rollback(ctx)
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			s.logger.Error("google callback - service - failed to rollback transaction: %w", err)
-		}
-	}(tx, ctx)
-
-	// Check if a user exists
-	user, err := s.repo.GetUserByEmail(ctx, tx, userInfo.Email)
+	userInfo, err := p.UserInfo(ctx, token)
 	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			s.logger.Error("google callback - service - failed to get user by email: %w", err)
+		s.logger.Error("oauth callback - service - failed to get user info: %w", err)
 
-			return nil, failure.InternalError(err)
+		if errors.Is(err, oauth.ErrEmailMissing) {
+			return res, failure.BadRequestFromString("the provider did not share an email address")
 		}
 
-		// User doesn't exist, create a new one
-		user, err = s.createGoogleUser(ctx, tx, userInfo)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// User exists, update their Google ID if not already set
-		user, err = s.updateExistingUserWithGoogleID(ctx, tx, user, userInfo)
-		if err != nil {
-			return nil, err
-		}
+		return res, failure.InternalError(err)
 	}
 
-	if err = tx.Commit(ctx); err != nil {
-		s.logger.Error("google callback - service - failed to commit transaction: %w", err)
+	if flow.LinkUserID != "" {
+		res.Linked = true
+		res.Code, err = s.createLinkGrant(ctx, oauthLinkGrant{
+			UserID:   flow.LinkUserID,
+			Provider: provider,
+			Subject:  userInfo.Subject,
+			Email:    userInfo.Email,
+		})
 
-		return nil, failure.InternalError(err)
+		return res, err
 	}
 
-	accessToken, err := jwt.GenerateAccessToken(user.ID.String(), user.Email, user.Level)
+	user, err := s.signIn(ctx, provider, userInfo)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to generate access token: %w", err)
+		return res, err
+	}
 
-		return nil, failure.InternalError(err)
+	res.Code, err = s.createGrant(ctx, user)
+	if err != nil {
+		return res, err
 	}
 
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID.String(), user.Email, user.Level)
+	return res, nil
+}
+
+func (s *oauthService) createGrant(ctx context.Context, user repository.User) (string, error) {
+	code := helper.GenerateStateToken()
+
+	err := s.cache.Save(ctx, fmt.Sprintf(cacheOAuthCodeKey, helper.HashToken(code)), oauthGrant{UserID: user.ID.String()}, int(constant.OAuthCodeExpiry.Seconds()))
 	if err != nil {
-		s.logger.Error("google callback - service - failed to generate refresh token: %w", err)
+		s.logger.Error("oauth callback - service - failed to store authorization code: %w", err)
 
-		return nil, failure.InternalError(err)
+		return "", failure.InternalError(err)
+	}
+
+	return code, nil
+}
+
+func (s *oauthService) createLinkGrant(ctx context.Context, grant oauthLinkGrant) (string, error) {
+	code := helper.GenerateStateToken()
+
+	err := s.cache.Save(ctx, fmt.Sprintf(cacheOAuthLinkKey, helper.HashToken(code)), grant, int(constant.OAuthCodeExpiry.Seconds()))
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to store link code: %w", err)
+
+		return "", failure.InternalError(err)
 	}
 
-	res = new(dto.UserLoginResponse).ToLoginResponse(accessToken, refreshToken)
+	return code, nil
+}
 
-	return res, nil
+// ConfirmLink attaches the identity behind a one-time link code from the callback redirect, provided the
+// logged-in user is the one who started the link flow.
+func (s *oauthService) ConfirmLink(ctx context.Context, provider, userID string, req dto.OAuthLinkRequest) error {
+	var grant oauthLinkGrant
+
+	err := s.cache.GetDel(ctx, fmt.Sprintf(cacheOAuthLinkKey, helper.HashToken(req.Code)), &grant)
+	if errors.Is(err, goredis.Nil) {
+		s.logger.Error("oauth link - service - unknown or expired link code")
+
+		return failure.Unauthorized("invalid or expired link code")
+	}
+
+	if err != nil {
+		s.logger.Error("oauth link - service - failed to get link code: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if grant.UserID != userID || grant.Provider != provider {
+		s.logger.Error("oauth link - service - link code of user %s redeemed by %s", grant.UserID, userID)
+
+		return failure.Forbidden("this link code was issued for another account")
+	}
+
+	return s.link(ctx, userID, provider, &oauth.UserInfo{Subject: grant.Subject, Email: grant.Email})
 }
 
-func (s *oauthService) createGoogleUser(ctx context.Context, tx pgx.Tx, userInfo *oauth.GoogleUserInfo) (repository.User, error) {
-	params := repository.CreateUserParams{
-		Email:        userInfo.Email,
-		Password:     pgtype.Text{Valid: false}, // No password for OAuth users
-		Level:        "1",
-		GoogleID:     pgtype.Text{String: userInfo.ID, Valid: true},
-		FullName:     pgtype.Text{String: userInfo.Name, Valid: true},
-		ProfileImage: pgtype.Text{String: userInfo.Picture, Valid: true},
-		IsVerified:   pgtype.Bool{Bool: userInfo.VerifiedEmail, Valid: true},
+// Exchange trades a one-time authorization code from the callback redirect for a token pair.
+func (s *oauthService) Exchange(ctx context.Context, req dto.OAuthExchangeRequest) (*dto.UserLoginResponse, error) {
+	var grant oauthGrant
+
+	err := s.cache.GetDel(ctx, fmt.Sprintf(cacheOAuthCodeKey, helper.HashToken(req.Code)), &grant)
+	if errors.Is(err, goredis.Nil) {
+		s.logger.Error("oauth exchange - service - unknown or expired code")
+
+		return nil, failure.Unauthorized("invalid or expired authorization code")
+	}
+
+	if err != nil {
+		s.logger.Error("oauth exchange - service - failed to get authorization code: %w", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(grant.UserID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("oauth exchange - service - user was deactivated")
+
+		return nil, failure.Forbidden(accountDeactivated)
 	}
 
-	user, err := s.repo.CreateUser(ctx, tx, params)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to create user: %w", err)
+		s.logger.Error("oauth exchange - service - failed to get user: %w", err)
 
-		return repository.User{}, failure.InternalError(err)
+		return nil, failure.InternalError(err)
 	}
 
-	return user, nil
+	return s.authService.CompleteLogin(ctx, user, req.ClientInfo)
 }
 
-func (s *oauthService) updateExistingUserWithGoogleID(ctx context.Context, tx pgx.Tx, user repository.User, userInfo *oauth.GoogleUserInfo) (repository.User, error) {
-	// Only update if Google ID is not already set
-	if user.GoogleID.Valid && user.GoogleID.String != "" {
-		return user, nil
+// consumeState accepts each state issued by startFlow exactly once and only before it expires,
+// so a callback cannot be forged or replayed.
+func (s *oauthService) consumeState(ctx context.Context, state string) (oauthState, error) {
+	var flow oauthState
+
+	err := s.cache.GetDel(ctx, fmt.Sprintf(cacheOAuthStateKey, state), &flow)
+	if errors.Is(err, goredis.Nil) {
+		s.logger.Error("oauth callback - service - unknown or expired state")
+
+		return flow, failure.BadRequestFromString("invalid or expired oauth state")
 	}
 
-	updateParams := repository.UpdateUserParams{
-		Email:        user.Email,
-		Password:     user.Password,
-		GoogleID:     pgtype.Text{String: userInfo.ID, Valid: true},
-		FullName:     user.FullName,
-		ProfileImage: pgtype.Text{String: userInfo.Picture, Valid: true},
-		IsVerified:   pgtype.Bool{Bool: true, Valid: true},
-		ID:           user.ID,
+	if err != nil {
+		s.logger.Error("oauth callback - service - failed to get state: %w", err)
+
+		return flow, failure.InternalError(err)
 	}
 
-	updatedUser, err := s.repo.UpdateUser(ctx, tx, updateParams)
+	return flow, nil
+}
+
+func (s *oauthService) provider(name string) (oauth.Provider, error) {
+	p, err := s.providers.Get(name)
 	if err != nil {
-		s.logger.Error("google callback - service - failed to update user: %w", err)
+		s.logger.Error("oauth - service - unknown provider: %s", name)
 
-		return repository.User{}, failure.InternalError(err)
+		return nil, failure.NotFound("oauth provider not found")
 	}
 
-	return updatedUser, nil
+	return p, nil
 }
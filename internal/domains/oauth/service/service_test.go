@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/config"
+	authMock "github.com/savioruz/goth/internal/domains/auth/mock"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/mock"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/failure"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	"github.com/savioruz/goth/pkg/oauth"
+	oauthMock "github.com/savioruz/goth/pkg/oauth/mock"
+	redisMock "github.com/savioruz/goth/pkg/redis/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/oauth2"
+)
+
+const provider = "google"
+
+type testDeps struct {
+	querier  *mock.MockQuerier
+	pgx      pgxmock.PgxPoolIface
+	provider *oauthMock.MockProvider
+	auth     *authMock.MockAuthService
+	cache    *redisMock.MockIRedisCache
+}
+
+func setup(t *testing.T) (OAuthService, testDeps) {
+	ctrl := gomock.NewController(t)
+
+	mockPgx, _ := pgxmock.NewPool()
+	deps := testDeps{
+		querier:  mock.NewMockQuerier(ctrl),
+		pgx:      mockPgx,
+		provider: oauthMock.NewMockProvider(ctrl),
+		auth:     authMock.NewMockAuthService(ctrl),
+		cache:    redisMock.NewMockIRedisCache(ctrl),
+	}
+
+	deps.provider.EXPECT().Name().Return(provider).AnyTimes()
+
+	mockLogger := log.NewMockInterface(ctrl)
+	mockLogger.EXPECT().Error(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	cfg := &config.Config{}
+	cfg.OAuth.FrontendURL = "http://localhost:5173"
+
+	service := New(mockPgx, deps.querier, nil, oauth.NewRegistry(deps.provider), deps.auth, deps.cache, cfg, mockLogger)
+
+	return service, deps
+}
+
+// expectGetDel hands out the value stored under a one-time key.
+func expectGetDel[T any](cache *redisMock.MockIRedisCache, stored T) *gomock.Call {
+	return cache.EXPECT().
+		GetDel(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, value any) error {
+			*value.(*T) = stored
+
+			return nil
+		})
+}
+
+// expectConsumed reports the one-time key gone after it was handed out once.
+func expectConsumed(cache *redisMock.MockIRedisCache, first *gomock.Call) {
+	cache.EXPECT().GetDel(gomock.Any(), gomock.Any(), gomock.Any()).Return(goredis.Nil).After(first)
+}
+
+func TestOAuthService_HandleCallback(t *testing.T) {
+	ctx := context.Background()
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	flow := oauthState{Provider: provider, CodeVerifier: "verifier", RedirectURI: "http://localhost:5173"}
+	token := &oauth2.Token{AccessToken: "access"}
+
+	t.Run("error: unknown state", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.cache.EXPECT().GetDel(gomock.Any(), gomock.Any(), gomock.Any()).Return(goredis.Nil)
+
+		res, err := service.HandleCallback(ctx, provider, "code", "state")
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		assert.Equal(t, "invalid or expired oauth state", err.Error())
+		assert.Empty(t, res.Code)
+	})
+
+	t.Run("error: consumed state is rejected", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectConsumed(deps.cache, expectGetDel(deps.cache, flow))
+		deps.provider.EXPECT().Exchange(gomock.Any(), "code", flow.CodeVerifier).Return(token, nil)
+		deps.provider.EXPECT().UserInfo(gomock.Any(), token).Return(&oauth.UserInfo{Subject: "subject", Email: "test@gmail.com", EmailVerified: true}, nil)
+		deps.pgx.ExpectBegin()
+		deps.querier.EXPECT().
+			GetUserIdentity(gomock.Any(), gomock.Any(), repository.GetUserIdentityParams{Provider: provider, Subject: "subject"}).
+			Return(repository.UserIdentity{UserID: userID}, nil)
+		deps.querier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID}, nil)
+		deps.pgx.ExpectCommit()
+		deps.pgx.ExpectRollback()
+		deps.cache.EXPECT().Save(gomock.Any(), gomock.Any(), oauthGrant{UserID: userID.String()}, gomock.Any()).Return(nil)
+
+		res, err := service.HandleCallback(ctx, provider, "code", "state")
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Code)
+		assert.Equal(t, flow.RedirectURI, res.RedirectURI)
+
+		res, err = service.HandleCallback(ctx, provider, "code", "state")
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		assert.Empty(t, res.Code)
+		assert.NoError(t, deps.pgx.ExpectationsWereMet())
+	})
+
+	t.Run("error: unverified email of an existing account", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectGetDel(deps.cache, flow)
+		deps.provider.EXPECT().Exchange(gomock.Any(), "code", flow.CodeVerifier).Return(token, nil)
+		deps.provider.EXPECT().UserInfo(gomock.Any(), token).Return(&oauth.UserInfo{Subject: "subject", Email: "test@gmail.com"}, nil)
+		deps.pgx.ExpectBegin()
+		deps.querier.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.UserIdentity{}, pgx.ErrNoRows)
+		deps.querier.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any(), "test@gmail.com").Return(repository.User{ID: userID, Email: "test@gmail.com"}, nil)
+		deps.pgx.ExpectRollback()
+
+		res, err := service.HandleCallback(ctx, provider, "code", "state")
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+		assert.Empty(t, res.Code)
+		assert.Equal(t, flow.RedirectURI, res.RedirectURI)
+		assert.NoError(t, deps.pgx.ExpectationsWereMet())
+	})
+}
+
+func TestOAuthService_Exchange(t *testing.T) {
+	ctx := context.Background()
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	req := dto.OAuthExchangeRequest{Code: "code"}
+
+	t.Run("error: code cannot be replayed", func(t *testing.T) {
+		service, deps := setup(t)
+
+		user := repository.User{ID: userID}
+
+		expectConsumed(deps.cache, expectGetDel(deps.cache, oauthGrant{UserID: userID.String()}))
+		deps.querier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		deps.auth.EXPECT().CompleteLogin(gomock.Any(), user, req.ClientInfo).Return(&dto.UserLoginResponse{AccessToken: "access"}, nil)
+
+		res, err := service.Exchange(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access", res.AccessToken)
+
+		res, err = service.Exchange(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, http.StatusUnauthorized, failure.GetCode(err))
+		assert.Equal(t, "invalid or expired authorization code", err.Error())
+	})
+}
+
+func TestOAuthService_ConfirmLink(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New().String()
+	grant := oauthLinkGrant{UserID: userID, Provider: provider, Subject: "subject", Email: "test@gmail.com"}
+
+	t.Run("error: link code of another user", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectGetDel(deps.cache, grant)
+
+		err := service.ConfirmLink(ctx, provider, uuid.New().String(), dto.OAuthLinkRequest{Code: "code"})
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectGetDel(deps.cache, grant)
+		deps.querier.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.UserIdentity{}, pgx.ErrNoRows)
+		deps.querier.EXPECT().GetUserIdentitiesByUserID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		deps.querier.EXPECT().
+			CreateUserIdentity(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CreateUserIdentityParams) (repository.UserIdentity, error) {
+				assert.Equal(t, userID, arg.UserID.String())
+				assert.Equal(t, "subject", arg.Subject)
+
+				return repository.UserIdentity{}, nil
+			})
+
+		err := service.ConfirmLink(ctx, provider, userID, dto.OAuthLinkRequest{Code: "code"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestOAuthService_Unlink(t *testing.T) {
+	ctx := context.Background()
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("error: last way to sign in", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.querier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID}, nil)
+		deps.querier.EXPECT().
+			GetUserIdentitiesByUserID(gomock.Any(), gomock.Any(), userID).
+			Return([]repository.UserIdentity{{UserID: userID, Provider: provider}}, nil)
+
+		err := service.Unlink(ctx, userID.String(), provider)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+		assert.Equal(t, "cannot unlink the only way to sign in, set a password first", err.Error())
+	})
+
+	t.Run("success: password remains", func(t *testing.T) {
+		service, deps := setup(t)
+
+		user := repository.User{ID: userID, Password: pgtype.Text{String: "hash", Valid: true}}
+
+		deps.querier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		deps.querier.EXPECT().
+			GetUserIdentitiesByUserID(gomock.Any(), gomock.Any(), userID).
+			Return([]repository.UserIdentity{{UserID: userID, Provider: provider}}, nil)
+		deps.querier.EXPECT().
+			DeleteUserIdentity(gomock.Any(), gomock.Any(), repository.DeleteUserIdentityParams{UserID: userID, Provider: provider}).
+			Return(int64(1), nil)
+
+		err := service.Unlink(ctx, userID.String(), provider)
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+package dto
+
+type CreateOrganizationRequest struct {
+	Slug string `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123" example:"arena"`
+	Name string `json:"name" validate:"required,max=255" example:"Arena Sports"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=255" example:"Arena Sports"`
+}
+
+// XenditCredentialsRequest sets the Xendit account payments of the organisation go to. Leaving
+// both empty falls back to the platform account.
+type XenditCredentialsRequest struct {
+	APIKey        string `json:"api_key" validate:"required_with=CallbackToken"`
+	CallbackToken string `json:"callback_token" validate:"required_with=APIKey"`
+}
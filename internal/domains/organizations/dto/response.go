@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"github.com/savioruz/goth/internal/domains/organizations/repository"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+type OrganizationResponse struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// HasXenditCredentials reports whether the organisation uses its own Xendit account
+	HasXenditCredentials bool   `json:"has_xendit_credentials"`
+	CreatedAt            string `json:"created_at"`
+	UpdatedAt            string `json:"updated_at"`
+}
+
+func (o OrganizationResponse) FromModel(model repository.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:                   model.ID.String(),
+		Slug:                 model.Slug,
+		Name:                 model.Name,
+		HasXenditCredentials: model.XenditApiKey.Valid,
+		CreatedAt:            model.CreatedAt.Time.Format(constant.FullDateFormat),
+		UpdatedAt:            model.UpdatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
+
+type PaginatedOrganizationResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+	TotalItems    int                    `json:"total_items"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+func (o *PaginatedOrganizationResponse) FromModel(organizations []repository.Organization, totalItems, limit int) {
+	o.TotalItems = totalItems
+	o.TotalPages = helper.CalculateTotalPages(totalItems, limit)
+
+	o.Organizations = make([]OrganizationResponse, len(organizations))
+	for i, organization := range organizations {
+		o.Organizations[i] = OrganizationResponse{}.FromModel(organization)
+	}
+}
+
+type MemberResponse struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name,omitempty"`
+	Role     string `json:"role"`
+}
+
+func (m MemberResponse) FromModel(model userRepository.User) MemberResponse {
+	return MemberResponse{
+		UserID:   model.ID.String(),
+		Email:    model.Email,
+		FullName: model.FullName.String,
+		Role:     model.Role,
+	}
+}
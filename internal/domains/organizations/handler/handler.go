@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/internal/domains/organizations/dto"
+	"github.com/savioruz/goth/internal/domains/organizations/service"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/logger"
+)
+
+type Handler struct {
+	service   service.OrganizationService
+	logger    logger.Interface
+	validator *validator.Validate
+}
+
+func New(s service.OrganizationService, l logger.Interface, v *validator.Validate) *Handler {
+	return &Handler{
+		service:   s,
+		logger:    l,
+		validator: v,
+	}
+}
+
+const (
+	identifier = "http - organization - %s"
+
+	routePath = "/organizations"
+)
+
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
+	organizations := r.Group(routePath, authn.Jwt(), middleware.RequirePermission(constant.PermissionOrganizationsManage))
+
+	organizations.Post("/", h.Create)
+	organizations.Get("/", h.GetAll)
+	organizations.Get("/:id", h.Get)
+	organizations.Patch("/:id", h.Update)
+	organizations.Put("/:id/xendit", h.SetXenditCredentials)
+	organizations.Get("/:id/members", h.GetMembers)
+	organizations.Put("/:id/members/:user_id", h.AddMember)
+	organizations.Delete("/:id/members/:user_id", h.RemoveMember)
+}
+
+// Create Organization godoc
+// @Summary Create organization (requires organizations:manage)
+// @Description Create an organization, reachable at its slug as subdomain or X-Tenant header
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body dto.CreateOrganizationRequest true "Organization create request"
+// @Success 201 {object} response.Data[dto.OrganizationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/ [post]
+// @Security BearerAuth
+func (h *Handler) Create(ctx *fiber.Ctx) error {
+	var req dto.CreateOrganizationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "create - body parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "create - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.Create(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error(identifier, "create - failed to create organization: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusCreated, res)
+}
+
+// GetAll Organizations godoc
+// @Summary Get all organizations (requires organizations:manage)
+// @Description Get all organizations, filtered by name or slug
+// @Tags organizations
+// @Produce json
+// @Param request query gdto.PaginationRequest false "Pagination request"
+// @Success 200 {object} response.Data[dto.PaginatedOrganizationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/ [get]
+// @Security BearerAuth
+func (h *Handler) GetAll(ctx *fiber.Ctx) error {
+	var req gdto.PaginationRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - query parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "get all - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetAll(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error(identifier, "get all - failed to get organizations: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// Get Organization godoc
+// @Summary Get organization by id (requires organizations:manage)
+// @Description Get organization by id
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} response.Data[dto.OrganizationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id} [get]
+// @Security BearerAuth
+func (h *Handler) Get(ctx *fiber.Ctx) error {
+	id, err := h.idParam(ctx, "get")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.Get(ctx.UserContext(), id)
+	if err != nil {
+		h.logger.Error(identifier, "get - failed to get organization: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// Update Organization godoc
+// @Summary Update organization (requires organizations:manage)
+// @Description Rename an organization. Its slug cannot change
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param organization body dto.UpdateOrganizationRequest true "Organization update request"
+// @Success 200 {object} response.Data[dto.OrganizationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id} [patch]
+// @Security BearerAuth
+func (h *Handler) Update(ctx *fiber.Ctx) error {
+	id, err := h.idParam(ctx, "update")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	var req dto.UpdateOrganizationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "update - body parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "update - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.Update(ctx.UserContext(), id, req)
+	if err != nil {
+		h.logger.Error(identifier, "update - failed to update organization: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// SetXenditCredentials godoc
+// @Summary Set organization Xendit credentials (requires organizations:manage)
+// @Description Route the organization's payments to its own Xendit account. Credentials are stored encrypted and never returned; leave both empty to use the platform account again
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param credentials body dto.XenditCredentialsRequest true "Xendit credentials"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id}/xendit [put]
+// @Security BearerAuth
+func (h *Handler) SetXenditCredentials(ctx *fiber.Ctx) error {
+	id, err := h.idParam(ctx, "set xendit credentials")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	var req dto.XenditCredentialsRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "set xendit credentials - body parsing error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		err = failure.BadRequestFromString(err.Error())
+
+		h.logger.Error(identifier, "set xendit credentials - validate error: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	if err = h.service.SetXenditCredentials(ctx.UserContext(), id, req); err != nil {
+		h.logger.Error(identifier, "set xendit credentials - failed to set credentials: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "xendit credentials updated")
+}
+
+// GetMembers godoc
+// @Summary Get organization members (requires organizations:manage)
+// @Description List the staff bound to an organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} response.Data[[]dto.MemberResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id}/members [get]
+// @Security BearerAuth
+func (h *Handler) GetMembers(ctx *fiber.Ctx) error {
+	id, err := h.idParam(ctx, "get members")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.GetMembers(ctx.UserContext(), id)
+	if err != nil {
+		h.logger.Error(identifier, "get members - failed to get members: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// AddMember godoc
+// @Summary Add organization member (requires organizations:manage)
+// @Description Bind a user to an organization, their permissions then only hold within it
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id}/members/{user_id} [put]
+// @Security BearerAuth
+func (h *Handler) AddMember(ctx *fiber.Ctx) error {
+	id, userID, err := h.memberParams(ctx, "add member")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err = h.service.AddMember(ctx.UserContext(), id, userID); err != nil {
+		h.logger.Error(identifier, "add member - failed to add member: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "member added")
+}
+
+// RemoveMember godoc
+// @Summary Remove organization member (requires organizations:manage)
+// @Description Unbind a user from an organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /organizations/{id}/members/{user_id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoveMember(ctx *fiber.Ctx) error {
+	id, userID, err := h.memberParams(ctx, "remove member")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err = h.service.RemoveMember(ctx.UserContext(), id, userID); err != nil {
+		h.logger.Error(identifier, "remove member - failed to remove member: %w", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "member removed")
+}
+
+func (h *Handler) idParam(ctx *fiber.Ctx, op string) (string, error) {
+	id := ctx.Params(constant.RequestParamID)
+	if err := h.validator.Var(id, constant.RequestValidateUUID); err != nil {
+		h.logger.Error(identifier, op+" - invalid organization id format: %w", err)
+
+		return "", failure.BadRequestFromString("invalid organization id format")
+	}
+
+	return id, nil
+}
+
+func (h *Handler) memberParams(ctx *fiber.Ctx, op string) (id, userID string, err error) {
+	if id, err = h.idParam(ctx, op); err != nil {
+		return "", "", err
+	}
+
+	userID = ctx.Params("user_id")
+	if err = h.validator.Var(userID, constant.RequestValidateUUID); err != nil {
+		h.logger.Error(identifier, op+" - invalid user id format: %w", err)
+
+		return "", "", failure.BadRequestFromString("invalid user id format")
+	}
+
+	return id, userID, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/organizations/dto"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+func (s *organizationService) GetMembers(ctx context.Context, id string) (res []dto.MemberResponse, err error) {
+	organization, err := s.get(ctx, "get members", id)
+	if err != nil {
+		return res, err
+	}
+
+	users, err := s.userRepo.GetUsersByOrganizationID(ctx, s.db, organization.ID)
+	if err != nil {
+		s.logger.Error(identifier, "get members - failed to get users of organization: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = make([]dto.MemberResponse, len(users))
+	for i, user := range users {
+		res[i] = dto.MemberResponse{}.FromModel(user)
+	}
+
+	return res, nil
+}
+
+// AddMember binds the user to the organisation, moving them out of any other one. Their tokens
+// are revoked so the next refresh carries the organisation.
+func (s *organizationService) AddMember(ctx context.Context, id, userID string) (err error) {
+	organization, err := s.get(ctx, "add member", id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.setOrganization(ctx, "add member", userID, organization.ID); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionOrganizationMemberAdd,
+		EntityType: constant.AuditEntityOrganization,
+		EntityID:   id,
+		After:      map[string]string{"user_id": userID},
+	})
+
+	return nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, id, userID string) (err error) {
+	user, err := s.userRepo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error(identifier, "remove member - failed to get user: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err != nil || user.OrganizationID != helper.PgUUID(id) {
+		s.logger.Error(identifier, "remove member - user %s is not a member of organization %s", userID, id)
+
+		return failure.NotFound("membership not found")
+	}
+
+	if err = s.setOrganization(ctx, "remove member", userID, pgtype.UUID{}); err != nil {
+		return err
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionOrganizationMemberRemove,
+		EntityType: constant.AuditEntityOrganization,
+		EntityID:   id,
+		Before:     map[string]string{"user_id": userID},
+	})
+
+	return nil
+}
+
+func (s *organizationService) setOrganization(ctx context.Context, op, userID string, organizationID pgtype.UUID) error {
+	updated, err := s.userRepo.SetUserOrganization(ctx, s.db, userRepository.SetUserOrganizationParams{
+		ID:             helper.PgUUID(userID),
+		OrganizationID: organizationID,
+	})
+	if err != nil {
+		s.logger.Error(identifier, op+" - failed to set organization of user: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	if updated == 0 {
+		s.logger.Error(identifier, op+" - user %s - not found", userID)
+
+		return failure.NotFound(fmt.Sprintf("user %s - not found", userID))
+	}
+
+	if err = s.revoker.RevokeUser(ctx, userID); err != nil {
+		s.logger.Error(identifier, op+" - failed to revoke user tokens: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
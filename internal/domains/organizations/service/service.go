@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/domains/organizations/dto"
+	"github.com/savioruz/goth/internal/domains/organizations/repository"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/secret"
+	"github.com/savioruz/goth/pkg/session"
+)
+
+type OrganizationService interface {
+	Resolve(ctx context.Context, slug string) (pgtype.UUID, error)
+	Create(ctx context.Context, req dto.CreateOrganizationRequest) (res dto.OrganizationResponse, err error)
+	Get(ctx context.Context, id string) (res dto.OrganizationResponse, err error)
+	GetAll(ctx context.Context, req gdto.PaginationRequest) (res dto.PaginatedOrganizationResponse, err error)
+	Update(ctx context.Context, id string, req dto.UpdateOrganizationRequest) (res dto.OrganizationResponse, err error)
+	SetXenditCredentials(ctx context.Context, id string, req dto.XenditCredentialsRequest) (err error)
+	GetMembers(ctx context.Context, id string) (res []dto.MemberResponse, err error)
+	AddMember(ctx context.Context, id, userID string) (err error)
+	RemoveMember(ctx context.Context, id, userID string) (err error)
+}
+
+type organizationService struct {
+	db       postgres.PgxIface
+	repo     repository.Querier
+	userRepo userRepository.Querier
+	box      *secret.Box
+	revoker  session.Revoker
+	cache    redis.IRedisCache
+	cfg      *config.Config
+	logger   logger.Interface
+	auditor  audit.Recorder
+}
+
+func New(db postgres.PgxIface, repo repository.Querier, u userRepository.Querier, box *secret.Box, revoker session.Revoker, cache redis.IRedisCache, cfg *config.Config, l logger.Interface, auditor audit.Recorder) OrganizationService {
+	return &organizationService{
+		db:       db,
+		repo:     repo,
+		userRepo: u,
+		box:      box,
+		revoker:  revoker,
+		cache:    cache,
+		cfg:      cfg,
+		logger:   l,
+		auditor:  auditor,
+	}
+}
+
+const (
+	cacheOrganizationSlugKey = "organization:slug"
+
+	identifier = "service - organization - %s"
+)
+
+// Resolve looks up the organisation of a request. Slugs never change, so the lookup is cached.
+func (s *organizationService) Resolve(ctx context.Context, slug string) (res pgtype.UUID, err error) {
+	cacheKey := helper.BuildCacheKey(cacheOrganizationSlugKey, slug)
+
+	var cacheRes string
+	if err = s.cache.Get(ctx, cacheKey, &cacheRes); err == nil {
+		return helper.PgUUID(cacheRes), nil
+	}
+
+	organization, err := s.repo.GetOrganizationBySlug(ctx, s.db, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Info(identifier, "resolve - organization %s - not found", slug)
+
+			return res, failure.NotFound("organization not found")
+		}
+
+		s.logger.Error(identifier, "resolve - failed to get organization by slug: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	go func() {
+		err := s.cache.Save(context.WithoutCancel(ctx), cacheKey, organization.ID.String(), s.cfg.Cache.Duration)
+		if err != nil {
+			s.logger.Error(identifier, "resolve - failed to set cache: %w", err)
+		}
+	}()
+
+	return organization.ID, nil
+}
+
+func (s *organizationService) Create(ctx context.Context, req dto.CreateOrganizationRequest) (res dto.OrganizationResponse, err error) {
+	organization, err := s.repo.CreateOrganization(ctx, s.db, repository.CreateOrganizationParams{
+		Slug: req.Slug,
+		Name: req.Name,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error(identifier, "create - slug %s already taken", req.Slug)
+
+			return res, failure.Conflict("organization slug already taken")
+		}
+
+		s.logger.Error(identifier, "create - failed to create organization: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	return res.FromModel(organization), nil
+}
+
+func (s *organizationService) Get(ctx context.Context, id string) (res dto.OrganizationResponse, err error) {
+	organization, err := s.get(ctx, "get", id)
+	if err != nil {
+		return res, err
+	}
+
+	return res.FromModel(organization), nil
+}
+
+func (s *organizationService) GetAll(ctx context.Context, req gdto.PaginationRequest) (res dto.PaginatedOrganizationResponse, err error) {
+	page, limit := helper.DefaultPagination(req.Page, req.Limit)
+
+	totalItems, err := s.repo.CountOrganizations(ctx, s.db, req.Filter)
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to count organizations: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	organizations, err := s.repo.GetOrganizations(ctx, s.db, repository.GetOrganizationsParams{
+		Column1: req.Filter,
+		Limit:   int32(limit),
+		Offset:  int32(helper.CalculateOffset(page, limit)),
+	})
+	if err != nil {
+		s.logger.Error(identifier, "get all - failed to get organizations: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res.FromModel(organizations, int(totalItems), limit)
+
+	return res, nil
+}
+
+func (s *organizationService) Update(ctx context.Context, id string, req dto.UpdateOrganizationRequest) (res dto.OrganizationResponse, err error) {
+	before, err := s.get(ctx, "update", id)
+	if err != nil {
+		return res, err
+	}
+
+	organization, err := s.repo.UpdateOrganization(ctx, s.db, repository.UpdateOrganizationParams{
+		ID:   helper.PgUUID(id),
+		Name: req.Name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, "update - organization %s - not found", id)
+
+			return res, failure.NotFound(fmt.Sprintf("organization %s - not found", id))
+		}
+
+		s.logger.Error(identifier, "update - failed to update organization: %w", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = res.FromModel(organization)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionOrganizationUpdate,
+		EntityType: constant.AuditEntityOrganization,
+		EntityID:   id,
+		Before:     dto.OrganizationResponse{}.FromModel(before),
+		After:      res,
+	})
+
+	return res, nil
+}
+
+// SetXenditCredentials stores the organisation's Xendit credentials sealed, or clears them so its
+// payments go to the platform account again.
+func (s *organizationService) SetXenditCredentials(ctx context.Context, id string, req dto.XenditCredentialsRequest) (err error) {
+	var apiKey, callbackToken pgtype.Text
+
+	if req.APIKey != "" {
+		if apiKey, err = s.seal(req.APIKey); err != nil {
+			return err
+		}
+
+		if callbackToken, err = s.seal(req.CallbackToken); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.repo.SetOrganizationXenditCredentials(ctx, s.db, repository.SetOrganizationXenditCredentialsParams{
+		ID:                  helper.PgUUID(id),
+		XenditApiKey:        apiKey,
+		XenditCallbackToken: callbackToken,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, "set xendit credentials - organization %s - not found", id)
+
+			return failure.NotFound(fmt.Sprintf("organization %s - not found", id))
+		}
+
+		s.logger.Error(identifier, "set xendit credentials - failed to store credentials: %w", err)
+
+		return failure.InternalError(err)
+	}
+
+	// Only whether the organisation has its own credentials is recorded, never their values
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionOrganizationXenditUpdate,
+		EntityType: constant.AuditEntityOrganization,
+		EntityID:   id,
+		After:      map[string]bool{"xendit_configured": req.APIKey != ""},
+	})
+
+	return nil
+}
+
+func (s *organizationService) seal(value string) (pgtype.Text, error) {
+	sealed, err := s.box.Seal(value)
+	if err != nil {
+		s.logger.Error(identifier, "seal - failed to seal credentials: %w", err)
+
+		return pgtype.Text{}, failure.InternalError(err)
+	}
+
+	return helper.PgString(sealed), nil
+}
+
+func (s *organizationService) get(ctx context.Context, op, id string) (repository.Organization, error) {
+	organization, err := s.repo.GetOrganizationByID(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, op+" - organization %s - not found", id)
+
+			return organization, failure.NotFound(fmt.Sprintf("organization %s - not found", id))
+		}
+
+		s.logger.Error(identifier, op+" - failed to get organization: %w", err)
+
+		return organization, failure.InternalError(err)
+	}
+
+	return organization, nil
+}
+
+// XenditCredentials returns the Xendit configuration payments of the organisation use: its own
+// credentials when it has them, the platform's otherwise.
+func XenditCredentials(ctx context.Context, db repository.DBTX, repo repository.Querier, box *secret.Box, cfg config.Xendit, organizationID pgtype.UUID) (config.Xendit, error) {
+	organization, err := repo.GetOrganizationByID(ctx, db, organizationID)
+	if err != nil {
+		return cfg, err
+	}
+
+	if !organization.XenditApiKey.Valid {
+		return cfg, nil
+	}
+
+	if cfg.APIKey, err = box.Open(organization.XenditApiKey.String); err != nil {
+		return cfg, err
+	}
+
+	if cfg.CallbackToken, err = box.Open(organization.XenditCallbackToken.String); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/config"
+	"github.com/savioruz/goth/internal/domains/organizations/mock"
+	"github.com/savioruz/goth/internal/domains/organizations/repository"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	redis "github.com/savioruz/goth/pkg/redis/mock"
+	"github.com/savioruz/goth/pkg/secret"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestOrganizationService_Resolve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	cfg := &config.Config{Cache: config.Cache{Duration: 300}}
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRedis := redis.NewMockIRedisCache(ctrl)
+	mockLogger := log.NewMockInterface(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, nil, mockRedis, cfg, mockLogger, nil)
+
+	organizationID := uuid.New()
+
+	t.Run("success: cache hit", func(t *testing.T) {
+		mockRedis.EXPECT().
+			Get(gomock.Any(), helper.BuildCacheKey(cacheOrganizationSlugKey, "arena"), gomock.Any()).
+			SetArg(2, organizationID.String()).
+			Return(nil)
+
+		id, err := service.Resolve(ctx, "arena")
+
+		assert.NoError(t, err)
+		assert.Equal(t, pgtype.UUID{Bytes: organizationID, Valid: true}, id)
+	})
+
+	t.Run("error: unknown organization", func(t *testing.T) {
+		mockRedis.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		mockQuerier.EXPECT().GetOrganizationBySlug(gomock.Any(), gomock.Any(), "unknown").Return(repository.Organization{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := service.Resolve(ctx, "unknown")
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
+
+func TestXenditCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	box, _ := secret.New(testKey)
+
+	platform := config.Xendit{APIKey: "platform-key", CallbackToken: "platform-token", SuccessURL: "https://example.com/success"}
+	organizationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("success: falls back to the platform account", func(t *testing.T) {
+		mockQuerier.EXPECT().GetOrganizationByID(gomock.Any(), gomock.Any(), organizationID).Return(repository.Organization{ID: organizationID}, nil)
+
+		credentials, err := XenditCredentials(ctx, mockPgx, mockQuerier, box, platform, organizationID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, platform, credentials)
+	})
+
+	t.Run("success: uses the organization's own account", func(t *testing.T) {
+		apiKey, _ := box.Seal("own-key")
+		callbackToken, _ := box.Seal("own-token")
+
+		mockQuerier.EXPECT().GetOrganizationByID(gomock.Any(), gomock.Any(), organizationID).Return(repository.Organization{
+			ID:                  organizationID,
+			XenditApiKey:        pgtype.Text{String: apiKey, Valid: true},
+			XenditCallbackToken: pgtype.Text{String: callbackToken, Valid: true},
+		}, nil)
+
+		credentials, err := XenditCredentials(ctx, mockPgx, mockQuerier, box, platform, organizationID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "own-key", credentials.APIKey)
+		assert.Equal(t, "own-token", credentials.CallbackToken)
+		assert.Equal(t, platform.SuccessURL, credentials.SuccessURL)
+	})
+}
@@ -1,11 +1,104 @@
 package dto
 
-import "github.com/savioruz/goth/pkg/gdto"
+import (
+	"fmt"
+
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/money"
+)
 
 type CreatePaymentInvoice struct {
-	OrderID    string `json:"order_id" validate:"required,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Amount     int64  `json:"amount" validate:"required,numeric,min=10000" example:"10000"`
-	PayerEmail string `json:"payer_email" validate:"required,email" example:"mail@example.com"`
+	OrderID       string        `json:"order_id" validate:"required,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Amount        money.Money   `json:"amount"`
+	PayerEmail    string        `json:"payer_email" validate:"required,email" example:"mail@example.com"`
+	CustomerName  string        `json:"customer_name" validate:"omitempty,max=255" example:"John Doe"`
+	CustomerPhone string        `json:"customer_phone" validate:"omitempty,e164" example:"+628123456789"`
+	Description   string        `json:"description" validate:"omitempty,max=1000" example:"Booking Field A on 2006-01-02 15:04-17:04"`
+	Items         []InvoiceItem `json:"items" validate:"omitempty,dive"`
+	Fees          []InvoiceFee  `json:"fees" validate:"omitempty,dive"`
+	TaxRate       float64       `json:"tax_rate" validate:"omitempty,min=0,max=100" example:"11"`
+	TaxInclusive  bool          `json:"tax_inclusive" example:"false"`
+	TaxAmount     money.Money   `json:"tax_amount"`
+	Channel       string        `json:"channel" validate:"omitempty,oneof=INVOICE QRIS VIRTUAL_ACCOUNT EWALLET" example:"QRIS"`
+	ChannelCode   string        `json:"channel_code" validate:"required_if=Channel VIRTUAL_ACCOUNT,required_if=Channel EWALLET" example:"BCA"`
+	MobileNumber  string        `json:"mobile_number" validate:"omitempty,e164" example:"+628123456789"`
+}
+
+// ItemsTotal sums the line items and fees before any exclusive tax is added.
+func (r CreatePaymentInvoice) ItemsTotal() money.Money {
+	total := money.Zero(r.Amount.Currency)
+
+	for _, item := range r.Items {
+		total = total.Add(item.Amount())
+	}
+
+	for _, fee := range r.Fees {
+		total = total.Add(fee.Value)
+	}
+
+	return total
+}
+
+// Total adds exclusive tax to ItemsTotal, which must match Amount when items are given.
+func (r CreatePaymentInvoice) Total() money.Money {
+	if r.TaxInclusive {
+		return r.ItemsTotal()
+	}
+
+	return r.ItemsTotal().Add(r.TaxAmount)
+}
+
+// Subtotal is the amount before tax.
+func (r CreatePaymentInvoice) Subtotal() money.Money {
+	return r.Amount.Sub(r.TaxAmount)
+}
+
+// SameCurrency reports whether every item, fee and the tax are billed in the currency of Amount.
+func (r CreatePaymentInvoice) SameCurrency() bool {
+	amounts := []money.Money{r.TaxAmount}
+
+	for _, item := range r.Items {
+		amounts = append(amounts, item.UnitPrice, item.Discount)
+	}
+
+	for _, fee := range r.Fees {
+		amounts = append(amounts, fee.Value)
+	}
+
+	return money.SameCurrency(r.Amount.Currency, amounts...)
+}
+
+// InvoiceItem is a single billed line of an invoice, e.g. the hours booked on a field.
+type InvoiceItem struct {
+	ReferenceID string      `json:"reference_id" validate:"omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name        string      `json:"name" validate:"required" example:"Field A"`
+	Category    string      `json:"category" validate:"required" example:"futsal"`
+	Date        string      `json:"date" validate:"omitempty,datetime=2006-01-02" example:"2006-01-02"`
+	StartTime   string      `json:"start_time" validate:"omitempty,datetime=15:04" example:"15:04"`
+	Hours       int         `json:"hours" validate:"required,min=1" example:"2"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Discount    money.Money `json:"discount"`
+}
+
+func (i InvoiceItem) Amount() money.Money {
+	return i.UnitPrice.Mul(int64(i.Hours)).Sub(i.Discount)
+}
+
+// Summary describes when the item takes place, e.g. "2006-01-02 15:04, 2 hour(s)".
+func (i InvoiceItem) Summary() string {
+	switch {
+	case i.Date != "" && i.StartTime != "":
+		return fmt.Sprintf("%s %s, %d hour(s)", i.Date, i.StartTime, i.Hours)
+	case i.Date != "":
+		return fmt.Sprintf("%s, %d hour(s)", i.Date, i.Hours)
+	default:
+		return fmt.Sprintf("%d hour(s)", i.Hours)
+	}
+}
+
+type InvoiceFee struct {
+	Type  string      `json:"type" validate:"required,oneof=SERVICE_FEE CONVENIENCE_FEE" example:"SERVICE_FEE"`
+	Value money.Money `json:"value"`
 }
 
 type CallbackPaymentInvoice struct {
@@ -31,15 +124,59 @@ type CallbackPaymentInvoice struct {
 	FailedRedirectURL  *string `json:"failed_redirect_url,omitempty"`
 }
 
+type CallbackPaymentRequest struct {
+	Event      string                     `json:"event" validate:"required"`
+	BusinessID string                     `json:"business_id"`
+	Created    string                     `json:"created"`
+	Data       CallbackPaymentRequestData `json:"data"`
+}
+
+type CallbackPaymentRequestData struct {
+	ID               string                `json:"id"`
+	PaymentRequestID *string               `json:"payment_request_id,omitempty"`
+	ReferenceID      string                `json:"reference_id" validate:"required,uuid"`
+	Currency         string                `json:"currency"`
+	Amount           float64               `json:"amount"`
+	Status           string                `json:"status" validate:"required"`
+	PaymentMethod    CallbackPaymentMethod `json:"payment_method"`
+	FailureCode      *string               `json:"failure_code,omitempty"`
+	Created          string                `json:"created"`
+	Updated          string                `json:"updated"`
+}
+
+// CallbackPaymentMethod carries the channel specific part of a payment request callback.
+// Only the object matching Type is populated by Xendit.
+type CallbackPaymentMethod struct {
+	ID             string                  `json:"id"`
+	Type           string                  `json:"type"`
+	QrCode         *CallbackChannelDetails `json:"qr_code,omitempty"`
+	VirtualAccount *CallbackChannelDetails `json:"virtual_account,omitempty"`
+	Ewallet        *CallbackChannelDetails `json:"ewallet,omitempty"`
+}
+
+type CallbackChannelDetails struct {
+	ChannelCode string `json:"channel_code"`
+}
+
 type CreatePaymentRequest struct {
-	BookingID     string `json:"booking_id" validate:"required,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
-	PaymentMethod string `json:"payment_method" validate:"required"`
-	Amount        int64  `json:"amount" validate:"required,numeric,min=10000" example:"10000"`
-	TransactionID string `json:"transaction_id" validate:"required"`
+	BookingID      string        `json:"booking_id" validate:"required,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	PaymentMethod  string        `json:"payment_method" validate:"required"`
+	PaymentChannel string        `json:"payment_channel" validate:"omitempty"`
+	Amount         money.Money   `json:"amount"`
+	TransactionID  string        `json:"transaction_id" validate:"required"`
+	CustomerName   string        `json:"customer_name" validate:"omitempty,max=255"`
+	CustomerPhone  string        `json:"customer_phone" validate:"omitempty,e164"`
+	Description    string        `json:"description" validate:"omitempty,max=1000"`
+	Items          []InvoiceItem `json:"items" validate:"omitempty,dive"`
+	Fees           []InvoiceFee  `json:"fees" validate:"omitempty,dive"`
+	TaxAmount      money.Money   `json:"tax_amount"`
 }
 
 type GetPaymentsRequest struct {
 	gdto.PaginationRequest
 	PaymentMethod string `query:"payment_method" json:"payment_method"`
 	PaymentStatus string `query:"payment_status" json:"payment_status"`
+	LocationID    string `query:"location_id" json:"location_id" validate:"omitempty,uuid"`
+	DateFrom      string `query:"date_from" json:"date_from" validate:"omitempty,datetime=2006-01-02"`
+	DateTo        string `query:"date_to" json:"date_to" validate:"omitempty,datetime=2006-01-02"`
 }
@@ -4,26 +4,41 @@ import (
 	"github.com/savioruz/goth/internal/domains/payments/repository"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
 )
 
 type CreatePaymentInvoiceResponse struct {
-	ID         string  `json:"id"`
-	OrderID    string  `json:"order_id"`
-	Amount     int64   `json:"amount"`
-	Status     string  `json:"status"`
-	ExpiryDate *string `json:"expiry_date,omitempty"`
-	PaymentURL *string `json:"payment_url,omitempty"`
+	ID                   string      `json:"id"`
+	OrderID              string      `json:"order_id"`
+	Amount               money.Money `json:"amount"`
+	Status               string      `json:"status"`
+	Channel              string      `json:"channel,omitempty"`
+	ChannelCode          string      `json:"channel_code,omitempty"`
+	ExpiryDate           *string     `json:"expiry_date,omitempty"`
+	PaymentURL           *string     `json:"payment_url,omitempty"`
+	QRString             *string     `json:"qr_string,omitempty"`
+	VirtualAccountNumber *string     `json:"virtual_account_number,omitempty"`
+	DeeplinkURL          *string     `json:"deeplink_url,omitempty"`
 }
 
 type PaymentResponse struct {
-	ID            string  `json:"id"`
-	BookingID     string  `json:"booking_id"`
-	PaymentMethod string  `json:"payment_method"`
-	PaymentStatus string  `json:"payment_status"`
-	TransactionID string  `json:"transaction_id"`
-	PaidAt        *string `json:"paid_at,omitempty"`
-	CreatedAt     string  `json:"created_at"`
-	UpdatedAt     string  `json:"updated_at"`
+	ID             string                `json:"id"`
+	BookingID      string                `json:"booking_id"`
+	PaymentMethod  string                `json:"payment_method"`
+	PaymentChannel string                `json:"payment_channel"`
+	ChannelCode    string                `json:"channel_code,omitempty"`
+	PaymentStatus  string                `json:"payment_status"`
+	TransactionID  string                `json:"transaction_id"`
+	Description    string                `json:"description,omitempty"`
+	CustomerName   string                `json:"customer_name,omitempty"`
+	CustomerPhone  string                `json:"customer_phone,omitempty"`
+	Subtotal       money.Money           `json:"subtotal"`
+	TaxAmount      money.Money           `json:"tax_amount"`
+	TotalAmount    money.Money           `json:"total_amount"`
+	Items          []PaymentItemResponse `json:"items,omitempty"`
+	PaidAt         *string               `json:"paid_at,omitempty"`
+	CreatedAt      string                `json:"created_at"`
+	UpdatedAt      string                `json:"updated_at"`
 }
 
 func (p PaymentResponse) FromModel(model repository.Payment) PaymentResponse {
@@ -35,14 +50,47 @@ func (p PaymentResponse) FromModel(model repository.Payment) PaymentResponse {
 	}
 
 	return PaymentResponse{
-		ID:            model.ID.String(),
-		BookingID:     model.BookingID.String(),
-		PaymentMethod: model.PaymentMethod,
-		PaymentStatus: model.PaymentStatus,
-		TransactionID: model.TransactionID,
-		PaidAt:        paidAt,
-		CreatedAt:     helper.FormatDateInAppTimezone(model.CreatedAt.Time, constant.FullDateFormat),
-		UpdatedAt:     helper.FormatDateInAppTimezone(model.UpdatedAt.Time, constant.FullDateFormat),
+		ID:             model.ID.String(),
+		BookingID:      model.BookingID.String(),
+		PaymentMethod:  model.PaymentMethod,
+		PaymentChannel: model.PaymentChannel,
+		ChannelCode:    model.ChannelCode.String,
+		PaymentStatus:  model.PaymentStatus,
+		TransactionID:  model.TransactionID,
+		Description:    model.Description.String,
+		CustomerName:   model.CustomerName.String,
+		CustomerPhone:  model.CustomerPhone.String,
+		Subtotal:       money.FromPgOrZero(model.Subtotal, model.Currency),
+		TaxAmount:      money.FromPgOrZero(model.TaxAmount, model.Currency),
+		TotalAmount:    money.FromPgOrZero(model.TotalAmount, model.Currency),
+		PaidAt:         paidAt,
+		CreatedAt:      helper.FormatDateInAppTimezone(model.CreatedAt.Time, constant.FullDateFormat),
+		UpdatedAt:      helper.FormatDateInAppTimezone(model.UpdatedAt.Time, constant.FullDateFormat),
+	}
+}
+
+type PaymentItemResponse struct {
+	ReferenceID string      `json:"reference_id,omitempty"`
+	Name        string      `json:"name"`
+	Category    string      `json:"category"`
+	Description string      `json:"description,omitempty"`
+	Quantity    int32       `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Discount    money.Money `json:"discount"`
+	Amount      money.Money `json:"amount"`
+}
+
+// FromModel converts a payment item, whose amounts are in the currency of its payment.
+func (p PaymentItemResponse) FromModel(model repository.PaymentItem, currency string) PaymentItemResponse {
+	return PaymentItemResponse{
+		ReferenceID: model.ReferenceID.String,
+		Name:        model.Name,
+		Category:    model.Category,
+		Description: model.Description.String,
+		Quantity:    model.Quantity,
+		UnitPrice:   money.FromPgOrZero(model.UnitPrice, currency),
+		Discount:    money.FromPgOrZero(model.Discount, currency),
+		Amount:      money.FromPgOrZero(model.Amount, currency),
 	}
 }
 
@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/middleware"
 	"github.com/savioruz/goth/internal/delivery/http/response"
 	"github.com/savioruz/goth/internal/domains/payments/dto"
 	"github.com/savioruz/goth/internal/domains/payments/service"
@@ -31,12 +32,13 @@ const (
 	routepath = "/payments"
 )
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	payments := r.Group(routepath)
 
 	payments.Post("/callbacks", h.Callbacks)
-	payments.Get("/", h.GetPayments)
-	payments.Get("/booking/:booking_id", h.GetPaymentsByBookingID)
+	payments.Post("/callbacks/payment-requests", h.PaymentRequestCallbacks)
+	payments.Get("/", authn.Authenticate(constant.PermissionPaymentsRead), h.GetPayments)
+	payments.Get("/booking/:booking_id", authn.Authenticate(constant.PermissionPaymentsRead), h.GetPaymentsByBookingID)
 }
 
 // Callbacks godoc
@@ -70,7 +72,7 @@ func (h *Handler) Callbacks(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, transformErr)
 	}
 
-	if err := h.service.Callbacks(ctx.Context(), req, token); err != nil {
+	if err := h.service.Callbacks(ctx.UserContext(), req, token); err != nil {
 		h.logger.Error(identifier, " - Callbacks - service error: %v", err)
 
 		return response.WithError(ctx, err)
@@ -79,20 +81,67 @@ func (h *Handler) Callbacks(ctx *fiber.Ctx) error {
 	return response.WithMessage(ctx, fiber.StatusOK, "payment callback processed successfully")
 }
 
+// PaymentRequestCallbacks godoc
+// @Summary Payment request callbacks
+// @Description Handle QRIS, virtual account and e-wallet payment callbacks
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param callback body dto.CallbackPaymentRequest true "Payment request callback"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /payments/callbacks/payment-requests [post]
+func (h *Handler) PaymentRequestCallbacks(ctx *fiber.Ctx) error {
+	var req dto.CallbackPaymentRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		h.logger.Error(identifier, " - PaymentRequestCallbacks - body parser error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	token := ctx.Get(constant.RequestHeaderCallback)
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErr := err.Error()
+		transformErr := failure.BadRequestFromString(validationErr)
+
+		h.logger.Error(identifier, " - PaymentRequestCallbacks - validation error: %v", transformErr)
+
+		return response.WithError(ctx, transformErr)
+	}
+
+	if err := h.service.PaymentRequestCallbacks(ctx.UserContext(), req, token); err != nil {
+		h.logger.Error(identifier, " - PaymentRequestCallbacks - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "payment callback processed successfully")
+}
+
 // GetPayments godoc
 // @Summary Get payments
-// @Description Get all payments with optional filtering and pagination
+// @Description Get payments with optional filtering and pagination. Customers only see the payments of their own bookings; staff with payments:read see the payments at the locations they are assigned to
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param payment_method query string false "Filter by payment method"
 // @Param payment_status query string false "Filter by payment status"
+// @Param location_id query string false "Filter by location ID (staff only)"
+// @Param date_from query string false "Only payments created on or after this date (YYYY-MM-DD)"
+// @Param date_to query string false "Only payments created on or before this date (YYYY-MM-DD)"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10, max: 100)"
 // @Success 200 {object} response.Data[dto.PaginatedPaymentResponse]
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /payments/ [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) GetPayments(ctx *fiber.Ctx) error {
 	var req dto.GetPaymentsRequest
 
@@ -102,7 +151,14 @@ func (h *Handler) GetPayments(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	payments, err := h.service.GetPayments(ctx.Context(), req)
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, " - GetPayments - principal not found: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	payments, err := h.service.GetPayments(ctx.UserContext(), req, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, " - GetPayments - service error: %v", err)
 
@@ -114,15 +170,20 @@ func (h *Handler) GetPayments(ctx *fiber.Ctx) error {
 
 // GetPaymentsByBookingID godoc
 // @Summary Get payments by booking ID
-// @Description Get all payments for a specific booking
+// @Description Get all payments for a specific booking. Only the customer who made the booking and staff with payments:read at its location may read them
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param booking_id path string true "Booking ID"
 // @Success 200 {object} response.Data[[]dto.PaymentResponse]
 // @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /payments/booking/{booking_id} [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
 func (h *Handler) GetPaymentsByBookingID(ctx *fiber.Ctx) error {
 	bookingID := ctx.Params("booking_id")
 
@@ -132,7 +193,20 @@ func (h *Handler) GetPaymentsByBookingID(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, failure.BadRequestFromString("booking ID is required"))
 	}
 
-	payments, err := h.service.GetPaymentsByBookingID(ctx.Context(), bookingID)
+	if err := h.validator.Var(bookingID, "uuid"); err != nil {
+		h.logger.Error(identifier, " - GetPaymentsByBookingID - invalid booking ID: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString("invalid booking ID"))
+	}
+
+	userID, permissions, err := middleware.Principal(ctx)
+	if err != nil {
+		h.logger.Error(identifier, " - GetPaymentsByBookingID - principal not found: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	payments, err := h.service.GetPaymentsByBookingID(ctx.UserContext(), bookingID, userID, permissions)
 	if err != nil {
 		h.logger.Error(identifier, " - GetPaymentsByBookingID - service error: %v", err)
 
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/savioruz/goth/internal/domains/payments/dto"
+	"github.com/savioruz/goth/internal/domains/payments/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/tenant"
+	paymentrequest "github.com/xendit/xendit-go/v7/payment_request"
+)
+
+const (
+	actionURLTypeDeeplink = "DEEPLINK"
+	actionURLTypeMobile   = "MOBILE"
+	actionURLTypeWeb      = "WEB"
+)
+
+// createPaymentRequest charges the customer directly through QRIS, a virtual account or an e-wallet
+// instead of redirecting them to the hosted invoice page.
+func (s *paymentService) createPaymentRequest(ctx context.Context, req dto.CreatePaymentInvoice) (res dto.CreatePaymentInvoiceResponse, err error) {
+	expiresAt := helper.NowInAppTimezone().Add(constant.PaymentExpiryDuration)
+
+	method, channelCode, err := s.buildPaymentMethod(req, expiresAt)
+	if err != nil {
+		return res, err
+	}
+
+	currency, erro := paymentrequest.NewPaymentRequestCurrencyFromValue(string(req.Amount.Currency))
+	if erro != nil {
+		s.logger.Error(identifier, " - createPaymentRequest - unsupported currency: %v", erro)
+
+		return res, failure.BadRequestFromString(erro.Error())
+	}
+
+	amount := req.Amount.Major()
+	referenceID := req.OrderID
+
+	params := *paymentrequest.NewPaymentRequestParameters(*currency)
+	params.ReferenceId = &referenceID
+	params.Amount = &amount
+	params.PaymentMethod = method
+	params.Items = basketItems(req)
+	params.Metadata = customerMetadata(req)
+
+	if req.Description != "" {
+		params.Description = *paymentrequest.NewNullableString(&req.Description)
+	}
+
+	client, err := s.xenditClient(ctx, "createPaymentRequest")
+	if err != nil {
+		return res, err
+	}
+
+	// Retries of the same channel are deduplicated, while switching channel creates a new payment request.
+	result, _, erro := client.PaymentRequestApi.CreatePaymentRequest(ctx).
+		IdempotencyKey(req.OrderID + ":" + channelCode).
+		PaymentRequestParameters(params).
+		Execute()
+	if erro != nil {
+		s.logger.Error(identifier, " - createPaymentRequest - failed to create payment request: %v", erro)
+
+		return res, failure.InternalError(erro)
+	}
+
+	paymentStatus := paymentStatusFromPaymentRequest(result.GetStatus().String())
+
+	id, err := s.insertPayment(ctx, repository.InsertPaymentParams{
+		BookingID:      helper.PgUUID(req.OrderID),
+		PaymentMethod:  channelCode,
+		PaymentStatus:  paymentStatus,
+		TransactionID:  result.GetId(),
+		PaymentChannel: req.Channel,
+		ChannelCode:    helper.PgString(channelCode),
+		Description:    helper.PgString(req.Description),
+		CustomerName:   helper.PgString(req.CustomerName),
+		CustomerPhone:  helper.PgString(req.CustomerPhone),
+		Subtotal:       req.Subtotal().Pg(),
+		TaxAmount:      req.TaxAmount.Pg(),
+		TotalAmount:    req.Amount.Pg(),
+		Currency:       string(req.Amount.Currency),
+		OrganizationID: tenant.FromContext(ctx),
+	}, paymentItemParams(req.Items, req.Fees))
+	if err != nil {
+		return res, err
+	}
+
+	expiryDate := expiresAt.Format(constant.FullDateFormat)
+
+	res = dto.CreatePaymentInvoiceResponse{
+		ID:          id,
+		OrderID:     req.OrderID,
+		Amount:      req.Amount,
+		Status:      paymentStatus,
+		Channel:     req.Channel,
+		ChannelCode: channelCode,
+		ExpiryDate:  &expiryDate,
+	}
+
+	paymentMethod := result.GetPaymentMethod()
+
+	switch req.Channel {
+	case constant.PaymentChannelQRIS:
+		qrCode := paymentMethod.GetQrCode()
+		channelProperties := qrCode.GetChannelProperties()
+
+		if qrString, ok := channelProperties.GetQrStringOk(); ok {
+			res.QRString = qrString
+		}
+	case constant.PaymentChannelVirtualAccount:
+		virtualAccount := paymentMethod.GetVirtualAccount()
+		channelProperties := virtualAccount.GetChannelProperties()
+
+		if number, ok := channelProperties.GetVirtualAccountNumberOk(); ok {
+			res.VirtualAccountNumber = number
+		}
+	case constant.PaymentChannelEWallet:
+		res.DeeplinkURL, res.PaymentURL = actionURLs(result.GetActions())
+	}
+
+	return res, nil
+}
+
+func (s *paymentService) buildPaymentMethod(req dto.CreatePaymentInvoice, expiresAt time.Time) (*paymentrequest.PaymentMethodParameters, string, error) {
+	switch req.Channel {
+	case constant.PaymentChannelQRIS:
+		method := paymentrequest.NewPaymentMethodParameters(paymentrequest.PAYMENTMETHODTYPE_QR_CODE, paymentrequest.PAYMENTMETHODREUSABILITY_ONE_TIME_USE)
+
+		qrCode := paymentrequest.NewQRCodeParameters()
+		qrCode.SetChannelCode(paymentrequest.QRCODECHANNELCODE_QRIS)
+		qrCode.SetChannelProperties(paymentrequest.QRCodeChannelProperties{ExpiresAt: &expiresAt})
+		method.SetQrCode(*qrCode)
+
+		return method, constant.PaymentChannelQRIS, nil
+	case constant.PaymentChannelVirtualAccount:
+		channelCode, err := paymentrequest.NewVirtualAccountChannelCodeFromValue(strings.ToUpper(req.ChannelCode))
+		if err != nil {
+			s.logger.Error(identifier, " - buildPaymentMethod - invalid virtual account channel: %s", req.ChannelCode)
+
+			return nil, "", failure.BadRequestFromString("unsupported virtual account channel: " + req.ChannelCode)
+		}
+
+		channelProperties := paymentrequest.NewVirtualAccountChannelProperties(req.CustomerName)
+		channelProperties.SetExpiresAt(expiresAt)
+
+		method := paymentrequest.NewPaymentMethodParameters(paymentrequest.PAYMENTMETHODTYPE_VIRTUAL_ACCOUNT, paymentrequest.PAYMENTMETHODREUSABILITY_ONE_TIME_USE)
+		method.SetVirtualAccount(*paymentrequest.NewVirtualAccountParameters(*channelCode, *channelProperties))
+
+		return method, channelCode.String(), nil
+	case constant.PaymentChannelEWallet:
+		channelCode, err := paymentrequest.NewEWalletChannelCodeFromValue(strings.ToUpper(req.ChannelCode))
+		if err != nil {
+			s.logger.Error(identifier, " - buildPaymentMethod - invalid e-wallet channel: %s", req.ChannelCode)
+
+			return nil, "", failure.BadRequestFromString("unsupported e-wallet channel: " + req.ChannelCode)
+		}
+
+		channelProperties := paymentrequest.NewEWalletChannelProperties()
+		channelProperties.SetSuccessReturnUrl(s.cfg.Xendit.SuccessURL)
+		channelProperties.SetFailureReturnUrl(s.cfg.Xendit.FailureURL)
+
+		if *channelCode == paymentrequest.EWALLETCHANNELCODE_OVO {
+			if req.MobileNumber == "" {
+				return nil, "", failure.BadRequestFromString("mobile number is required for OVO payments")
+			}
+
+			channelProperties.SetMobileNumber(req.MobileNumber)
+		}
+
+		ewallet := paymentrequest.NewEWalletParameters()
+		ewallet.SetChannelCode(*channelCode)
+		ewallet.SetChannelProperties(*channelProperties)
+
+		method := paymentrequest.NewPaymentMethodParameters(paymentrequest.PAYMENTMETHODTYPE_EWALLET, paymentrequest.PAYMENTMETHODREUSABILITY_ONE_TIME_USE)
+		method.SetEwallet(*ewallet)
+
+		return method, channelCode.String(), nil
+	}
+
+	return nil, "", failure.BadRequestFromString("unsupported payment channel: " + req.Channel)
+}
+
+// customerName resolves the payer's name from their account, falling back to the email's local part.
+func (s *paymentService) customerName(ctx context.Context, email string) string {
+	user, err := s.userRepo.GetUserByEmail(ctx, s.db, email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error(identifier, " - customerName - failed to get user by email: %v", err)
+	}
+
+	if user.FullName.Valid && user.FullName.String != "" {
+		return user.FullName.String
+	}
+
+	return strings.Split(email, "@")[0]
+}
+
+// actionURLs picks the deeplink and web checkout URLs from the actions of an e-wallet payment request.
+func actionURLs(actions []paymentrequest.PaymentRequestAction) (deeplink, web *string) {
+	for _, action := range actions {
+		url, ok := action.GetUrlOk()
+		if !ok || url == nil {
+			continue
+		}
+
+		switch action.GetUrlType() {
+		case actionURLTypeDeeplink, actionURLTypeMobile:
+			deeplink = url
+		case actionURLTypeWeb:
+			web = url
+		}
+	}
+
+	return deeplink, web
+}
+
+// paymentStatusFromPaymentRequest maps a Xendit payment request status onto our payment statuses.
+func paymentStatusFromPaymentRequest(status string) string {
+	switch status {
+	case constant.PaymentRequestStatusSucceeded:
+		return constant.PaymentStatusPaid
+	case constant.PaymentRequestStatusFailed, constant.PaymentRequestStatusCanceled:
+		return constant.PaymentStatusFailed
+	case constant.PaymentRequestStatusExpired:
+		return constant.PaymentStatusExpired
+	default:
+		return constant.PaymentStatusPending
+	}
+}
+
+// bookingStatusFromPayment returns the booking status implied by a payment status, or empty when unchanged.
+func bookingStatusFromPayment(paymentStatus string) string {
+	switch paymentStatus {
+	case constant.PaymentStatusPaid:
+		return constant.BookingStatusConfirmed
+	case constant.PaymentStatusExpired:
+		return constant.BookingStatusExpired
+	case constant.PaymentStatusFailed:
+		return constant.BookingStatusCanceled
+	default:
+		return ""
+	}
+}
+
+// callbackChannelCode reads the channel code from whichever channel object the callback carries.
+func callbackChannelCode(method dto.CallbackPaymentMethod) string {
+	var details *dto.CallbackChannelDetails
+
+	switch method.Type {
+	case string(paymentrequest.PAYMENTMETHODTYPE_QR_CODE):
+		details = method.QrCode
+	case string(paymentrequest.PAYMENTMETHODTYPE_VIRTUAL_ACCOUNT):
+		details = method.VirtualAccount
+	case string(paymentrequest.PAYMENTMETHODTYPE_EWALLET):
+		details = method.Ewallet
+	}
+
+	if details == nil || details.ChannelCode == "" {
+		return constant.PaymentUnknownMethod
+	}
+
+	return details.ChannelCode
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/savioruz/goth/internal/domains/payments/dto"
+	"github.com/savioruz/goth/internal/domains/payments/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
+	"github.com/xendit/xendit-go/v7/invoice"
+	paymentrequest "github.com/xendit/xendit-go/v7/payment_request"
+)
+
+var feeLabels = map[string]string{
+	constant.PaymentFeeService:     "Service fee",
+	constant.PaymentFeeConvenience: "Convenience fee",
+}
+
+// paymentItemParams flattens the invoice items and fees into rows so the invoice can be re-rendered later.
+// Fees are stored as items of the FEE category; the payment ID is filled in by insertPayment.
+func paymentItemParams(items []dto.InvoiceItem, fees []dto.InvoiceFee) []repository.InsertPaymentItemParams {
+	params := make([]repository.InsertPaymentItemParams, 0, len(items)+len(fees))
+
+	for _, item := range items {
+		params = append(params, repository.InsertPaymentItemParams{
+			ReferenceID: helper.PgString(item.ReferenceID),
+			Name:        item.Name,
+			Category:    item.Category,
+			Description: helper.PgString(item.Summary()),
+			Quantity:    int32(item.Hours),
+			UnitPrice:   item.UnitPrice.Pg(),
+			Discount:    item.Discount.Pg(),
+			Amount:      item.Amount().Pg(),
+		})
+	}
+
+	for _, fee := range fees {
+		params = append(params, repository.InsertPaymentItemParams{
+			ReferenceID: helper.PgString(fee.Type),
+			Name:        feeLabels[fee.Type],
+			Category:    constant.PaymentItemCategoryFee,
+			Quantity:    1,
+			UnitPrice:   fee.Value.Pg(),
+			Discount:    money.Zero(fee.Value.Currency).Pg(),
+			Amount:      fee.Value.Pg(),
+		})
+	}
+
+	return params
+}
+
+// taxLabel names the tax line, e.g. "PPN 11%" or "PPN 11% (included)".
+func taxLabel(rate float64, inclusive bool) string {
+	label := fmt.Sprintf("%s %s%%", constant.TaxLabelPPN, strconv.FormatFloat(rate, 'f', -1, 64))
+	if inclusive {
+		label += " (included)"
+	}
+
+	return label
+}
+
+// invoiceAmount converts an amount for the invoice API, which only takes float32.
+// A float32 holds whole numbers exactly only up to 2^24, larger amounts would be charged rounded.
+func invoiceAmount(amount money.Money) (float32, error) {
+	major := amount.Major()
+	if float64(float32(major)) != major {
+		return 0, failure.BadRequestFromString(fmt.Sprintf("%s cannot be charged exactly on an invoice", amount))
+	}
+
+	return float32(major), nil
+}
+
+// invoiceItems converts the request into Xendit invoice items and fees.
+// Xendit has no per-item discount, so discounts are sent as negative fees.
+func invoiceItems(req dto.CreatePaymentInvoice) ([]invoice.InvoiceItem, []invoice.InvoiceFee, error) {
+	items := make([]invoice.InvoiceItem, 0, len(req.Items))
+	fees := make([]invoice.InvoiceFee, 0, len(req.Fees))
+
+	for _, item := range req.Items {
+		unitPrice, err := invoiceAmount(item.UnitPrice)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		invoiceItem := invoice.NewInvoiceItem(item.Name, unitPrice, float32(item.Hours))
+		invoiceItem.SetCategory(item.Category)
+
+		if item.ReferenceID != "" {
+			invoiceItem.SetReferenceId(item.ReferenceID)
+		}
+
+		items = append(items, *invoiceItem)
+
+		if item.Discount.IsPositive() {
+			discount, err := invoiceAmount(item.Discount.Neg())
+			if err != nil {
+				return nil, nil, err
+			}
+
+			fees = append(fees, *invoice.NewInvoiceFee(constant.PaymentFeeDiscount, discount))
+		}
+	}
+
+	for _, fee := range req.Fees {
+		value, err := invoiceAmount(fee.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fees = append(fees, *invoice.NewInvoiceFee(fee.Type, value))
+	}
+
+	if req.TaxAmount.IsPositive() && !req.TaxInclusive {
+		tax, err := invoiceAmount(req.TaxAmount)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fees = append(fees, *invoice.NewInvoiceFee(taxLabel(req.TaxRate, false), tax))
+	}
+
+	return items, fees, nil
+}
+
+func invoiceCustomer(req dto.CreatePaymentInvoice) *invoice.CustomerObject {
+	customer := invoice.NewCustomerObject()
+	customer.SetEmail(req.PayerEmail)
+
+	if req.CustomerName != "" {
+		customer.SetGivenNames(req.CustomerName)
+	}
+
+	if req.CustomerPhone != "" {
+		customer.SetMobileNumber(req.CustomerPhone)
+	}
+
+	return customer
+}
+
+// basketItems converts the request into payment request basket items, with fees and discounts as their own lines.
+func basketItems(req dto.CreatePaymentInvoice) []paymentrequest.PaymentRequestBasketItem {
+	items := make([]paymentrequest.PaymentRequestBasketItem, 0, len(req.Items)+len(req.Fees))
+	currency := string(req.Amount.Currency)
+
+	for _, item := range req.Items {
+		basketItem := paymentrequest.NewPaymentRequestBasketItem(item.Name, item.Category, currency, float64(item.Hours), item.UnitPrice.Major())
+		basketItem.SetDescription(item.Summary())
+
+		if item.ReferenceID != "" {
+			basketItem.SetReferenceId(item.ReferenceID)
+		}
+
+		items = append(items, *basketItem)
+
+		if item.Discount.IsPositive() {
+			discount := paymentrequest.NewPaymentRequestBasketItem(item.Name+" discount", constant.PaymentItemCategoryDiscount, currency, 1, item.Discount.Neg().Major())
+			items = append(items, *discount)
+		}
+	}
+
+	for _, fee := range req.Fees {
+		basketItem := paymentrequest.NewPaymentRequestBasketItem(feeLabels[fee.Type], constant.PaymentItemCategoryFee, currency, 1, fee.Value.Major())
+		basketItem.SetReferenceId(fee.Type)
+
+		items = append(items, *basketItem)
+	}
+
+	if req.TaxAmount.IsPositive() && !req.TaxInclusive {
+		tax := paymentrequest.NewPaymentRequestBasketItem(taxLabel(req.TaxRate, false), constant.PaymentItemCategoryTax, currency, 1, req.TaxAmount.Major())
+		items = append(items, *tax)
+	}
+
+	return items
+}
+
+func customerMetadata(req dto.CreatePaymentInvoice) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"customer_email": req.PayerEmail,
+	}
+
+	if req.CustomerName != "" {
+		metadata["customer_name"] = req.CustomerName
+	}
+
+	if req.CustomerPhone != "" {
+		metadata["customer_phone"] = req.CustomerPhone
+	}
+
+	return metadata
+}
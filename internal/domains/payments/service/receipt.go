@@ -0,0 +1,206 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	fieldRepository "github.com/savioruz/goth/internal/domains/fields/repository"
+	locationRepository "github.com/savioruz/goth/internal/domains/locations/repository"
+	"github.com/savioruz/goth/internal/domains/payments/repository"
+	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/money"
+	"github.com/savioruz/goth/pkg/receipt"
+	"github.com/savioruz/goth/pkg/supabase"
+	"github.com/savioruz/goth/pkg/tenant"
+)
+
+// GetReceipt returns the PDF receipt of a paid booking, rendering and storing it on first access.
+func (s *paymentService) GetReceipt(ctx context.Context, bookingID string) ([]byte, error) {
+	key := supabase.ReceiptKey(tenant.FromContext(ctx).String(), bookingID)
+
+	stored, err := s.storage.GetObject(ctx, key)
+	if err == nil {
+		return stored, nil
+	}
+
+	if !errors.Is(err, supabase.ErrFileNotFound) {
+		s.logger.Error(identifier, " - GetReceipt - failed to get stored receipt: %v", err)
+	}
+
+	pdf, err := s.renderReceipt(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.PutObject(ctx, key, pdf, receipt.ContentType); err != nil {
+		s.logger.Error(identifier, " - GetReceipt - failed to store receipt: %v", err)
+	}
+
+	return pdf, nil
+}
+
+func (s *paymentService) renderReceipt(ctx context.Context, bookingID string) ([]byte, error) {
+	booking, err := s.bookingRepo.GetBookingById(ctx, s.db, bookingRepository.GetBookingByIdParams{
+		ID:             helper.PgUUID(bookingID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, failure.NotFound("booking not found")
+		}
+
+		s.logger.Error(identifier, " - renderReceipt - failed to get booking: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	if booking.Status != constant.BookingStatusPaid && booking.Status != constant.BookingStatusConfirmed {
+		return nil, failure.BadRequestFromString("receipt is only available for paid bookings")
+	}
+
+	// Walk-in guests have no account, their contact details are on the booking
+	user := userRepository.User{FullName: booking.GuestName, Email: booking.GuestEmail.String}
+	if booking.UserID.Valid {
+		user, err = s.userRepo.GetUserByID(ctx, s.db, booking.UserID)
+		if err != nil {
+			s.logger.Error(identifier, " - renderReceipt - failed to get user: %v", err)
+
+			return nil, failure.InternalError(err)
+		}
+	}
+
+	field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepository.GetFieldByIdParams{
+		ID:             booking.FieldID,
+		OrganizationID: booking.OrganizationID,
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - renderReceipt - failed to get field: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	location, err := s.locationRepo.GetLocationById(ctx, s.db, locationRepository.GetLocationByIdParams{
+		ID:             field.LocationID,
+		OrganizationID: field.OrganizationID,
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - renderReceipt - failed to get location: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	payments, err := s.repo.GetPaymentsByBookingID(ctx, s.db, repository.GetPaymentsByBookingIDParams{
+		BookingID:      booking.ID,
+		OrganizationID: booking.OrganizationID,
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - renderReceipt - failed to get payments: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	payment, ok := settledPayment(payments)
+	if !ok {
+		return nil, failure.NotFound("no payment found for booking")
+	}
+
+	items, err := s.repo.GetPaymentItemsByPaymentID(ctx, s.db, payment.ID)
+	if err != nil {
+		s.logger.Error(identifier, " - renderReceipt - failed to get payment items: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	startTime, _ := helper.PgTimeToString(booking.StartTime)
+	endTime, _ := helper.PgTimeToString(booking.EndTime)
+	currency := booking.Currency
+	total := money.FromPgOrZero(booking.TotalPrice, currency)
+	subtotal := money.FromPgOrZero(booking.Subtotal, currency)
+	tax := money.FromPgOrZero(booking.TaxAmount, currency)
+
+	if paid := money.FromPgOrZero(payment.TotalAmount, payment.Currency); paid.IsPositive() {
+		currency = payment.Currency
+		total, subtotal, tax = paid, money.FromPgOrZero(payment.Subtotal, currency), money.FromPgOrZero(payment.TaxAmount, currency)
+	}
+
+	customerName := payment.CustomerName.String
+	if customerName == "" {
+		customerName = user.FullName.String
+	}
+
+	data := receipt.Data{
+		Issuer:        s.cfg.App.Name,
+		ReceiptNumber: bookingID,
+		IssuedAt:      helper.NowInAppTimezone().Format(constant.TimestampFormat),
+		CustomerName:  customerName,
+		CustomerEmail: user.Email,
+		CustomerPhone: cmp.Or(payment.CustomerPhone.String, booking.GuestPhone.String),
+		LocationName:  location.Name,
+		FieldName:     field.Name,
+		FieldType:     field.Type,
+		BookingDate:   booking.BookingDate.Time.Format(constant.DateFormat),
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Status:        booking.Status,
+		PaymentMethod: payment.PaymentMethod,
+		Subtotal:      subtotal,
+		Tax:           tax,
+		TaxLabel:      taxLabel(helper.Float64FromPg(booking.TaxRate), booking.TaxInclusive),
+		Total:         total,
+	}
+
+	if payment.PaidAt.Valid {
+		data.PaidAt = helper.FormatDateInAppTimezone(payment.PaidAt.Time, constant.TimestampFormat)
+	}
+
+	for _, item := range items {
+		data.Items = append(data.Items, receipt.Item{
+			Name:        item.Name,
+			Description: item.Description.String,
+			Quantity:    int(item.Quantity),
+			UnitPrice:   money.FromPgOrZero(item.UnitPrice, payment.Currency),
+			Discount:    money.FromPgOrZero(item.Discount, payment.Currency),
+			Amount:      money.FromPgOrZero(item.Amount, payment.Currency),
+		})
+	}
+
+	if len(data.Items) == 0 {
+		data.Items = []receipt.Item{{
+			Name:      field.Name,
+			Quantity:  1,
+			UnitPrice: total,
+			Discount:  money.Zero(total.Currency),
+			Amount:    total,
+		}}
+	}
+
+	pdf, err := receipt.Render(data)
+	if err != nil {
+		s.logger.Error(identifier, " - renderReceipt - failed to render receipt: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return pdf, nil
+}
+
+// settledPayment picks the paid payment of a booking, falling back to the most recent one.
+func settledPayment(payments []repository.Payment) (repository.Payment, bool) {
+	if len(payments) == 0 {
+		return repository.Payment{}, false
+	}
+
+	for _, payment := range payments {
+		if payment.PaymentStatus == constant.PaymentStatusPaid {
+			return payment, true
+		}
+	}
+
+	return payments[0], true
+}
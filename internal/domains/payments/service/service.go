@@ -2,14 +2,22 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/savioruz/goth/config"
 	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	fieldRepository "github.com/savioruz/goth/internal/domains/fields/repository"
+	locationRepository "github.com/savioruz/goth/internal/domains/locations/repository"
+	locationService "github.com/savioruz/goth/internal/domains/locations/service"
+	organizationRepository "github.com/savioruz/goth/internal/domains/organizations/repository"
+	organizationService "github.com/savioruz/goth/internal/domains/organizations/service"
 	"github.com/savioruz/goth/internal/domains/payments/dto"
 	"github.com/savioruz/goth/internal/domains/payments/repository"
 	userRepository "github.com/savioruz/goth/internal/domains/user/repository"
@@ -18,8 +26,13 @@ import (
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
 	"github.com/savioruz/goth/pkg/mail"
+	"github.com/savioruz/goth/pkg/money"
 	"github.com/savioruz/goth/pkg/postgres"
+	"github.com/savioruz/goth/pkg/receipt"
 	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/secret"
+	"github.com/savioruz/goth/pkg/supabase"
+	"github.com/savioruz/goth/pkg/tenant"
 	"github.com/xendit/xendit-go/v7"
 	"github.com/xendit/xendit-go/v7/invoice"
 )
@@ -27,36 +40,46 @@ import (
 type PaymentService interface {
 	CreateInvoice(ctx context.Context, req dto.CreatePaymentInvoice) (dto.CreatePaymentInvoiceResponse, error)
 	Callbacks(ctx context.Context, req dto.CallbackPaymentInvoice, token string) error
+	PaymentRequestCallbacks(ctx context.Context, req dto.CallbackPaymentRequest, token string) error
 	CreatePayments(ctx context.Context, req dto.CreatePaymentRequest) (string, error)
-	GetPayments(ctx context.Context, req dto.GetPaymentsRequest) (dto.PaginatedPaymentResponse, error)
-	GetPaymentsByBookingID(ctx context.Context, bookingID string) ([]dto.PaymentResponse, error)
+	GetPayments(ctx context.Context, req dto.GetPaymentsRequest, userID string, permissions []string) (dto.PaginatedPaymentResponse, error)
+	GetPaymentsByBookingID(ctx context.Context, bookingID, userID string, permissions []string) ([]dto.PaymentResponse, error)
+	GetReceipt(ctx context.Context, bookingID string) ([]byte, error)
 }
 
 type paymentService struct {
-	db          postgres.PgxIface
-	repo        repository.Querier
-	bookingRepo bookingRepository.Querier
-	userRepo    userRepository.Querier
-	cache       redis.IRedisCache
-	cfg         *config.Config
-	logger      logger.Interface
-	xendit      *xendit.APIClient
-	validator   *validator.Validate
-	mailService mail.Service
+	db               postgres.PgxIface
+	repo             repository.Querier
+	bookingRepo      bookingRepository.Querier
+	userRepo         userRepository.Querier
+	fieldRepo        fieldRepository.Querier
+	locationRepo     locationRepository.Querier
+	organizationRepo organizationRepository.Querier
+	box              *secret.Box
+	cache            redis.IRedisCache
+	cfg              *config.Config
+	logger           logger.Interface
+	validator        *validator.Validate
+	mailService      mail.Service
+	storage          *supabase.Client
 }
 
-func New(db postgres.PgxIface, r repository.Querier, b bookingRepository.Querier, u userRepository.Querier, c redis.IRedisCache, cfg *config.Config, l logger.Interface, m mail.Service) PaymentService {
+func New(db postgres.PgxIface, r repository.Querier, b bookingRepository.Querier, u userRepository.Querier, f fieldRepository.Querier, lr locationRepository.Querier, o organizationRepository.Querier, box *secret.Box, c redis.IRedisCache, cfg *config.Config, l logger.Interface, m mail.Service, storage *supabase.Client) PaymentService {
 	return &paymentService{
-		db:          db,
-		repo:        r,
-		bookingRepo: b,
-		userRepo:    u,
-		cache:       c,
-		cfg:         cfg,
-		logger:      l,
-		xendit:      xendit.NewClient(cfg.Xendit.APIKey),
-		validator:   validator.New(),
-		mailService: m,
+		db:               db,
+		repo:             r,
+		bookingRepo:      b,
+		userRepo:         u,
+		fieldRepo:        f,
+		locationRepo:     lr,
+		organizationRepo: o,
+		box:              box,
+		cache:            c,
+		cfg:              cfg,
+		logger:           l,
+		validator:        validator.New(),
+		mailService:      m,
+		storage:          storage,
 	}
 }
 
@@ -71,11 +94,60 @@ func (s *paymentService) CreateInvoice(ctx context.Context, req dto.CreatePaymen
 		return res, failure.BadRequestFromString("validation error: " + err.Error())
 	}
 
-	createInvoice := *invoice.NewCreateInvoiceRequest(req.OrderID, float64(req.Amount))
+	if err := validateAmount(req.Amount); err != nil {
+		s.logger.Error(identifier, " - CreateInvoice - invalid amount: %v", err)
+
+		return res, err
+	}
+
+	if !req.SameCurrency() {
+		s.logger.Error(identifier, " - CreateInvoice - invoice items are not all in %s", req.Amount.Currency)
+
+		return res, failure.BadRequestFromString("invoice items, fees and tax must be in the currency of the amount")
+	}
+
+	if len(req.Items) > 0 && !req.Total().Equal(req.Amount) {
+		s.logger.Error(identifier, " - CreateInvoice - amount %s does not match invoice items total %s", req.Amount, req.Total())
+
+		return res, failure.BadRequestFromString("amount does not match the total of invoice items and fees")
+	}
+
+	if req.CustomerName == "" {
+		req.CustomerName = s.customerName(ctx, req.PayerEmail)
+	}
+
+	switch req.Channel {
+	case constant.PaymentChannelQRIS, constant.PaymentChannelVirtualAccount, constant.PaymentChannelEWallet:
+		return s.createPaymentRequest(ctx, req)
+	default:
+		return s.createHostedInvoice(ctx, req)
+	}
+}
+
+func (s *paymentService) createHostedInvoice(ctx context.Context, req dto.CreatePaymentInvoice) (res dto.CreatePaymentInvoiceResponse, err error) {
+	currency := string(req.Amount.Currency)
+
+	createInvoice := *invoice.NewCreateInvoiceRequest(req.OrderID, req.Amount.Major())
+	createInvoice.Currency = &currency
 	createInvoice.SuccessRedirectUrl = &s.cfg.Xendit.SuccessURL
 	createInvoice.FailureRedirectUrl = &s.cfg.Xendit.FailureURL
+	createInvoice.Customer = invoiceCustomer(req)
 
-	invoiceResult, _, erro := s.xendit.InvoiceApi.CreateInvoice(ctx).CreateInvoiceRequest(createInvoice).Execute()
+	createInvoice.Items, createInvoice.Fees, err = invoiceItems(req)
+	if err != nil {
+		return res, err
+	}
+
+	if req.Description != "" {
+		createInvoice.Description = &req.Description
+	}
+
+	client, err := s.xenditClient(ctx, "CreateInvoice")
+	if err != nil {
+		return res, err
+	}
+
+	invoiceResult, _, erro := client.InvoiceApi.CreateInvoice(ctx).CreateInvoiceRequest(createInvoice).Execute()
 	if erro != nil {
 		s.logger.Error(identifier, " - CreateInvoice - failed to create invoiceResult: %v", erro)
 
@@ -102,30 +174,23 @@ func (s *paymentService) CreateInvoice(ctx context.Context, req dto.CreatePaymen
 		return res, failure.InternalError(err)
 	}
 
-	tx, err := s.db.Begin(ctx)
-	if err != nil {
-		s.logger.Error(identifier, " - CreateInvoice - failed to begin transaction: %v", err)
-
-		return res, failure.InternalError(err)
-	}
-
-	defer func(tx pgx.Tx, ctx context.Context) {
-		err := tx.Rollback(ctx)
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			s.logger.Error(identifier, " - CreateInvoice - failed to rollback transaction: %v", err)
-		}
-	}(tx, ctx)
-
-	id, err := s.repo.InsertPayment(ctx, tx, repository.InsertPaymentParams{
-		BookingID:     helper.PgUUID(req.OrderID),
-		PaymentMethod: paymentMethod,
-		PaymentStatus: paymentStatus,
-		TransactionID: transactionID,
-	})
+	id, err := s.insertPayment(ctx, repository.InsertPaymentParams{
+		BookingID:      helper.PgUUID(req.OrderID),
+		PaymentMethod:  paymentMethod,
+		PaymentStatus:  paymentStatus,
+		TransactionID:  transactionID,
+		PaymentChannel: constant.PaymentChannelInvoice,
+		Description:    helper.PgString(req.Description),
+		CustomerName:   helper.PgString(req.CustomerName),
+		CustomerPhone:  helper.PgString(req.CustomerPhone),
+		Subtotal:       req.Subtotal().Pg(),
+		TaxAmount:      req.TaxAmount.Pg(),
+		TotalAmount:    req.Amount.Pg(),
+		Currency:       string(req.Amount.Currency),
+		OrganizationID: tenant.FromContext(ctx),
+	}, paymentItemParams(req.Items, req.Fees))
 	if err != nil {
-		s.logger.Error(identifier, " - CreateInvoice - failed to insert payment: %v", err)
-
-		return res, failure.InternalError(err)
+		return res, err
 	}
 
 	expiryDate := invoiceResult.ExpiryDate.Format(constant.DateFormat)
@@ -136,44 +201,77 @@ func (s *paymentService) CreateInvoice(ctx context.Context, req dto.CreatePaymen
 	}
 
 	res = dto.CreatePaymentInvoiceResponse{
-		ID:         id.String(),
+		ID:         id,
 		OrderID:    req.OrderID,
 		Amount:     req.Amount,
 		Status:     paymentStatus,
+		Channel:    constant.PaymentChannelInvoice,
 		ExpiryDate: &expiryDate,
 		PaymentURL: &paymentURL,
 	}
 
-	if err = tx.Commit(ctx); err != nil {
-		s.logger.Error(identifier, " - CreateInvoice - failed to commit transaction: %v", err)
-
-		return res, failure.InternalError(err)
-	}
-
 	return res, nil
 }
 
-func (s *paymentService) Callbacks(ctx context.Context, req dto.CallbackPaymentInvoice, token string) (err error) {
-	if s.cfg.Xendit.CallbackToken != token {
-		s.logger.Error(identifier, " - Callbacks - invalid callback token: %s", token)
-
-		return failure.Unauthorized("invalid callback token")
-	}
-
+// insertPayment stores a freshly created payment together with its line items and returns its ID.
+func (s *paymentService) insertPayment(ctx context.Context, params repository.InsertPaymentParams, items []repository.InsertPaymentItemParams) (string, error) {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		s.logger.Error(identifier, " - Callbacks - failed to begin transaction: %v", err)
+		s.logger.Error(identifier, " - insertPayment - failed to begin transaction: %v", err)
 
-		return failure.InternalError(err)
+		return "", failure.InternalError(err)
 	}
 
 	defer func(tx pgx.Tx, ctx context.Context) {
 		err := tx.Rollback(ctx)
 		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			s.logger.Error(identifier, " - Callbacks - failed to rollback transaction: %v", err)
+			s.logger.Error(identifier, " - insertPayment - failed to rollback transaction: %v", err)
 		}
 	}(tx, ctx)
 
+	id, err := s.repo.InsertPayment(ctx, tx, params)
+	if err != nil {
+		s.logger.Error(identifier, " - insertPayment - failed to insert payment: %v", err)
+
+		return "", failure.InternalError(err)
+	}
+
+	for _, item := range items {
+		item.PaymentID = id
+
+		if err = s.repo.InsertPaymentItem(ctx, tx, item); err != nil {
+			s.logger.Error(identifier, " - insertPayment - failed to insert payment item: %v", err)
+
+			return "", failure.InternalError(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error(identifier, " - insertPayment - failed to commit transaction: %v", err)
+
+		return "", failure.InternalError(err)
+	}
+
+	return id.String(), nil
+}
+
+// validCallbackToken compares the tokens in constant time, so the stored token cannot be guessed from response timings.
+func validCallbackToken(expected, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
+
+func (s *paymentService) Callbacks(ctx context.Context, req dto.CallbackPaymentInvoice, token string) (err error) {
+	credentials, err := s.xenditCredentials(ctx, "Callbacks")
+	if err != nil {
+		return err
+	}
+
+	if !validCallbackToken(credentials.CallbackToken, token) {
+		s.logger.Error(identifier, " - Callbacks - invalid callback token")
+
+		return failure.Unauthorized("invalid callback token")
+	}
+
 	paymentStatus := req.Status
 	if req.Status == "" {
 		paymentStatus = constant.PaymentStatusPending
@@ -184,52 +282,104 @@ func (s *paymentService) Callbacks(ctx context.Context, req dto.CallbackPaymentI
 		paymentMethod = &constant.PaymentUnknownMethod
 	}
 
-	if err := s.repo.UpdatePaymentStatusByBookingID(ctx, tx, repository.UpdatePaymentStatusByBookingIDParams{
-		BookingID:     helper.PgUUID(req.ExternalID),
-		PaymentStatus: paymentStatus,
-		PaymentMethod: *paymentMethod,
-		PaidAt:        helper.PgTimestampNow(),
-	}); err != nil {
-		s.logger.Error(identifier, " - Callbacks - failed to update payment status: %v", err)
+	return s.applyPaymentStatus(ctx, req.ExternalID, paymentStatus, *paymentMethod)
+}
 
-		if errors.Is(err, pgx.ErrNoRows) {
-			return failure.NotFound("payment not found for booking ID: " + req.ExternalID)
-		}
+func (s *paymentService) PaymentRequestCallbacks(ctx context.Context, req dto.CallbackPaymentRequest, token string) (err error) {
+	credentials, err := s.xenditCredentials(ctx, "PaymentRequestCallbacks")
+	if err != nil {
+		return err
+	}
 
-		return failure.InternalError(err)
+	if !validCallbackToken(credentials.CallbackToken, token) {
+		s.logger.Error(identifier, " - PaymentRequestCallbacks - invalid callback token")
+
+		return failure.Unauthorized("invalid callback token")
 	}
 
-	var bookingStatus string
-	if req.Status == constant.PaymentStatusPaid {
-		bookingStatus = constant.BookingStatusConfirmed
+	switch req.Event {
+	case constant.PaymentRequestEventSucceeded, constant.PaymentRequestEventFailed, constant.PaymentRequestEventPending:
+	default:
+		s.logger.Info(identifier, " - PaymentRequestCallbacks - ignoring event: %s", req.Event)
+
+		return nil
 	}
 
-	if err = s.bookingRepo.UpdateBookingStatus(ctx, tx, bookingRepository.UpdateBookingStatusParams{
-		ID:     helper.PgUUID(req.ExternalID),
-		Status: bookingStatus,
-	}); err != nil {
-		s.logger.Error(identifier, " - Callbacks - failed to update booking status: %v", err)
+	paymentStatus := paymentStatusFromPaymentRequest(req.Data.Status)
+	paymentMethod := callbackChannelCode(req.Data.PaymentMethod)
 
-		if errors.Is(err, pgx.ErrNoRows) {
-			return failure.NotFound("booking not found for transaction ID: " + req.ExternalID)
+	return s.applyPaymentStatus(ctx, req.Data.ReferenceID, paymentStatus, paymentMethod)
+}
+
+// applyPaymentStatus updates the payment and its booking after Xendit reports a status change.
+func (s *paymentService) applyPaymentStatus(ctx context.Context, bookingID, paymentStatus, paymentMethod string) (err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error(identifier, " - applyPaymentStatus - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error(identifier, " - applyPaymentStatus - failed to rollback transaction: %v", err)
 		}
+	}(tx, ctx)
+
+	// Only a settled payment records when it was paid; other statuses keep the stored value.
+	var paidAt pgtype.Timestamp
+	if paymentStatus == constant.PaymentStatusPaid {
+		paidAt = helper.PgTimestampNow()
+	}
+
+	rows, err := s.repo.UpdatePaymentStatusByBookingID(ctx, tx, repository.UpdatePaymentStatusByBookingIDParams{
+		BookingID:      helper.PgUUID(bookingID),
+		PaymentStatus:  paymentStatus,
+		PaymentMethod:  paymentMethod,
+		PaidAt:         paidAt,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - applyPaymentStatus - failed to update payment status: %v", err)
 
 		return failure.InternalError(err)
 	}
 
+	// Nothing matches when the payment does not exist or was already paid, the booking is left as it is.
+	if rows == 0 {
+		return failure.NotFound("no open payment found for booking ID: " + bookingID)
+	}
+
+	if bookingStatus := bookingStatusFromPayment(paymentStatus); bookingStatus != "" {
+		if err = s.bookingRepo.UpdateBookingStatus(ctx, tx, bookingRepository.UpdateBookingStatusParams{
+			ID:             helper.PgUUID(bookingID),
+			Status:         bookingStatus,
+			OrganizationID: tenant.FromContext(ctx),
+		}); err != nil {
+			s.logger.Error(identifier, " - applyPaymentStatus - failed to update booking status: %v", err)
+
+			if errors.Is(err, pgx.ErrNoRows) {
+				return failure.NotFound("booking not found for transaction ID: " + bookingID)
+			}
+
+			return failure.InternalError(err)
+		}
+	}
+
 	if err = tx.Commit(ctx); err != nil {
-		s.logger.Error(identifier, " - Callbacks - failed to commit transaction: %v", err)
+		s.logger.Error(identifier, " - applyPaymentStatus - failed to commit transaction: %v", err)
 
 		return failure.InternalError(err)
 	}
 
-	s.logger.Info(identifier, " - Callbacks - payment status updated successfully for booking ID: %s", req.ExternalID)
+	s.logger.Info(identifier, " - applyPaymentStatus - payment status updated successfully for booking ID: %s", bookingID)
 
 	// Send booking confirmation email if payment is successful
-	if req.Status == constant.PaymentStatusPaid {
+	if paymentStatus == constant.PaymentStatusPaid {
 		go func() {
-			if err := s.sendBookingConfirmationEmail(ctx, req.ExternalID, *paymentMethod); err != nil {
-				s.logger.Error(identifier, " - Callbacks - failed to send booking confirmation email: %v", err)
+			if err := s.sendBookingConfirmationEmail(context.WithoutCancel(ctx), bookingID, paymentMethod); err != nil {
+				s.logger.Error(identifier, " - applyPaymentStatus - failed to send booking confirmation email: %v", err)
 			}
 		}()
 	}
@@ -244,6 +394,12 @@ func (s *paymentService) CreatePayments(ctx context.Context, req dto.CreatePayme
 		return "", failure.BadRequestFromString("validation error: " + err.Error())
 	}
 
+	if err := validateAmount(req.Amount); err != nil {
+		s.logger.Error(identifier, " - CreatePayments - invalid amount: %v", err)
+
+		return "", err
+	}
+
 	var paymentStatus string
 	if req.PaymentMethod == constant.PaymentCashMethod {
 		paymentStatus = constant.PaymentStatusPaid
@@ -251,28 +407,43 @@ func (s *paymentService) CreatePayments(ctx context.Context, req dto.CreatePayme
 		paymentStatus = constant.PaymentStatusPending
 	}
 
-	res, err := s.repo.InsertPayment(ctx, s.db, repository.InsertPaymentParams{
-		BookingID:     helper.PgUUID(req.BookingID),
-		PaymentMethod: req.PaymentMethod,
-		PaymentStatus: paymentStatus,
-		TransactionID: req.TransactionID,
-	})
-	if err != nil {
-		s.logger.Error(identifier, " - CreatePayments - failed to create payment: %v", err)
-
-		return "", failure.InternalError(err)
-	}
-
-	return res.String(), nil
+	paymentChannel := req.PaymentChannel
+	if paymentChannel == "" {
+		paymentChannel = req.PaymentMethod
+	}
+
+	return s.insertPayment(ctx, repository.InsertPaymentParams{
+		BookingID:      helper.PgUUID(req.BookingID),
+		PaymentMethod:  req.PaymentMethod,
+		PaymentStatus:  paymentStatus,
+		TransactionID:  req.TransactionID,
+		PaymentChannel: paymentChannel,
+		Description:    helper.PgString(req.Description),
+		CustomerName:   helper.PgString(req.CustomerName),
+		CustomerPhone:  helper.PgString(req.CustomerPhone),
+		Subtotal:       req.Amount.Sub(req.TaxAmount).Pg(),
+		TaxAmount:      req.TaxAmount.Pg(),
+		TotalAmount:    req.Amount.Pg(),
+		Currency:       string(req.Amount.Currency),
+		OrganizationID: tenant.FromContext(ctx),
+	}, paymentItemParams(req.Items, req.Fees))
 }
 
-func (s *paymentService) GetPayments(ctx context.Context, req dto.GetPaymentsRequest) (res dto.PaginatedPaymentResponse, err error) {
+// GetPayments lists payments. Customers only see the payments of their own bookings, staff only
+// those of bookings at the locations they are assigned to.
+func (s *paymentService) GetPayments(ctx context.Context, req dto.GetPaymentsRequest, userID string, permissions []string) (res dto.PaginatedPaymentResponse, err error) {
 	if err := s.validator.Struct(req); err != nil {
 		s.logger.Error(identifier, " - GetPayments - validation error: %v", err)
 
 		return res, failure.BadRequestFromString("validation error: " + err.Error())
 	}
 
+	if req.DateFrom != "" && req.DateTo != "" && req.DateTo < req.DateFrom {
+		s.logger.Error(identifier, " - GetPayments - date_to %s is before date_from %s", req.DateTo, req.DateFrom)
+
+		return res, failure.BadRequestFromString("date_to must not be before date_from")
+	}
+
 	// Set default pagination values
 	page := req.Page
 	if page <= 0 {
@@ -286,9 +457,48 @@ func (s *paymentService) GetPayments(ctx context.Context, req dto.GetPaymentsReq
 
 	offset := (page - 1) * limit
 
+	if !slices.Contains(permissions, constant.PermissionPaymentsRead) {
+		bookingIDs, err := s.bookingRepo.GetBookingIDsByUserID(ctx, s.db, bookingRepository.GetBookingIDsByUserIDParams{
+			UserID:         helper.PgUUID(userID),
+			OrganizationID: tenant.FromContext(ctx),
+		})
+		if err != nil {
+			s.logger.Error(identifier, " - GetPayments - failed to get bookings of user: %v", err)
+
+			return res, failure.InternalError(err)
+		}
+
+		return s.getBookingPayments(ctx, req, bookingIDs, limit, offset)
+	}
+
+	scope, err := locationService.ResolveStaffScope(ctx, s.db, s.locationRepo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, " - GetPayments - failed to resolve staff scope: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if req.LocationID != "" {
+		locationID := helper.PgUUID(req.LocationID)
+		if !scope.Allows(locationID) {
+			s.logger.Error(identifier, " - GetPayments - user %s is not assigned to location %s", userID, req.LocationID)
+
+			return res, failure.Forbidden("you are not assigned to this location")
+		}
+
+		return s.getLocationPayments(ctx, req, []pgtype.UUID{locationID}, limit, offset)
+	}
+
+	if !scope.All {
+		return s.getLocationPayments(ctx, req, scope.LocationIDs, limit, offset)
+	}
+
 	totalCount, err := s.repo.CountPayments(ctx, s.db, repository.CountPaymentsParams{
-		Column1: req.PaymentMethod,
-		Column2: req.PaymentStatus,
+		OrganizationID: tenant.FromContext(ctx),
+		PaymentMethod:  req.PaymentMethod,
+		PaymentStatus:  req.PaymentStatus,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
 	})
 	if err != nil {
 		s.logger.Error(identifier, " - GetPayments - failed to count payments: %v", err)
@@ -298,10 +508,13 @@ func (s *paymentService) GetPayments(ctx context.Context, req dto.GetPaymentsReq
 
 	// Get paginated payments
 	payments, err := s.repo.GetPayments(ctx, s.db, repository.GetPaymentsParams{
-		Column1: req.PaymentMethod,
-		Column2: req.PaymentStatus,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		OrganizationID: tenant.FromContext(ctx),
+		PaymentMethod:  req.PaymentMethod,
+		PaymentStatus:  req.PaymentStatus,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
+		LimitCount:     int32(limit),
+		OffsetCount:    int32(offset),
 	})
 	if err != nil {
 		s.logger.Error(identifier, " - GetPayments - failed to get payments: %v", err)
@@ -314,12 +527,81 @@ func (s *paymentService) GetPayments(ctx context.Context, req dto.GetPaymentsReq
 	return res, nil
 }
 
-func (s *paymentService) GetPaymentsByBookingID(ctx context.Context, bookingID string) (res []dto.PaymentResponse, err error) {
+func (s *paymentService) getLocationPayments(ctx context.Context, req dto.GetPaymentsRequest, locationIDs []pgtype.UUID, limit, offset int) (res dto.PaginatedPaymentResponse, err error) {
+	fieldIDs, err := s.fieldRepo.GetFieldIDsByLocationIDs(ctx, s.db, fieldRepository.GetFieldIDsByLocationIDsParams{
+		LocationIds:    locationIDs,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - getLocationPayments - failed to get fields of locations: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	bookingIDs, err := s.bookingRepo.GetBookingIDsByFieldIDs(ctx, s.db, bookingRepository.GetBookingIDsByFieldIDsParams{
+		FieldIds:       fieldIDs,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - getLocationPayments - failed to get bookings of fields: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	return s.getBookingPayments(ctx, req, bookingIDs, limit, offset)
+}
+
+func (s *paymentService) getBookingPayments(ctx context.Context, req dto.GetPaymentsRequest, bookingIDs []pgtype.UUID, limit, offset int) (res dto.PaginatedPaymentResponse, err error) {
+	totalCount, err := s.repo.CountPaymentsForBookings(ctx, s.db, repository.CountPaymentsForBookingsParams{
+		OrganizationID: tenant.FromContext(ctx),
+		BookingIds:     bookingIDs,
+		PaymentMethod:  req.PaymentMethod,
+		PaymentStatus:  req.PaymentStatus,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - getBookingPayments - failed to count payments: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	payments, err := s.repo.GetPaymentsForBookings(ctx, s.db, repository.GetPaymentsForBookingsParams{
+		OrganizationID: tenant.FromContext(ctx),
+		BookingIds:     bookingIDs,
+		PaymentMethod:  req.PaymentMethod,
+		PaymentStatus:  req.PaymentStatus,
+		DateFrom:       helper.PgDate(req.DateFrom),
+		DateTo:         helper.PgDate(req.DateTo),
+		LimitCount:     int32(limit),
+		OffsetCount:    int32(offset),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - getBookingPayments - failed to get payments: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res.FromModel(payments, int(totalCount), limit)
+
+	return res, nil
+}
+
+// GetPaymentsByBookingID lists the payments of a booking. Only the customer who made the booking and
+// staff of its location may read them.
+func (s *paymentService) GetPaymentsByBookingID(ctx context.Context, bookingID, userID string, permissions []string) (res []dto.PaymentResponse, err error) {
 	if bookingID == "" {
 		return res, failure.BadRequestFromString("booking ID is required")
 	}
 
-	payments, err := s.repo.GetPaymentsByBookingID(ctx, s.db, helper.PgUUID(bookingID))
+	if err = s.authorizeBooking(ctx, "GetPaymentsByBookingID", bookingID, userID, permissions); err != nil {
+		return res, err
+	}
+
+	payments, err := s.repo.GetPaymentsByBookingID(ctx, s.db, repository.GetPaymentsByBookingIDParams{
+		BookingID:      helper.PgUUID(bookingID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		s.logger.Error(identifier, " - GetPaymentsByBookingID - failed to get payments: %v", err)
 
@@ -329,15 +611,84 @@ func (s *paymentService) GetPaymentsByBookingID(ctx context.Context, bookingID s
 	paymentResponses := make([]dto.PaymentResponse, len(payments))
 	for i, payment := range payments {
 		paymentResponses[i] = dto.PaymentResponse{}.FromModel(payment)
+
+		items, err := s.repo.GetPaymentItemsByPaymentID(ctx, s.db, payment.ID)
+		if err != nil {
+			s.logger.Error(identifier, " - GetPaymentsByBookingID - failed to get payment items: %v", err)
+
+			return res, failure.InternalError(err)
+		}
+
+		for _, item := range items {
+			paymentResponses[i].Items = append(paymentResponses[i].Items, dto.PaymentItemResponse{}.FromModel(item, payment.Currency))
+		}
 	}
 
 	return paymentResponses, nil
 }
 
+// authorizeBooking lets the owner of a booking through, and staff holding payments:read when the
+// booking is at a location they are assigned to.
+func (s *paymentService) authorizeBooking(ctx context.Context, op, bookingID, userID string, permissions []string) error {
+	booking, err := s.bookingRepo.GetBookingById(ctx, s.db, bookingRepository.GetBookingByIdParams{
+		ID:             helper.PgUUID(bookingID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(identifier, " - "+op+" - booking %s not found", bookingID)
+
+			return failure.NotFound("booking not found")
+		}
+
+		s.logger.Error(identifier, " - "+op+" - failed to get booking: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if booking.UserID.String() == userID {
+		return nil
+	}
+
+	if !slices.Contains(permissions, constant.PermissionPaymentsRead) {
+		s.logger.Error(identifier, " - "+op+" - user %s does not own booking %s", userID, bookingID)
+
+		return failure.Forbidden("you are not allowed to access the payments of this booking")
+	}
+
+	field, err := s.fieldRepo.GetFieldById(ctx, s.db, fieldRepository.GetFieldByIdParams{
+		ID:             booking.FieldID,
+		OrganizationID: tenant.FromContext(ctx),
+	})
+	if err != nil {
+		s.logger.Error(identifier, " - "+op+" - failed to get field of booking: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	scope, err := locationService.ResolveStaffScope(ctx, s.db, s.locationRepo, userID, permissions)
+	if err != nil {
+		s.logger.Error(identifier, " - "+op+" - failed to resolve staff scope: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if !scope.Allows(field.LocationID) {
+		s.logger.Error(identifier, " - "+op+" - user %s is not assigned to location %s", userID, field.LocationID.String())
+
+		return failure.Forbidden("you are not assigned to the location of this booking")
+	}
+
+	return nil
+}
+
 // sendBookingConfirmationEmail sends confirmation email after successful payment
 func (s *paymentService) sendBookingConfirmationEmail(ctx context.Context, bookingID, paymentMethod string) error {
 	// Get booking details
-	booking, err := s.bookingRepo.GetBookingById(ctx, s.db, helper.PgUUID(bookingID))
+	booking, err := s.bookingRepo.GetBookingById(ctx, s.db, bookingRepository.GetBookingByIdParams{
+		ID:             helper.PgUUID(bookingID),
+		OrganizationID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get booking details: %w", err)
 	}
@@ -352,6 +703,8 @@ func (s *paymentService) sendBookingConfirmationEmail(ctx context.Context, booki
 	startTime, _ := helper.PgTimeToString(booking.StartTime)
 	endTime, _ := helper.PgTimeToString(booking.EndTime)
 
+	taxAmount := money.FromPgOrZero(booking.TaxAmount, booking.Currency)
+
 	emailData := mail.BookingConfirmationData{
 		CustomerName:     user.FullName.String,
 		BookingID:        bookingID,
@@ -359,11 +712,64 @@ func (s *paymentService) sendBookingConfirmationEmail(ctx context.Context, booki
 		BookingDate:      booking.BookingDate.Time.Format("2006-01-02"),
 		StartTime:        startTime,
 		EndTime:          endTime,
-		TotalAmount:      helper.FormatAmountFromCents(booking.TotalPrice.Int.Int64()),
+		Subtotal:         money.FromPgOrZero(booking.Subtotal, booking.Currency).String(),
+		TaxAmount:        taxAmount.String(),
+		TotalAmount:      money.FromPgOrZero(booking.TotalPrice, booking.Currency).String(),
 		PaymentMethod:    paymentMethod,
 		ConfirmationDate: time.Now().Format("2006-01-02 15:04:05"),
 	}
 
+	if taxAmount.IsPositive() {
+		emailData.TaxLabel = taxLabel(helper.Float64FromPg(booking.TaxRate), booking.TaxInclusive)
+	}
+
+	var attachments []mail.Attachment
+
+	// A missing receipt should not hold back the confirmation itself
+	pdf, err := s.GetReceipt(ctx, bookingID)
+	if err != nil {
+		s.logger.Error(identifier, " - sendBookingConfirmationEmail - failed to get receipt: %v", err)
+	} else {
+		emailData.HasReceipt = true
+		attachments = append(attachments, mail.Attachment{
+			Filename:    receipt.Filename(bookingID),
+			ContentType: receipt.ContentType,
+			Content:     pdf,
+		})
+	}
+
 	// Send email
-	return s.mailService.SendBookingConfirmationEmail(user.Email, emailData)
+	return s.mailService.SendBookingConfirmationEmail(user.Email, emailData, attachments...)
+}
+
+// xenditCredentials returns the Xendit credentials of the request's organisation. Xendit calls back
+// on the organisation's subdomain, so callbacks are verified against its own token.
+func (s *paymentService) xenditCredentials(ctx context.Context, op string) (config.Xendit, error) {
+	credentials, err := organizationService.XenditCredentials(ctx, s.db, s.organizationRepo, s.box, s.cfg.Xendit, tenant.FromContext(ctx))
+	if err != nil {
+		s.logger.Error(identifier, " - "+op+" - failed to get xendit credentials: %v", err)
+
+		return credentials, failure.InternalError(err)
+	}
+
+	return credentials, nil
+}
+
+func (s *paymentService) xenditClient(ctx context.Context, op string) (*xendit.APIClient, error) {
+	credentials, err := s.xenditCredentials(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return xendit.NewClient(credentials.APIKey), nil
+}
+
+// validateAmount rejects payments below the minimum Xendit accepts for the currency.
+func validateAmount(amount money.Money) error {
+	minimum := money.FromMajor(constant.PaymentMinimumAmount, amount.Currency)
+	if amount.LessThan(minimum) {
+		return failure.BadRequestFromString(fmt.Sprintf("amount must be at least %s", minimum))
+	}
+
+	return nil
 }
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/savioruz/goth/config"
+	bookingMock "github.com/savioruz/goth/internal/domains/bookings/mock"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	fieldMock "github.com/savioruz/goth/internal/domains/fields/mock"
+	fieldRepository "github.com/savioruz/goth/internal/domains/fields/repository"
+	locationMock "github.com/savioruz/goth/internal/domains/locations/mock"
+	"github.com/savioruz/goth/internal/domains/payments/dto"
+	"github.com/savioruz/goth/internal/domains/payments/mock"
+	"github.com/savioruz/goth/internal/domains/payments/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	log "github.com/savioruz/goth/pkg/logger/mock"
+	"github.com/savioruz/goth/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type testDeps struct {
+	pgx      pgxmock.PgxPoolIface
+	querier  *mock.MockQuerier
+	bookings *bookingMock.MockQuerier
+	fields   *fieldMock.MockQuerier
+	location *locationMock.MockQuerier
+}
+
+func setup(t *testing.T) (*paymentService, testDeps) {
+	ctrl := gomock.NewController(t)
+
+	mockPgx, _ := pgxmock.NewPool()
+	deps := testDeps{
+		pgx:      mockPgx,
+		querier:  mock.NewMockQuerier(ctrl),
+		bookings: bookingMock.NewMockQuerier(ctrl),
+		fields:   fieldMock.NewMockQuerier(ctrl),
+		location: locationMock.NewMockQuerier(ctrl),
+	}
+
+	mockLogger := log.NewMockInterface(ctrl)
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+
+	service := New(mockPgx, deps.querier, deps.bookings, nil, deps.fields, deps.location, nil, nil, nil, &config.Config{}, mockLogger, nil, nil)
+
+	return service.(*paymentService), deps
+}
+
+func TestPaymentService_GetPayments(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New().String()
+	bookingIDs := []pgtype.UUID{{Bytes: uuid.New(), Valid: true}}
+
+	t.Run("success: customer only lists the payments of their bookings", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.bookings.EXPECT().
+			GetBookingIDsByUserID(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ bookingRepository.DBTX, arg bookingRepository.GetBookingIDsByUserIDParams) ([]pgtype.UUID, error) {
+				assert.Equal(t, userID, arg.UserID.String())
+
+				return bookingIDs, nil
+			})
+		deps.querier.EXPECT().
+			CountPaymentsForBookings(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.CountPaymentsForBookingsParams) (int64, error) {
+				assert.Equal(t, bookingIDs, arg.BookingIds)
+
+				return 1, nil
+			})
+		deps.querier.EXPECT().
+			GetPaymentsForBookings(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.GetPaymentsForBookingsParams) ([]repository.Payment, error) {
+				assert.Equal(t, bookingIDs, arg.BookingIds)
+
+				return []repository.Payment{{BookingID: bookingIDs[0], Currency: "IDR"}}, nil
+			})
+
+		res, err := service.GetPayments(ctx, dto.GetPaymentsRequest{}, userID, nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Payments, 1)
+	})
+
+	t.Run("error: staff filtering by a location they are not assigned to", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.location.EXPECT().GetStaffLocationIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return([]pgtype.UUID{{Bytes: uuid.New(), Valid: true}}, nil)
+
+		_, err := service.GetPayments(ctx, dto.GetPaymentsRequest{LocationID: uuid.New().String()}, userID, []string{constant.PermissionPaymentsRead})
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+func TestPaymentService_applyPaymentStatus(t *testing.T) {
+	ctx := context.Background()
+	bookingID := uuid.New().String()
+
+	t.Run("success: paid payment confirms the booking", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.pgx.ExpectBegin()
+		deps.querier.EXPECT().
+			UpdatePaymentStatusByBookingID(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.DBTX, arg repository.UpdatePaymentStatusByBookingIDParams) (int64, error) {
+				assert.Equal(t, constant.PaymentStatusPaid, arg.PaymentStatus)
+				assert.True(t, arg.PaidAt.Valid)
+
+				return 1, nil
+			})
+		deps.bookings.EXPECT().
+			UpdateBookingStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ bookingRepository.DBTX, arg bookingRepository.UpdateBookingStatusParams) error {
+				assert.Equal(t, constant.BookingStatusConfirmed, arg.Status)
+
+				return nil
+			})
+		deps.pgx.ExpectCommit()
+		deps.pgx.ExpectRollback()
+
+		err := service.applyPaymentStatus(ctx, bookingID, constant.PaymentStatusPaid, "QRIS")
+
+		assert.NoError(t, err)
+		assert.NoError(t, deps.pgx.ExpectationsWereMet())
+	})
+
+	t.Run("error: late expiry of a paid payment leaves the booking", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.pgx.ExpectBegin()
+		deps.querier.EXPECT().UpdatePaymentStatusByBookingID(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil)
+		deps.pgx.ExpectRollback()
+
+		err := service.applyPaymentStatus(ctx, bookingID, constant.PaymentStatusExpired, "QRIS")
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+		assert.NoError(t, deps.pgx.ExpectationsWereMet())
+	})
+}
+
+func TestPaymentService_authorizeBooking(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New().String()
+	staffID := uuid.New().String()
+	bookingID := uuid.New().String()
+	locationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	booking := bookingRepository.Booking{UserID: pgtype.UUID{Bytes: uuid.MustParse(ownerID), Valid: true}}
+	staff := []string{constant.PermissionPaymentsRead}
+
+	expectStaffLocations := func(deps testDeps, locationIDs ...pgtype.UUID) {
+		deps.bookings.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+		deps.fields.EXPECT().GetFieldById(gomock.Any(), gomock.Any(), gomock.Any()).Return(fieldRepository.Field{LocationID: locationID}, nil)
+		deps.location.EXPECT().GetStaffLocationIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(locationIDs, nil)
+	}
+
+	t.Run("success: owner", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.bookings.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+
+		err := service.authorizeBooking(ctx, "test", bookingID, ownerID, nil)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error: not the owner", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.bookings.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(booking, nil)
+
+		err := service.authorizeBooking(ctx, "test", bookingID, uuid.New().String(), nil)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("success: staff assigned to the location", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectStaffLocations(deps, locationID)
+
+		err := service.authorizeBooking(ctx, "test", bookingID, staffID, staff)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error: staff of another location", func(t *testing.T) {
+		service, deps := setup(t)
+
+		expectStaffLocations(deps, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+
+		err := service.authorizeBooking(ctx, "test", bookingID, staffID, staff)
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+		assert.Equal(t, "you are not assigned to the location of this booking", err.Error())
+	})
+
+	t.Run("error: booking not found", func(t *testing.T) {
+		service, deps := setup(t)
+
+		deps.bookings.EXPECT().GetBookingById(gomock.Any(), gomock.Any(), gomock.Any()).Return(bookingRepository.Booking{}, pgx.ErrNoRows)
+
+		err := service.authorizeBooking(ctx, "test", bookingID, ownerID, nil)
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
+
+func TestInvoiceItems(t *testing.T) {
+	item := dto.InvoiceItem{Name: "Field A", Category: "futsal", Hours: 2, UnitPrice: money.FromMajor(150000, money.IDR)}
+
+	t.Run("success", func(t *testing.T) {
+		items, fees, err := invoiceItems(dto.CreatePaymentInvoice{
+			Items:     []dto.InvoiceItem{item},
+			TaxRate:   11,
+			TaxAmount: money.FromMajor(33000, money.IDR),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float32(150000), items[0].Price)
+		assert.Equal(t, float32(33000), fees[0].Value)
+	})
+
+	t.Run("error: amount beyond float32 precision", func(t *testing.T) {
+		item.UnitPrice = money.FromMajor(16777217, money.IDR)
+
+		_, _, err := invoiceItems(dto.CreatePaymentInvoice{Items: []dto.InvoiceItem{item}})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "github.com/savioruz/goth/pkg/gdto"
+import (
+	"github.com/savioruz/goth/pkg/gdto"
+	"github.com/savioruz/goth/pkg/helper"
+)
 
 type UserRegisterRequest struct {
 	Email    string `example:"string@gmail.com" json:"email" validate:"required,email"`
@@ -8,26 +11,98 @@ type UserRegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
 }
 
+// ClientInfo describes the device a session is used from. It is filled by the handler, not the request body.
+type ClientInfo struct {
+	Device    string `json:"-"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func NewClientInfo(ipAddress, userAgent string) ClientInfo {
+	return ClientInfo{
+		Device:    helper.DeviceFromUserAgent(userAgent),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+}
+
 type UserLoginRequest struct {
 	Email    string `example:"string@gmail.com" json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	ClientInfo
+}
+
+// PhoneLoginCodeRequest asks for a login code texted to a verified phone number.
+type PhoneLoginCodeRequest struct {
+	Phone string `example:"+6281234567890" json:"phone" validate:"required,e164"`
+}
+
+// PhoneLoginRequest logs in with the code texted to the phone instead of a password.
+type PhoneLoginRequest struct {
+	Phone string `example:"+6281234567890" json:"phone" validate:"required,e164"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+	ClientInfo
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	ClientInfo
+}
+
+type OAuthExchangeRequest struct {
+	Code string `json:"code" validate:"required"`
+	ClientInfo
+}
+
+// OAuthLinkRequest redeems the link_code from a link flow's callback redirect.
+type OAuthLinkRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAVerifyRequest completes a login that needs a second factor. Code is a code from the
+// authenticator app or one of the recovery codes.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFASetupRequest starts enrolment for an account that must use two-factor authentication to log in.
+type MFASetupRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
 }
 
 type GetUsersRequest struct {
 	gdto.PaginationRequest
-	Email    string `query:"email" json:"email"`
-	FullName string `query:"full_name" json:"full_name"`
-	Level    string `query:"level" json:"level"`
+	Email       string `query:"email" json:"email"`
+	FullName    string `query:"full_name" json:"full_name"`
+	Role        string `query:"role" json:"role"`
+	Deactivated bool   `query:"deactivated" json:"deactivated"`
+}
+
+// CreateUserRequest creates an account for someone else, e.g. staff. They are invited by email to
+// set their password.
+type CreateUserRequest struct {
+	Email string `example:"string@gmail.com" json:"email" validate:"required,email,max=255"`
+	Name  string `json:"name" validate:"required,min=1,max=255"`
+	Role  string `example:"staff" json:"role" validate:"omitempty,max=50"`
 }
 
 type UpdateUserRoleRequest struct {
-	Level string `json:"level" validate:"required,oneof=1 2 9"`
+	Role string `json:"role" validate:"required,max=50"`
 }
 
 type EmailVerificationRequest struct {
 	Token string `query:"token" validate:"required"`
 }
 
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
@@ -40,3 +115,56 @@ type ResetPasswordRequest struct {
 type ValidateResetTokenRequest struct {
 	Token string `query:"token" validate:"required"`
 }
+
+// UpdateProfileRequest changes the fields that are set and leaves the others as they are.
+type UpdateProfileRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=255"`
+}
+
+// ChangePhoneRequest sets a phone number in E.164 format. It is texted a code to verify it. The number
+// can be used to log in, so the current password is required.
+type ChangePhoneRequest struct {
+	Phone    string `example:"+6281234567890" json:"phone" validate:"required,e164"`
+	Password string `json:"password" validate:"required"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// ChangeEmailRequest starts an email change, which takes effect once the new address is confirmed.
+type ChangeEmailRequest struct {
+	Email    string `example:"string@gmail.com" json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeleteAccountRequest confirms the deletion with the current password. Accounts that only sign in
+// through an OAuth provider have none and leave it empty.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type ExportRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json zip"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `example:"front_desk" json:"name" validate:"required,min=2,max=50,lowercase"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `example:"bookings:read_any" json:"permissions" validate:"dive,required"`
+}
+
+// UpdateRoleRequest changes the fields that are set. Permissions replace the current ones.
+type UpdateRoleRequest struct {
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Permissions *[]string `json:"permissions" validate:"omitempty,dive,required"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	bookingDto "github.com/savioruz/goth/internal/domains/bookings/dto"
+	paymentDto "github.com/savioruz/goth/internal/domains/payments/dto"
 	"github.com/savioruz/goth/internal/domains/user/repository"
 	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/helper"
@@ -12,14 +14,31 @@ type UserRegisterResponse struct {
 }
 
 type UserLoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// MFAToken is returned instead of the tokens when the login needs a second factor, see /auth/mfa/verify
+	MFAToken string `json:"mfa_token,omitempty"`
+	// MFAEnrollmentRequired is set when the account has to set up two-factor authentication first, see /auth/mfa/setup
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+	// RecoveryCodes are returned once, when enrolment completes during login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type UserProfileResponse struct {
-	Email        string `json:"email"`
-	Name         string `json:"name"`
-	ProfileImage string `json:"profile_image"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	ProfileImage  string `json:"profile_image"`
+	Phone         string `json:"phone"`
+	PhoneVerified bool   `json:"phone_verified"`
 }
 
 type OauthGetURLResponse struct {
@@ -27,22 +46,60 @@ type OauthGetURLResponse struct {
 	State string `json:"state"`
 }
 
+// OAuthCallbackResponse carries the one-time code the frontend at RedirectURI exchanges for tokens.
+// Link flows set Linked and the code is a link code, redeemed by the logged-in user instead.
+type OAuthCallbackResponse struct {
+	Code        string `json:"code"`
+	RedirectURI string `json:"redirect_uri"`
+	Linked      bool   `json:"linked"`
+}
+
+type UserIdentityResponse struct {
+	Provider  string `json:"provider"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (u UserIdentityResponse) FromModel(model repository.UserIdentity) UserIdentityResponse {
+	return UserIdentityResponse{
+		Provider:  model.Provider,
+		Email:     model.Email,
+		CreatedAt: model.CreatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
+
 type UserAdminResponse struct {
-	ID           string `json:"id"`
-	Email        string `json:"email"`
-	FullName     string `json:"full_name"`
-	Level        string `json:"level"`
-	ProfileImage string `json:"profile_image,omitempty"`
-	IsVerified   bool   `json:"is_verified"`
-	LastLogin    string `json:"last_login,omitempty"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	FullName      string `json:"full_name"`
+	Role          string `json:"role"`
+	ProfileImage  string `json:"profile_image,omitempty"`
+	IsVerified    bool   `json:"is_verified"`
+	Phone         string `json:"phone,omitempty"`
+	PhoneVerified bool   `json:"phone_verified"`
+	LastLogin     string `json:"last_login,omitempty"`
+	DeactivatedAt string `json:"deactivated_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// ImpersonationResponse carries an access token acting as the user. It cannot be refreshed.
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
 }
 
 type EmailVerificationResponse struct {
 	Message string `json:"message"`
 }
 
+type ResendVerificationResponse struct {
+	Message string `json:"message"`
+}
+
+type PhoneLoginCodeResponse struct {
+	Message string `json:"message"`
+}
+
 type ForgotPasswordResponse struct {
 	Message string `json:"message"`
 }
@@ -81,14 +138,16 @@ func (u UserProfileResponse) ToProfileResponse(user repository.User) UserProfile
 	}
 
 	return UserProfileResponse{
-		Email:        user.Email,
-		Name:         name,
-		ProfileImage: profileImage,
+		Email:         user.Email,
+		Name:          name,
+		ProfileImage:  profileImage,
+		Phone:         user.Phone.String,
+		PhoneVerified: user.PhoneVerified,
 	}
 }
 
 func (u UserAdminResponse) FromModel(model repository.User) UserAdminResponse {
-	var fullName, profileImage, lastLogin string
+	var fullName, profileImage, lastLogin, deactivatedAt string
 
 	if model.FullName.Valid {
 		fullName = model.FullName.String
@@ -102,16 +161,23 @@ func (u UserAdminResponse) FromModel(model repository.User) UserAdminResponse {
 		lastLogin = model.LastLogin.Time.Format(constant.FullDateFormat)
 	}
 
+	if model.DeletedAt.Valid {
+		deactivatedAt = model.DeletedAt.Time.Format(constant.FullDateFormat)
+	}
+
 	return UserAdminResponse{
-		ID:           model.ID.String(),
-		Email:        model.Email,
-		FullName:     fullName,
-		Level:        model.Level,
-		ProfileImage: profileImage,
-		IsVerified:   model.IsVerified.Bool,
-		LastLogin:    lastLogin,
-		CreatedAt:    model.CreatedAt.Time.Format(constant.FullDateFormat),
-		UpdatedAt:    model.UpdatedAt.Time.Format(constant.FullDateFormat),
+		ID:            model.ID.String(),
+		Email:         model.Email,
+		FullName:      fullName,
+		Role:          model.Role,
+		ProfileImage:  profileImage,
+		IsVerified:    model.IsVerified.Bool,
+		Phone:         model.Phone.String,
+		PhoneVerified: model.PhoneVerified,
+		LastLogin:     lastLogin,
+		DeactivatedAt: deactivatedAt,
+		CreatedAt:     model.CreatedAt.Time.Format(constant.FullDateFormat),
+		UpdatedAt:     model.UpdatedAt.Time.Format(constant.FullDateFormat),
 	}
 }
 
@@ -136,3 +202,64 @@ func (p *PaginatedUserResponse) FromModel(users []repository.User, totalItems, l
 		p.Users[i] = UserAdminResponse{}.FromModel(user)
 	}
 }
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	Current    bool   `json:"current"`
+	LastSeenAt string `json:"last_seen_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (s SessionResponse) FromModel(model repository.Session, currentID string) SessionResponse {
+	return SessionResponse{
+		ID:         model.ID.String(),
+		Device:     model.Device.String,
+		IPAddress:  model.IpAddress.String,
+		UserAgent:  model.UserAgent.String,
+		Current:    model.ID.String() == currentID,
+		LastSeenAt: model.LastSeenAt.Time.Format(constant.FullDateFormat),
+		CreatedAt:  model.CreatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
+
+// UserExportResponse is everything stored about a user, for them to download.
+type UserExportResponse struct {
+	ExportedAt string                       `json:"exported_at"`
+	Profile    UserAdminResponse            `json:"profile"`
+	Identities []UserIdentityResponse       `json:"identities"`
+	Sessions   []SessionResponse            `json:"sessions"`
+	Bookings   []bookingDto.BookingResponse `json:"bookings"`
+	Payments   []paymentDto.PaymentResponse `json:"payments"`
+}
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	IsSystem    bool     `json:"is_system"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+func (r RoleResponse) FromModel(model repository.Role, permissions []string) RoleResponse {
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return RoleResponse{
+		Name:        model.Name,
+		Description: model.Description,
+		IsSystem:    model.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   model.CreatedAt.Time.Format(constant.FullDateFormat),
+		UpdatedAt:   model.UpdatedAt.Time.Format(constant.FullDateFormat),
+	}
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
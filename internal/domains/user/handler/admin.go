@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+)
+
+// CreateUser godoc
+// @Summary Create user (requires users:write)
+// @Description Create an account, staff unless another role is given, and email the user an invitation to set their password
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body dto.CreateUserRequest true "Create user request"
+// @Success 201 {object} response.Data[dto.UserAdminResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin [post]
+// @Security BearerAuth
+func (h *Handler) CreateUser(ctx *fiber.Ctx) error {
+	var req dto.CreateUserRequest
+	if err := h.parseAndValidate(ctx, "CreateUser", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	user, err := h.service.CreateUser(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error("http - user - CreateUser - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusCreated, user)
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate user (requires users:write)
+// @Description Prevent the user from logging in and end their sessions, their data is kept
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Data[dto.UserAdminResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/deactivate [post]
+// @Security BearerAuth
+func (h *Handler) DeactivateUser(ctx *fiber.Ctx) error {
+	userID, err := h.adminTarget(ctx, "DeactivateUser")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	actorID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - DeactivateUser - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	user, err := h.service.DeactivateUser(ctx.UserContext(), userID, actorID)
+	if err != nil {
+		h.logger.Error("http - user - DeactivateUser - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, user)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate user (requires users:write)
+// @Description Allow a deactivated user to log in again. Accounts deleted by their owner cannot be reactivated
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Data[dto.UserAdminResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/reactivate [post]
+// @Security BearerAuth
+func (h *Handler) ReactivateUser(ctx *fiber.Ctx) error {
+	userID, err := h.adminTarget(ctx, "ReactivateUser")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	user, err := h.service.ReactivateUser(ctx.UserContext(), userID)
+	if err != nil {
+		h.logger.Error("http - user - ReactivateUser - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, user)
+}
+
+// ForcePasswordReset godoc
+// @Summary Force password reset (requires users:write)
+// @Description Invalidate the user's password, end their sessions and email them a reset link
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/password-reset [post]
+// @Security BearerAuth
+func (h *Handler) ForcePasswordReset(ctx *fiber.Ctx) error {
+	userID, err := h.adminTarget(ctx, "ForcePasswordReset")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.ForcePasswordReset(ctx.UserContext(), userID); err != nil {
+		h.logger.Error("http - user - ForcePasswordReset - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "password reset email sent")
+}
+
+// VerifyUserEmail godoc
+// @Summary Verify user email (requires users:write)
+// @Description Mark the user's email as verified without the verification link
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Data[dto.UserAdminResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/verify-email [post]
+// @Security BearerAuth
+func (h *Handler) VerifyUserEmail(ctx *fiber.Ctx) error {
+	userID, err := h.adminTarget(ctx, "VerifyUserEmail")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	user, err := h.service.VerifyUserEmail(ctx.UserContext(), userID)
+	if err != nil {
+		h.logger.Error("http - user - VerifyUserEmail - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, user)
+}
+
+// Impersonate godoc
+// @Summary Impersonate user (requires users:impersonate)
+// @Description Get a short lived access token acting as the user, for support. Every request made with it is audited
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Data[dto.ImpersonationResponse]
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/impersonate [post]
+// @Security BearerAuth
+func (h *Handler) Impersonate(ctx *fiber.Ctx) error {
+	userID, err := h.adminTarget(ctx, "Impersonate")
+	if err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	impersonatorID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - Impersonate - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	res, err := h.service.Impersonate(ctx.UserContext(), userID, impersonatorID)
+	if err != nil {
+		h.logger.Error("http - user - Impersonate - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+func (h *Handler) adminTarget(ctx *fiber.Ctx, op string) (string, error) {
+	userID := ctx.Params("id")
+
+	if err := h.validator.Var(userID, constant.RequestValidateUUID); err != nil {
+		h.logger.Error("http - user - %s - invalid user ID: %v", op, err)
+
+		return "", failure.BadRequestFromString("invalid user ID")
+	}
+
+	return userID, nil
+}
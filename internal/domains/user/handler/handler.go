@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -10,10 +11,14 @@ import (
 
 	"github.com/savioruz/goth/internal/domains/user/dto"
 	"github.com/savioruz/goth/internal/domains/user/service"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
 )
 
+const exportFormatZip = "zip"
+
 var (
 	ErrEmailNil       = errors.New("email is nil")
 	ErrEmailNotString = errors.New("email is not string")
@@ -33,15 +38,41 @@ func New(s service.UserService, l logger.Interface, v *validator.Validate) *Hand
 	}
 }
 
-func (h *Handler) RegisterRoutes(r fiber.Router) {
+func (h *Handler) RegisterRoutes(r fiber.Router, authn *middleware.Auth) {
 	users := r.Group("/users")
 
-	users.Get("/profile", middleware.Jwt(), h.Profile)
-
-	// Admin routes - only accessible by admin
-	users.Get("/admin", middleware.Jwt(), middleware.AdminOnly(), h.GetAllUsers)
-	users.Get("/admin/:id", middleware.Jwt(), middleware.AdminOnly(), h.GetUserByID)
-	users.Patch("/admin/:id/role", middleware.Jwt(), middleware.AdminOnly(), h.UpdateUserRole)
+	users.Get("/profile", authn.Jwt(), h.Profile)
+	users.Patch("/profile", authn.Jwt(), h.UpdateProfile)
+	users.Delete("/profile", authn.Jwt(), middleware.NoImpersonation(), h.DeleteAccount)
+	users.Get("/export", authn.Jwt(), h.Export)
+	users.Post("/profile/avatar", authn.Jwt(), h.UploadAvatar)
+	users.Post("/profile/password", authn.Jwt(), middleware.NoImpersonation(), h.ChangePassword)
+	users.Post("/profile/email", authn.Jwt(), middleware.NoImpersonation(), h.ChangeEmail)
+	users.Post("/profile/email/confirm", h.ConfirmEmailChange)
+	users.Post("/profile/phone", authn.Jwt(), middleware.NoImpersonation(), h.ChangePhone)
+	users.Post("/profile/phone/verify", authn.Jwt(), middleware.NoImpersonation(), h.VerifyPhone)
+	users.Get("/sessions", authn.Jwt(), middleware.NoImpersonation(), h.GetSessions)
+	users.Delete("/sessions/:id", authn.Jwt(), middleware.NoImpersonation(), h.RevokeSession)
+
+	// Admin routes - only accessible with the users permissions
+	users.Get("/admin", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersRead), h.GetAllUsers)
+	users.Get("/admin/:id", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersRead), h.GetUserByID)
+	users.Patch("/admin/:id/role", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.UpdateUserRole)
+	users.Post("/admin/:id/unlock", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.UnlockUser)
+	users.Post("/admin", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.CreateUser)
+	users.Post("/admin/:id/deactivate", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.DeactivateUser)
+	users.Post("/admin/:id/reactivate", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.ReactivateUser)
+	users.Post("/admin/:id/password-reset", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.ForcePasswordReset)
+	users.Post("/admin/:id/verify-email", authn.Jwt(), middleware.RequirePermission(constant.PermissionUsersWrite), h.VerifyUserEmail)
+	users.Post("/admin/:id/impersonate", authn.Jwt(), middleware.NoImpersonation(), middleware.RequirePermission(constant.PermissionUsersImpersonate), h.Impersonate)
+
+	roles := r.Group("/roles", authn.Jwt(), middleware.RequirePermission(constant.PermissionRolesManage))
+
+	roles.Get("/", h.GetRoles)
+	roles.Get("/permissions", h.GetPermissions)
+	roles.Post("/", h.CreateRole)
+	roles.Patch("/:name", h.UpdateRole)
+	roles.Delete("/:name", h.DeleteRole)
 }
 
 // Profile godoc
@@ -85,14 +116,14 @@ func (h *Handler) Profile(ctx *fiber.Ctx) error {
 }
 
 // GetAllUsers godoc
-// @Summary Get all users (Admin only)
+// @Summary Get all users (requires users:read)
 // @Description Get all users with pagination and filtering
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param email query string false "Filter by email"
 // @Param full_name query string false "Filter by full name"
-// @Param level query string false "Filter by user level"
+// @Param role query string false "Filter by role"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10, max: 100)"
 // @Success 200 {object} response.Data[dto.PaginatedUserResponse]
@@ -110,7 +141,7 @@ func (h *Handler) GetAllUsers(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, err)
 	}
 
-	users, err := h.service.GetAllUsers(ctx.Context(), req)
+	users, err := h.service.GetAllUsers(ctx.UserContext(), req)
 	if err != nil {
 		h.logger.Error("http - user - GetAllUsers - service error: %v", err)
 
@@ -121,7 +152,7 @@ func (h *Handler) GetAllUsers(ctx *fiber.Ctx) error {
 }
 
 // GetUserByID godoc
-// @Summary Get user by ID (Admin only)
+// @Summary Get user by ID (requires users:read)
 // @Description Get user details by ID
 // @Tags users
 // @Accept json
@@ -143,7 +174,7 @@ func (h *Handler) GetUserByID(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, failure.BadRequestFromString("user ID is required"))
 	}
 
-	user, err := h.service.GetUserByID(ctx.Context(), userID)
+	user, err := h.service.GetUserByID(ctx.UserContext(), userID)
 	if err != nil {
 		h.logger.Error("http - user - GetUserByID - service error: %v", err)
 
@@ -154,8 +185,8 @@ func (h *Handler) GetUserByID(ctx *fiber.Ctx) error {
 }
 
 // UpdateUserRole godoc
-// @Summary Update user role (Admin only)
-// @Description Update user role/level
+// @Summary Update user role (requires users:write)
+// @Description Assign a role to the user
 // @Tags users
 // @Accept json
 // @Produce json
@@ -192,7 +223,7 @@ func (h *Handler) UpdateUserRole(ctx *fiber.Ctx) error {
 		return response.WithError(ctx, transformErr)
 	}
 
-	user, err := h.service.UpdateUserRole(ctx.Context(), userID, req)
+	user, err := h.service.UpdateUserRole(ctx.UserContext(), userID, req)
 	if err != nil {
 		h.logger.Error("http - user - UpdateUserRole - service error: %v", err)
 
@@ -201,3 +232,483 @@ func (h *Handler) UpdateUserRole(ctx *fiber.Ctx) error {
 
 	return response.WithJSON(ctx, fiber.StatusOK, user)
 }
+
+// UnlockUser godoc
+// @Summary Unlock user (requires users:write)
+// @Description Lift the lockout of a user after too many failed login attempts
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/admin/{id}/unlock [post]
+// @Security BearerAuth
+func (h *Handler) UnlockUser(ctx *fiber.Ctx) error {
+	userID := ctx.Params("id")
+
+	if err := h.validator.Var(userID, constant.RequestValidateUUID); err != nil {
+		h.logger.Error("http - user - UnlockUser - invalid user ID: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString("invalid user ID"))
+	}
+
+	if err := h.service.UnlockUser(ctx.UserContext(), userID); err != nil {
+		h.logger.Error("http - user - UnlockUser - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "user unlocked")
+}
+
+// GetSessions godoc
+// @Summary Get active sessions
+// @Description List the devices the current user is logged in on
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Data[[]dto.SessionResponse]
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/sessions [get]
+// @Security BearerAuth
+func (h *Handler) GetSessions(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - GetSessions - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	sessionID, _ := ctx.Locals(constant.JwtFieldSession).(string)
+
+	sessions, err := h.service.GetSessions(ctx.UserContext(), userID, sessionID)
+	if err != nil {
+		h.logger.Error("http - user - GetSessions - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Log the current user out of one of their sessions
+// @Tags users
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/sessions/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RevokeSession(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - RevokeSession - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	sessionID := ctx.Params("id")
+	if err := h.validator.Var(sessionID, "required,uuid"); err != nil {
+		h.logger.Error("http - user - RevokeSession - invalid session id: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString("invalid session id format"))
+	}
+
+	if err := h.service.RevokeSession(ctx.UserContext(), userID, sessionID); err != nil {
+		h.logger.Error("http - user - RevokeSession - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "session revoked")
+}
+
+// UpdateProfile godoc
+// @Summary Update user profile
+// @Description Update the profile of the current user, fields left out are unchanged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile body dto.UpdateProfileRequest true "Update profile request"
+// @Success 200 {object} response.Data[dto.UserProfileResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile [patch]
+// @Security BearerAuth
+func (h *Handler) UpdateProfile(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - UpdateProfile - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := h.parseAndValidate(ctx, "UpdateProfile", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	res, err := h.service.UpdateProfile(ctx.UserContext(), userID, req)
+	if err != nil {
+		h.logger.Error("http - user - UpdateProfile - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// UploadAvatar godoc
+// @Summary Upload profile image
+// @Description Replace the profile image of the current user
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Image to upload"
+// @Success 200 {object} response.Data[dto.UserProfileResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/avatar [post]
+// @Security BearerAuth
+func (h *Handler) UploadAvatar(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - UploadAvatar - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	file, err := ctx.FormFile("avatar")
+	if err != nil {
+		h.logger.Error("http - user - UploadAvatar - failed to get form file: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString("no avatar uploaded"))
+	}
+
+	if file.Size > constant.MaxUploadSize {
+		h.logger.Error("http - user - UploadAvatar - file too large: %d bytes", file.Size)
+
+		return response.WithError(ctx, failure.BadRequestFromString(fmt.Sprintf("upload size exceeds %d bytes", constant.MaxUploadSize)))
+	}
+
+	if !helper.IsValidImageType(file.Header.Get("Content-Type")) {
+		h.logger.Error("http - user - UploadAvatar - invalid file type: %s", file.Header.Get("Content-Type"))
+
+		return response.WithError(ctx, failure.BadRequestFromString("invalid file type. Only JPEG, PNG, GIF, and WebP are allowed"))
+	}
+
+	res, err := h.service.UploadAvatar(ctx.UserContext(), userID, file)
+	if err != nil {
+		h.logger.Error("http - user - UploadAvatar - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, res)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the current user and log out their other sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param password body dto.ChangePasswordRequest true "Change password request"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/password [post]
+// @Security BearerAuth
+func (h *Handler) ChangePassword(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - ChangePassword - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	sessionID, _ := ctx.Locals(constant.JwtFieldSession).(string)
+
+	var req dto.ChangePasswordRequest
+	if err := h.parseAndValidate(ctx, "ChangePassword", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.ChangePassword(ctx.UserContext(), userID, sessionID, req); err != nil {
+		h.logger.Error("http - user - ChangePassword - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "password changed")
+}
+
+// ChangeEmail godoc
+// @Summary Change email
+// @Description Send a confirmation link to the new email, which replaces the current one once confirmed
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param email body dto.ChangeEmailRequest true "Change email request"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/email [post]
+// @Security BearerAuth
+func (h *Handler) ChangeEmail(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - ChangeEmail - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	var req dto.ChangeEmailRequest
+	if err := h.parseAndValidate(ctx, "ChangeEmail", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.ChangeEmail(ctx.UserContext(), userID, req); err != nil {
+		h.logger.Error("http - user - ChangeEmail - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "a confirmation link has been sent to the new email")
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Switch the account to the new email with the token from the confirmation link
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token body dto.ConfirmEmailChangeRequest true "Confirm email change request"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/email/confirm [post]
+func (h *Handler) ConfirmEmailChange(ctx *fiber.Ctx) error {
+	var req dto.ConfirmEmailChangeRequest
+	if err := h.parseAndValidate(ctx, "ConfirmEmailChange", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	if err := h.service.ConfirmEmailChange(ctx.UserContext(), req); err != nil {
+		h.logger.Error("http - user - ConfirmEmailChange - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "email changed")
+}
+
+// ChangePhone godoc
+// @Summary Change phone number
+// @Description Set the phone number of the current user and text it a verification code. Requires the current password, accounts without one must set a password first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param phone body dto.ChangePhoneRequest true "Change phone request"
+// @Success 200 {object} response.Data[dto.UserProfileResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/phone [post]
+// @Security BearerAuth
+func (h *Handler) ChangePhone(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - ChangePhone - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	var req dto.ChangePhoneRequest
+	if err := h.parseAndValidate(ctx, "ChangePhone", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	profile, err := h.service.ChangePhone(ctx.UserContext(), userID, req)
+	if err != nil {
+		h.logger.Error("http - user - ChangePhone - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, profile)
+}
+
+// VerifyPhone godoc
+// @Summary Verify phone number
+// @Description Verify the phone number of the current user with the code texted to it
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param code body dto.VerifyPhoneRequest true "Verify phone request"
+// @Success 200 {object} response.Data[dto.UserProfileResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile/phone/verify [post]
+// @Security BearerAuth
+func (h *Handler) VerifyPhone(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - VerifyPhone - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	var req dto.VerifyPhoneRequest
+	if err := h.parseAndValidate(ctx, "VerifyPhone", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	profile, err := h.service.VerifyPhone(ctx.UserContext(), userID, req)
+	if err != nil {
+		h.logger.Error("http - user - VerifyPhone - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, profile)
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Delete the account of the current user. Personal data is anonymised, bookings and payments are kept for accounting
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param account body dto.DeleteAccountRequest true "Delete account request"
+// @Success 200 {object} response.Message
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/profile [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteAccount(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - DeleteAccount - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	// Accounts without a password have nothing to confirm with and may send no body
+	var req dto.DeleteAccountRequest
+	if len(ctx.Body()) > 0 {
+		if err := h.parseAndValidate(ctx, "DeleteAccount", &req); err != nil {
+			return response.WithError(ctx, err)
+		}
+	}
+
+	if err := h.service.DeleteAccount(ctx.UserContext(), userID, req); err != nil {
+		h.logger.Error("http - user - DeleteAccount - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "account deleted")
+}
+
+// Export godoc
+// @Summary Export account data
+// @Description Download the profile, sign-in methods, sessions, bookings and payments of the current user
+// @Tags users
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "Export format" Enums(json, zip)
+// @Success 200 {object} response.Data[dto.UserExportResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /users/export [get]
+// @Security BearerAuth
+func (h *Handler) Export(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(constant.JwtFieldUser).(string)
+	if !ok {
+		h.logger.Error("http - user - Export - invalid user type in context")
+
+		return response.WithError(ctx, constant.ErrInvalidContextUserType)
+	}
+
+	var req dto.ExportRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		h.logger.Error("http - user - Export - query parser error: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString("invalid query parameters"))
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - user - Export - validation error: %v", err)
+
+		return response.WithError(ctx, failure.BadRequestFromString(err.Error()))
+	}
+
+	if req.Format == exportFormatZip {
+		archive, err := h.service.ExportArchive(ctx.UserContext(), userID)
+		if err != nil {
+			h.logger.Error("http - user - Export - service error: %v", err)
+
+			return response.WithError(ctx, err)
+		}
+
+		ctx.Attachment("account-export.zip")
+
+		return ctx.Send(archive)
+	}
+
+	export, err := h.service.Export(ctx.UserContext(), userID)
+	if err != nil {
+		h.logger.Error("http - user - Export - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	ctx.Attachment("account-export.json")
+
+	return response.WithJSON(ctx, fiber.StatusOK, export)
+}
+
+func (h *Handler) parseAndValidate(ctx *fiber.Ctx, op string, req any) error {
+	if err := ctx.BodyParser(req); err != nil {
+		h.logger.Error("http - user - %s - body parser error: %v", op, err)
+
+		return failure.BadRequestFromString("invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Error("http - user - %s - validation error: %v", op, err)
+
+		return failure.BadRequestFromString(err.Error())
+	}
+
+	return nil
+}
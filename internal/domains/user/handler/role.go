@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/savioruz/goth/internal/delivery/http/response"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+)
+
+// GetRoles godoc
+// @Summary Get roles (requires roles:manage)
+// @Description List the roles with their permissions
+// @Tags roles
+// @Produce json
+// @Success 200 {object} response.Data[[]dto.RoleResponse]
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /roles [get]
+// @Security BearerAuth
+func (h *Handler) GetRoles(ctx *fiber.Ctx) error {
+	roles, err := h.service.GetRoles(ctx.UserContext())
+	if err != nil {
+		h.logger.Error("http - user - GetRoles - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, roles)
+}
+
+// GetPermissions godoc
+// @Summary Get permissions (requires roles:manage)
+// @Description List the permissions that can be granted to roles
+// @Tags roles
+// @Produce json
+// @Success 200 {object} response.Data[[]dto.PermissionResponse]
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /roles/permissions [get]
+// @Security BearerAuth
+func (h *Handler) GetPermissions(ctx *fiber.Ctx) error {
+	permissions, err := h.service.GetPermissions(ctx.UserContext())
+	if err != nil {
+		h.logger.Error("http - user - GetPermissions - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, permissions)
+}
+
+// CreateRole godoc
+// @Summary Create role (requires roles:manage)
+// @Description Create a role with the given permissions
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param role body dto.CreateRoleRequest true "Create role request"
+// @Success 201 {object} response.Data[dto.RoleResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /roles [post]
+// @Security BearerAuth
+func (h *Handler) CreateRole(ctx *fiber.Ctx) error {
+	var req dto.CreateRoleRequest
+	if err := h.parseAndValidate(ctx, "CreateRole", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	role, err := h.service.CreateRole(ctx.UserContext(), req)
+	if err != nil {
+		h.logger.Error("http - user - CreateRole - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusCreated, role)
+}
+
+// UpdateRole godoc
+// @Summary Update role (requires roles:manage)
+// @Description Update the description or replace the permissions of a role. Users of the role have to sign in again when its permissions change
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param role body dto.UpdateRoleRequest true "Update role request"
+// @Success 200 {object} response.Data[dto.RoleResponse]
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /roles/{name} [patch]
+// @Security BearerAuth
+func (h *Handler) UpdateRole(ctx *fiber.Ctx) error {
+	var req dto.UpdateRoleRequest
+	if err := h.parseAndValidate(ctx, "UpdateRole", &req); err != nil {
+		return response.WithError(ctx, err)
+	}
+
+	role, err := h.service.UpdateRole(ctx.UserContext(), ctx.Params("name"), req)
+	if err != nil {
+		h.logger.Error("http - user - UpdateRole - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithJSON(ctx, fiber.StatusOK, role)
+}
+
+// DeleteRole godoc
+// @Summary Delete role (requires roles:manage)
+// @Description Delete a role that is not a system role and is not assigned to any user
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} response.Message
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /roles/{name} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteRole(ctx *fiber.Ctx) error {
+	if err := h.service.DeleteRole(ctx.UserContext(), ctx.Params("name")); err != nil {
+		h.logger.Error("http - user - DeleteRole - service error: %v", err)
+
+		return response.WithError(ctx, err)
+	}
+
+	return response.WithMessage(ctx, fiber.StatusOK, "role deleted")
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	bookingDto "github.com/savioruz/goth/internal/domains/bookings/dto"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	paymentDto "github.com/savioruz/goth/internal/domains/payments/dto"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/supabase"
+)
+
+// DeleteAccount soft deletes the user and anonymises their personal data. Bookings and payments
+// are kept for accounting, only the customer details on the payments and the guest details on
+// bookings made before the user had an account are removed. Stored receipts carry the customer
+// details too, they are deleted and rendered again from the anonymised payments when requested.
+func (s *userService) DeleteAccount(ctx context.Context, userID string, req dto.DeleteAccountRequest) error {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - DeleteAccount - user not found: %s", userID)
+
+			return failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - DeleteAccount - failed to get user: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if user.Password.Valid {
+		if err = s.verifyPassword("DeleteAccount", user, req.Password); err != nil {
+			return err
+		}
+	}
+
+	bookings, err := s.bookingRepo.GetAllBookingsByUserId(ctx, s.db, user.ID)
+	if err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to get bookings: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - DeleteAccount - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	deleted, err := s.repo.AnonymizeUser(ctx, tx, user.ID)
+	if err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to anonymize user: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if deleted == 0 {
+		s.logger.Error("service - user - DeleteAccount - user already deleted: %s", userID)
+
+		return failure.NotFound("user not found")
+	}
+
+	if err = s.deleteCredentials(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to delete credentials: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if _, err = s.revokeSessions(ctx, tx, user.ID, pgtype.UUID{}); err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to revoke sessions: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.bookingRepo.AnonymizeGuestBookingsByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to anonymize guest bookings: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if len(bookings) > 0 {
+		bookingIDs := make([]pgtype.UUID, len(bookings))
+		for i, booking := range bookings {
+			bookingIDs[i] = booking.ID
+		}
+
+		if err = s.paymentRepo.AnonymizePaymentsByBookingIDs(ctx, tx, bookingIDs); err != nil {
+			s.logger.Error("service - user - DeleteAccount - failed to anonymize payments: %v", err)
+
+			return failure.InternalError(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to commit transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.revoker.RevokeUser(ctx, user.ID.String()); err != nil {
+		s.logger.Error("service - user - DeleteAccount - failed to revoke user tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	go s.deleteReceipts(context.WithoutCancel(ctx), bookings)
+
+	if user.ProfileImage.Valid && strings.HasPrefix(user.ProfileImage.String, s.storage.GetPublicURL(supabase.AvatarsStoragePath)) {
+		go s.deleteAvatar(context.WithoutCancel(ctx), user.ProfileImage.String)
+	}
+
+	return nil
+}
+
+// Export collects the user's profile, sign-in methods, sessions, bookings and payments.
+func (s *userService) Export(ctx context.Context, userID string) (res dto.UserExportResponse, err error) {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - Export - user not found: %s", userID)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - Export - failed to get user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, s.db, user.ID)
+	if err != nil {
+		s.logger.Error("service - user - Export - failed to get identities: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	sessions, err := s.repo.GetSessionsByUserID(ctx, s.db, user.ID)
+	if err != nil {
+		s.logger.Error("service - user - Export - failed to get sessions: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	bookings, err := s.bookingRepo.GetAllBookingsByUserId(ctx, s.db, user.ID)
+	if err != nil {
+		s.logger.Error("service - user - Export - failed to get bookings: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = dto.UserExportResponse{
+		ExportedAt: time.Now().Format(constant.FullDateFormat),
+		Profile:    dto.UserAdminResponse{}.FromModel(user),
+		Identities: make([]dto.UserIdentityResponse, len(identities)),
+		Sessions:   make([]dto.SessionResponse, len(sessions)),
+		Bookings:   make([]bookingDto.BookingResponse, len(bookings)),
+		Payments:   []paymentDto.PaymentResponse{},
+	}
+
+	for i, identity := range identities {
+		res.Identities[i] = dto.UserIdentityResponse{}.FromModel(identity)
+	}
+
+	for i, session := range sessions {
+		res.Sessions[i] = dto.SessionResponse{}.FromModel(session, "")
+	}
+
+	if len(bookings) == 0 {
+		return res, nil
+	}
+
+	bookingIDs := make([]pgtype.UUID, len(bookings))
+	for i, booking := range bookings {
+		bookingIDs[i] = booking.ID
+		res.Bookings[i] = bookingDto.BookingResponse{}.FromModel(booking)
+	}
+
+	payments, err := s.paymentRepo.GetPaymentsByBookingIDs(ctx, s.db, bookingIDs)
+	if err != nil {
+		s.logger.Error("service - user - Export - failed to get payments: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	for _, payment := range payments {
+		res.Payments = append(res.Payments, paymentDto.PaymentResponse{}.FromModel(payment))
+	}
+
+	return res, nil
+}
+
+// ExportArchive returns the export as a ZIP archive with a JSON file per section.
+func (s *userService) ExportArchive(ctx context.Context, userID string) ([]byte, error) {
+	export, err := s.Export(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{name: "profile.json", data: export.Profile},
+		{name: "identities.json", data: export.Identities},
+		{name: "sessions.json", data: export.Sessions},
+		{name: "bookings.json", data: export.Bookings},
+		{name: "payments.json", data: export.Payments},
+	}
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			s.logger.Error("service - user - ExportArchive - failed to create %s: %v", file.name, err)
+
+			return nil, failure.InternalError(err)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err = encoder.Encode(file.data); err != nil {
+			s.logger.Error("service - user - ExportArchive - failed to write %s: %v", file.name, err)
+
+			return nil, failure.InternalError(err)
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		s.logger.Error("service - user - ExportArchive - failed to close archive: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *userService) deleteReceipts(ctx context.Context, bookings []bookingRepository.Booking) {
+	for _, booking := range bookings {
+		key := supabase.ReceiptKey(booking.OrganizationID.String(), booking.ID.String())
+		if err := s.storage.DeleteObject(ctx, key); err != nil {
+			s.logger.Error("service - user - deleteReceipts - failed to delete receipt %s: %v", key, err)
+		}
+	}
+}
+
+// deleteCredentials removes the user's linked identities and two-factor secrets, along with their
+// outstanding verification, reset and email change links.
+func (s *userService) deleteCredentials(ctx context.Context, db repository.DBTX, userID pgtype.UUID) error {
+	deletes := []func(context.Context, repository.DBTX, pgtype.UUID) error{
+		s.repo.DeleteUserIdentitiesByUserID,
+		s.repo.DeleteUserMFA,
+		s.repo.DeleteMFARecoveryCodes,
+		s.repo.DeleteEmailVerificationsByUserID,
+		s.repo.DeletePasswordResetsByUserID,
+		s.repo.DeleteEmailChangesByUserID,
+	}
+
+	for _, del := range deletes {
+		if err := del(ctx, db, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
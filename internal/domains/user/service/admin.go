@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/jwt"
+	"github.com/savioruz/goth/pkg/tenant"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const passwordTokenLength = 32
+
+// CreateUser creates an account for someone else, staff by default, and invites them by email to
+// set their password. Users created within an organisation's requests are bound to it.
+func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (res dto.UserAdminResponse, err error) {
+	role := req.Role
+	if role == "" {
+		role = constant.UserRoleStaff
+	}
+
+	if _, err = s.repo.GetRole(ctx, s.db, role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - CreateUser - role not found: %s", role)
+
+			return res, failure.BadRequestFromString("role not found")
+		}
+
+		s.logger.Error("service - user - CreateUser - failed to get role: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	password, err := unusablePassword()
+	if err != nil {
+		s.logger.Error("service - user - CreateUser - failed to generate password: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	token, err := helper.GenerateRandomToken(passwordTokenLength)
+	if err != nil {
+		s.logger.Error("service - user - CreateUser - failed to generate invitation token: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - CreateUser - failed to begin transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - CreateUser - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	// The invitation proves the address, so the account starts verified
+	user, err := s.repo.CreateUser(ctx, tx, repository.CreateUserParams{
+		Email:      req.Email,
+		Password:   password,
+		Role:       role,
+		FullName:   helper.PgString(req.Name),
+		IsVerified: helper.PgBool(true),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error("service - user - CreateUser - user already exists: %s", req.Email)
+
+			return res, failure.Conflict("user already exists")
+		}
+
+		s.logger.Error("service - user - CreateUser - failed to create user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if organizationID := tenant.FromContext(ctx); organizationID.Valid {
+		_, err = s.repo.SetUserOrganization(ctx, tx, repository.SetUserOrganizationParams{
+			ID:             user.ID,
+			OrganizationID: organizationID,
+		})
+		if err != nil {
+			s.logger.Error("service - user - CreateUser - failed to set organization: %v", err)
+
+			return res, failure.InternalError(err)
+		}
+	}
+
+	if _, err = s.repo.CreateInvitation(ctx, tx, repository.CreateInvitationParams{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+	}); err != nil {
+		s.logger.Error("service - user - CreateUser - failed to create invitation: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - CreateUser - failed to commit transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	go func() {
+		if err := s.mail.SendInvitationEmail(user.Email, req.Name, token); err != nil {
+			s.logger.Error("service - user - CreateUser - failed to send invitation email: %v", err)
+		}
+	}()
+
+	res = dto.UserAdminResponse{}.FromModel(user)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserCreate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   res.ID,
+		After:      res,
+	})
+
+	return res, nil
+}
+
+// DeactivateUser soft deletes the user so they can no longer log in, and logs them out everywhere.
+// Their data is kept, unlike when they delete their account, so they can be reactivated.
+func (s *userService) DeactivateUser(ctx context.Context, id, actorID string) (res dto.UserAdminResponse, err error) {
+	if id == actorID {
+		s.logger.Error("service - user - DeactivateUser - attempt to deactivate own account: %s", id)
+
+		return res, failure.BadRequestFromString("you cannot deactivate your own account")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - DeactivateUser - failed to begin transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - DeactivateUser - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	user, err := s.repo.DeactivateUser(ctx, tx, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - DeactivateUser - user not found: %s", id)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - DeactivateUser - failed to deactivate user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if _, err = s.revokeSessions(ctx, tx, user.ID, pgtype.UUID{}); err != nil {
+		s.logger.Error("service - user - DeactivateUser - failed to revoke sessions: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - DeactivateUser - failed to commit transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	// Access tokens outlive the sessions, Jwt rejects them once the user is revoked
+	if err = s.revoker.RevokeUser(ctx, user.ID.String()); err != nil {
+		s.logger.Error("service - user - DeactivateUser - failed to revoke user tokens: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserDeactivate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+		Before:     map[string]bool{"active": true},
+		After:      map[string]bool{"active": false},
+	})
+
+	return dto.UserAdminResponse{}.FromModel(user), nil
+}
+
+func (s *userService) ReactivateUser(ctx context.Context, id string) (res dto.UserAdminResponse, err error) {
+	user, err := s.repo.ReactivateUser(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - ReactivateUser - deactivated user not found: %s", id)
+
+			return res, failure.NotFound("deactivated user not found")
+		}
+
+		s.logger.Error("service - user - ReactivateUser - failed to reactivate user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserReactivate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+		Before:     map[string]bool{"active": false},
+		After:      map[string]bool{"active": true},
+	})
+
+	return dto.UserAdminResponse{}.FromModel(user), nil
+}
+
+// ForcePasswordReset replaces the user's password with an unusable one, logs them out everywhere
+// and sends them a reset link, e.g. when their password may have leaked.
+func (s *userService) ForcePasswordReset(ctx context.Context, id string) error {
+	user, err := s.getUser(ctx, "ForcePasswordReset", id)
+	if err != nil {
+		return err
+	}
+
+	if !user.Password.Valid {
+		s.logger.Error("service - user - ForcePasswordReset - user has no password: %s", id)
+
+		return failure.BadRequestFromString("user signs in through an oauth provider and has no password")
+	}
+
+	password, err := unusablePassword()
+	if err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to generate password: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	token, err := helper.GenerateRandomToken(passwordTokenLength)
+	if err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to generate reset token: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - ForcePasswordReset - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	if _, err = s.repo.ResetPassword(ctx, tx, repository.ResetPasswordParams{
+		Password: password,
+		ID:       user.ID,
+	}); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to replace password: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	// Only the link sent now can be used
+	if err = s.repo.DeletePasswordResetsByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to delete reset tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if _, err = s.repo.CreatePasswordReset(ctx, tx, repository.CreatePasswordResetParams{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+	}); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to create reset token: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if _, err = s.revokeSessions(ctx, tx, user.ID, pgtype.UUID{}); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to revoke sessions: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to commit transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.revoker.RevokeUser(ctx, user.ID.String()); err != nil {
+		s.logger.Error("service - user - ForcePasswordReset - failed to revoke user tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	go func() {
+		if err := s.mail.SendPasswordResetEmail(user.Email, user.FullName.String, token); err != nil {
+			s.logger.Error("service - user - ForcePasswordReset - failed to send reset email: %v", err)
+		}
+	}()
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserPasswordReset,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+	})
+
+	return nil
+}
+
+// VerifyUserEmail marks the user's email as verified without the verification link, e.g. when
+// the email never arrived.
+func (s *userService) VerifyUserEmail(ctx context.Context, id string) (res dto.UserAdminResponse, err error) {
+	user, err := s.getUser(ctx, "VerifyUserEmail", id)
+	if err != nil {
+		return res, err
+	}
+
+	if helper.BoolFromPg(user.IsVerified) {
+		return dto.UserAdminResponse{}.FromModel(user), nil
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - VerifyUserEmail - failed to begin transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - VerifyUserEmail - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	if user, err = s.repo.VerifyEmail(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - VerifyUserEmail - failed to verify email: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = s.attachGuestBookings(ctx, tx, "VerifyUserEmail", user.ID, user.Email); err != nil {
+		return res, err
+	}
+
+	if err = s.repo.DeleteEmailVerificationsByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - VerifyUserEmail - failed to delete verification tokens: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - VerifyUserEmail - failed to commit transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserEmailVerify,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+		Before:     map[string]bool{"is_verified": false},
+		After:      map[string]bool{"is_verified": true},
+	})
+
+	return dto.UserAdminResponse{}.FromModel(user), nil
+}
+
+// Impersonate issues an access token acting as the user, for support. The token has no session,
+// so it expires with the access token lifetime. Requests made with it are recorded in the audit
+// log along with the impersonator.
+func (s *userService) Impersonate(ctx context.Context, id, impersonatorID string) (res dto.ImpersonationResponse, err error) {
+	if id == impersonatorID {
+		s.logger.Error("service - user - Impersonate - attempt to impersonate self: %s", id)
+
+		return res, failure.BadRequestFromString("you cannot impersonate yourself")
+	}
+
+	user, err := s.getUser(ctx, "Impersonate", id)
+	if err != nil {
+		return res, err
+	}
+
+	permissions, err := s.repo.GetRolePermissions(ctx, s.db, user.Role)
+	if err != nil {
+		s.logger.Error("service - user - Impersonate - failed to get role permissions: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	// Otherwise an impersonation could be used to start another one under a different name
+	if slices.Contains(permissions, constant.PermissionUsersImpersonate) {
+		s.logger.Error("service - user - Impersonate - user %s can impersonate others", id)
+
+		return res, failure.Forbidden("users who can impersonate cannot be impersonated")
+	}
+
+	token, err := jwt.GenerateImpersonationToken(user.ID.String(), user.Email, user.Role, user.OrganizationID.String(), permissions, impersonatorID)
+	if err != nil {
+		s.logger.Error("service - user - Impersonate - failed to generate token: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.logger.Info("service - user - Impersonate - user %s impersonates %s", impersonatorID, id)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserImpersonate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+	})
+
+	return dto.ImpersonationResponse{AccessToken: token}, nil
+}
+
+func (s *userService) getUser(ctx context.Context, op, id string) (repository.User, error) {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - %s - user not found: %s", op, id)
+
+			return user, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - %s - failed to get user: %v", op, err)
+
+		return user, failure.InternalError(err)
+	}
+
+	return user, nil
+}
+
+// unusablePassword is the hash of a random password nobody knows. Accounts without a password sign
+// in through OAuth providers only, these still get to set one through a reset link.
+func unusablePassword() (pgtype.Text, error) {
+	secret, err := helper.GenerateRandomToken(passwordTokenLength)
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+
+	return helper.PgString(string(hash)), nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/otp"
+)
+
+// ChangePhone sets the user's phone number and texts it a code. The number is unverified, and cannot
+// be used to log in, until the code is entered.
+func (s *userService) ChangePhone(ctx context.Context, userID string, req dto.ChangePhoneRequest) (res dto.UserProfileResponse, err error) {
+	user, err := s.checkPassword(ctx, "ChangePhone", userID, req.Password)
+	if err != nil {
+		return res, err
+	}
+
+	if user.PhoneVerified && user.Phone.String == req.Phone {
+		return res.ToProfileResponse(user), nil
+	}
+
+	// Sending first keeps the current number, verified or not, when no code can be sent yet
+	if err = s.otp.Send(ctx, constant.OTPPurposePhoneVerify, req.Phone); err != nil {
+		if errors.Is(err, otp.ErrCooldown) {
+			s.logger.Error("service - user - ChangePhone - code sent recently to %s", req.Phone)
+
+			return res, failure.TooManyRequests("a code was sent recently, try again later")
+		}
+
+		s.logger.Error("service - user - ChangePhone - failed to send code: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	user, err = s.repo.SetUserPhone(ctx, s.db, repository.SetUserPhoneParams{
+		ID:    user.ID,
+		Phone: helper.PgString(req.Phone),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - ChangePhone - user not found: %s", userID)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - ChangePhone - failed to set phone: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	return res.ToProfileResponse(user), nil
+}
+
+func (s *userService) VerifyPhone(ctx context.Context, userID string, req dto.VerifyPhoneRequest) (res dto.UserProfileResponse, err error) {
+	user, err := s.getUser(ctx, "VerifyPhone", userID)
+	if err != nil {
+		return res, err
+	}
+
+	if !user.Phone.Valid {
+		s.logger.Error("service - user - VerifyPhone - user has no phone: %s", userID)
+
+		return res, failure.BadRequestFromString("no phone number to verify")
+	}
+
+	if user.PhoneVerified {
+		return res.ToProfileResponse(user), nil
+	}
+
+	if err = s.otp.Verify(ctx, constant.OTPPurposePhoneVerify, user.Phone.String, req.Code); err != nil {
+		switch {
+		case errors.Is(err, otp.ErrInvalidCode):
+			s.logger.Error("service - user - VerifyPhone - invalid code for %s", userID)
+
+			return res, failure.BadRequestFromString("invalid or expired code")
+		case errors.Is(err, otp.ErrTooManyAttempts):
+			s.logger.Error("service - user - VerifyPhone - too many attempts for %s", userID)
+
+			return res, failure.TooManyRequests("too many attempts, request a new code")
+		default:
+			s.logger.Error("service - user - VerifyPhone - failed to verify code: %v", err)
+
+			return res, failure.InternalError(err)
+		}
+	}
+
+	// The number may have changed since the code was sent, the code only verifies the number it was sent to
+	user, err = s.repo.VerifyUserPhone(ctx, s.db, repository.VerifyUserPhoneParams{
+		ID:    user.ID,
+		Phone: user.Phone,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error("service - user - VerifyPhone - phone verified by another user: %s", userID)
+
+			return res, failure.Conflict("phone number is already in use")
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - VerifyPhone - phone changed during verification: %s", userID)
+
+			return res, failure.BadRequestFromString("invalid or expired code")
+		}
+
+		s.logger.Error("service - user - VerifyPhone - failed to verify phone: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	return res.ToProfileResponse(user), nil
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/supabase"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailChangeTokenLength = 32
+
+	errNoPassword = "account signs in through an OAuth provider and has no password"
+)
+
+func (s *userService) UpdateProfile(ctx context.Context, userID string, req dto.UpdateProfileRequest) (res dto.UserProfileResponse, err error) {
+	var fullName pgtype.Text
+	if req.Name != nil {
+		fullName = helper.PgString(strings.TrimSpace(*req.Name))
+	}
+
+	user, err := s.repo.UpdateUserProfile(ctx, s.db, repository.UpdateUserProfileParams{
+		ID:       helper.PgUUID(userID),
+		FullName: fullName,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UpdateProfile - user not found: %s", userID)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - UpdateProfile - failed to update profile: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	return res.ToProfileResponse(user), nil
+}
+
+// UploadAvatar stores the image as the user's profile image and removes the one it replaces.
+func (s *userService) UploadAvatar(ctx context.Context, userID string, file *multipart.FileHeader) (res dto.UserProfileResponse, err error) {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UploadAvatar - user not found: %s", userID)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - UploadAvatar - failed to get user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	fileHandle, err := file.Open()
+	if err != nil {
+		s.logger.Error("service - user - UploadAvatar - failed to open file %s: %v", file.Filename, err)
+
+		return res, failure.InternalError(err)
+	}
+	defer fileHandle.Close()
+
+	url, err := s.storage.UploadFileTo(ctx, fileHandle, supabase.AvatarsStoragePath, file.Filename)
+	if err != nil {
+		s.logger.Error("service - user - UploadAvatar - failed to upload file %s: %v", file.Filename, err)
+
+		return res, failure.InternalError(err)
+	}
+
+	updated, err := s.repo.UpdateUserProfile(ctx, s.db, repository.UpdateUserProfileParams{
+		ID:           user.ID,
+		ProfileImage: helper.PgString(url),
+	})
+	if err != nil {
+		s.logger.Error("service - user - UploadAvatar - failed to update profile image: %v", err)
+
+		s.deleteAvatar(context.WithoutCancel(ctx), url)
+
+		return res, failure.InternalError(err)
+	}
+
+	// Images from an OAuth provider are not ours to delete
+	if user.ProfileImage.Valid && strings.HasPrefix(user.ProfileImage.String, s.storage.GetPublicURL(supabase.AvatarsStoragePath)) {
+		go s.deleteAvatar(context.WithoutCancel(ctx), user.ProfileImage.String)
+	}
+
+	s.invalidateProfile(ctx, updated.Email)
+
+	return res.ToProfileResponse(updated), nil
+}
+
+// ChangePassword replaces the password after checking the current one, and signs the user out
+// everywhere except the current session.
+func (s *userService) ChangePassword(ctx context.Context, userID, currentSessionID string, req dto.ChangePasswordRequest) error {
+	user, err := s.checkPassword(ctx, "ChangePassword", userID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		s.logger.Error("service - user - ChangePassword - new password equals the current one")
+
+		return failure.BadRequestFromString("new password must be different from the current password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to hash password: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - ChangePassword - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	if _, err = s.repo.ResetPassword(ctx, tx, repository.ResetPasswordParams{
+		ID:       user.ID,
+		Password: helper.PgString(string(hashedPassword)),
+	}); err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to update password: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	// A reset link requested before the change must not undo it
+	if err = s.repo.DeletePasswordResetsByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to delete reset tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	var keep pgtype.UUID
+	if currentSessionID != "" {
+		keep = helper.PgUUID(currentSessionID)
+	}
+
+	sessionIDs, err := s.revokeSessions(ctx, tx, user.ID, keep)
+	if err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to revoke sessions: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - ChangePassword - failed to commit transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err = s.revoker.RevokeSession(ctx, sessionID.String()); err != nil {
+			s.logger.Error("service - user - ChangePassword - failed to revoke access tokens: %v", err)
+
+			return failure.InternalError(err)
+		}
+	}
+
+	return nil
+}
+
+// ChangeEmail sends a confirmation link to the new address. The account keeps its current
+// address until the link is used.
+func (s *userService) ChangeEmail(ctx context.Context, userID string, req dto.ChangeEmailRequest) error {
+	user, err := s.checkPassword(ctx, "ChangeEmail", userID, req.Password)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(user.Email, req.Email) {
+		s.logger.Error("service - user - ChangeEmail - new email equals the current one")
+
+		return failure.BadRequestFromString("new email must be different from the current email")
+	}
+
+	_, err = s.repo.GetUserByEmail(ctx, s.db, req.Email)
+	if err == nil {
+		s.logger.Error("service - user - ChangeEmail - email already in use")
+
+		return failure.Conflict("email already in use")
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("service - user - ChangeEmail - failed to get user by email: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	token, err := helper.GenerateRandomToken(emailChangeTokenLength)
+	if err != nil {
+		s.logger.Error("service - user - ChangeEmail - failed to generate token: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - ChangeEmail - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - ChangeEmail - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	// Only the latest requested address can be confirmed
+	if err = s.repo.DeleteEmailChangesByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - ChangeEmail - failed to delete previous email changes: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if _, err = s.repo.CreateEmailChange(ctx, tx, repository.CreateEmailChangeParams{
+		UserID:    user.ID,
+		NewEmail:  req.Email,
+		TokenHash: helper.HashToken(token),
+	}); err != nil {
+		s.logger.Error("service - user - ChangeEmail - failed to create email change: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - ChangeEmail - failed to commit transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	go func() {
+		if err := s.mail.SendEmailChangeEmail(req.Email, user.FullName.String, token); err != nil {
+			s.logger.Error("service - user - ChangeEmail - failed to send confirmation email: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the confirmed address. Access tokens carry the email,
+// so the user's current ones are revoked and picked up again on refresh.
+func (s *userService) ConfirmEmailChange(ctx context.Context, req dto.ConfirmEmailChangeRequest) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - ConfirmEmailChange - failed to begin transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - ConfirmEmailChange - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	change, err := s.repo.GetEmailChangeByTokenHash(ctx, tx, helper.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - ConfirmEmailChange - invalid or expired token")
+
+			return failure.BadRequestFromString("invalid or expired email change token")
+		}
+
+		s.logger.Error("service - user - ConfirmEmailChange - failed to get email change: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, tx, change.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - ConfirmEmailChange - user not found: %s", change.UserID.String())
+
+			return failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - ConfirmEmailChange - failed to get user: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if _, err = s.repo.UpdateUserEmail(ctx, tx, repository.UpdateUserEmailParams{
+		ID:    user.ID,
+		Email: change.NewEmail,
+	}); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error("service - user - ConfirmEmailChange - email already in use")
+
+			return failure.Conflict("email already in use")
+		}
+
+		s.logger.Error("service - user - ConfirmEmailChange - failed to update email: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.attachGuestBookings(ctx, tx, "ConfirmEmailChange", user.ID, change.NewEmail); err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteEmailChangesByUserID(ctx, tx, user.ID); err != nil {
+		s.logger.Error("service - user - ConfirmEmailChange - failed to delete email changes: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - ConfirmEmailChange - failed to commit transaction: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.revoker.RevokeUser(ctx, user.ID.String()); err != nil {
+		s.logger.Error("service - user - ConfirmEmailChange - failed to revoke user tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	s.invalidateProfile(ctx, user.Email)
+
+	return nil
+}
+
+// checkPassword loads the user and rejects the request unless password is their current one.
+func (s *userService) checkPassword(ctx context.Context, op, userID, password string) (repository.User, error) {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - %s - user not found: %s", op, userID)
+
+			return user, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - %s - failed to get user: %v", op, err)
+
+		return user, failure.InternalError(err)
+	}
+
+	if !user.Password.Valid {
+		s.logger.Error("service - user - %s - user has no password", op)
+
+		return user, failure.BadRequestFromString(errNoPassword)
+	}
+
+	return user, s.verifyPassword(op, user, password)
+}
+
+func (s *userService) verifyPassword(op string, user repository.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password.String), []byte(password)); err != nil {
+		s.logger.Error("service - user - %s - invalid password", op)
+
+		return failure.BadRequestFromString("current password is incorrect")
+	}
+
+	return nil
+}
+
+// revokeSessions revokes the user's sessions except keep, along with their refresh tokens, and returns
+// the revoked session IDs so their access tokens can be revoked once the transaction commits.
+func (s *userService) revokeSessions(ctx context.Context, db repository.DBTX, userID, keep pgtype.UUID) ([]pgtype.UUID, error) {
+	sessionIDs, err := s.repo.RevokeOtherSessions(ctx, db, repository.RevokeOtherSessionsParams{
+		UserID: userID,
+		KeepID: keep,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err = s.repo.RevokeRefreshTokenFamily(ctx, db, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessionIDs, nil
+}
+
+func (s *userService) deleteAvatar(ctx context.Context, url string) {
+	if err := s.storage.DeleteFile(ctx, url); err != nil {
+		s.logger.Error("service - user - deleteAvatar - failed to delete avatar %s: %v", url, err)
+	}
+}
+
+func (s *userService) invalidateProfile(ctx context.Context, email string) {
+	if err := s.cache.Delete(ctx, fmt.Sprintf(cacheGetUserKey, email)); err != nil {
+		s.logger.Error("service - user - invalidateProfile - failed to delete cache: %v", err)
+	}
+}
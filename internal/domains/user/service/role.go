@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
+	"github.com/savioruz/goth/pkg/failure"
+)
+
+func (s *userService) GetRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := s.repo.GetRoles(ctx, s.db)
+	if err != nil {
+		s.logger.Error("service - user - GetRoles - failed to get roles: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	rolePermissions, err := s.repo.GetAllRolePermissions(ctx, s.db)
+	if err != nil {
+		s.logger.Error("service - user - GetRoles - failed to get role permissions: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	permissions := make(map[string][]string, len(roles))
+	for _, rp := range rolePermissions {
+		permissions[rp.Role] = append(permissions[rp.Role], rp.Permission)
+	}
+
+	res := make([]dto.RoleResponse, len(roles))
+	for i, role := range roles {
+		res[i] = dto.RoleResponse{}.FromModel(role, permissions[role.Name])
+	}
+
+	return res, nil
+}
+
+func (s *userService) GetPermissions(ctx context.Context) ([]dto.PermissionResponse, error) {
+	permissions, err := s.repo.GetPermissions(ctx, s.db)
+	if err != nil {
+		s.logger.Error("service - user - GetPermissions - failed to get permissions: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	res := make([]dto.PermissionResponse, len(permissions))
+	for i, permission := range permissions {
+		res[i] = dto.PermissionResponse{Name: permission.Name, Description: permission.Description}
+	}
+
+	return res, nil
+}
+
+func (s *userService) CreateRole(ctx context.Context, req dto.CreateRoleRequest) (res dto.RoleResponse, err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - CreateRole - failed to begin transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - CreateRole - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	role, err := s.repo.CreateRole(ctx, tx, repository.CreateRoleParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error("service - user - CreateRole - role already exists: %s", req.Name)
+
+			return res, failure.Conflict("role already exists")
+		}
+
+		s.logger.Error("service - user - CreateRole - failed to create role: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = s.setRolePermissions(ctx, tx, role.Name, req.Permissions); err != nil {
+		return res, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - CreateRole - failed to commit transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	res = dto.RoleResponse{}.FromModel(role, req.Permissions)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionRoleCreate,
+		EntityType: constant.AuditEntityRole,
+		EntityID:   role.Name,
+		After:      res,
+	})
+
+	return res, nil
+}
+
+// UpdateRole changes a role. When its permissions change, the tokens of its users are revoked so
+// they pick up the new permissions on their next refresh.
+func (s *userService) UpdateRole(ctx context.Context, name string, req dto.UpdateRoleRequest) (res dto.RoleResponse, err error) {
+	// Taking permissions away from admins could leave nobody able to give them back
+	if name == constant.UserRoleAdmin && req.Permissions != nil {
+		s.logger.Error("service - user - UpdateRole - attempt to change admin permissions")
+
+		return res, failure.Forbidden("permissions of the admin role cannot be changed")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.logger.Error("service - user - UpdateRole - failed to begin transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			s.logger.Error("service - user - UpdateRole - failed to rollback transaction: %v", err)
+		}
+	}(tx, ctx)
+
+	role, err := s.repo.GetRole(ctx, tx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UpdateRole - role not found: %s", name)
+
+			return res, failure.NotFound("role not found")
+		}
+
+		s.logger.Error("service - user - UpdateRole - failed to get role: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	beforePermissions, err := s.repo.GetRolePermissions(ctx, tx, name)
+	if err != nil {
+		s.logger.Error("service - user - UpdateRole - failed to get role permissions: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	before := dto.RoleResponse{}.FromModel(role, beforePermissions)
+
+	if req.Description != nil {
+		role, err = s.repo.UpdateRole(ctx, tx, repository.UpdateRoleParams{
+			Name:        name,
+			Description: *req.Description,
+		})
+		if err != nil {
+			s.logger.Error("service - user - UpdateRole - failed to update role: %v", err)
+
+			return res, failure.InternalError(err)
+		}
+	}
+
+	if req.Permissions != nil {
+		if err = s.setRolePermissions(ctx, tx, name, *req.Permissions); err != nil {
+			return res, err
+		}
+	}
+
+	permissions, err := s.repo.GetRolePermissions(ctx, tx, name)
+	if err != nil {
+		s.logger.Error("service - user - UpdateRole - failed to get role permissions: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.logger.Error("service - user - UpdateRole - failed to commit transaction: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	if req.Permissions != nil {
+		if err = s.revokeRoleUsers(ctx, name); err != nil {
+			return res, err
+		}
+	}
+
+	res = dto.RoleResponse{}.FromModel(role, permissions)
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionRoleUpdate,
+		EntityType: constant.AuditEntityRole,
+		EntityID:   name,
+		Before:     before,
+		After:      res,
+	})
+
+	return res, nil
+}
+
+// DeleteRole deletes a role that is not a system role and no user has.
+func (s *userService) DeleteRole(ctx context.Context, name string) error {
+	deleted, err := s.repo.DeleteRole(ctx, s.db, name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			s.logger.Error("service - user - DeleteRole - role is assigned to users: %s", name)
+
+			return failure.Conflict("role is assigned to users")
+		}
+
+		s.logger.Error("service - user - DeleteRole - failed to delete role: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if deleted > 0 {
+		s.auditor.Record(ctx, audit.Entry{
+			Action:     constant.AuditActionRoleDelete,
+			EntityType: constant.AuditEntityRole,
+			EntityID:   name,
+		})
+
+		return nil
+	}
+
+	// Nothing was deleted, either the role does not exist or it is a system role
+	if _, err = s.repo.GetRole(ctx, s.db, name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - DeleteRole - role not found: %s", name)
+
+			return failure.NotFound("role not found")
+		}
+
+		s.logger.Error("service - user - DeleteRole - failed to get role: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	s.logger.Error("service - user - DeleteRole - attempt to delete system role: %s", name)
+
+	return failure.Forbidden("system roles cannot be deleted")
+}
+
+func (s *userService) setRolePermissions(ctx context.Context, db repository.DBTX, role string, permissions []string) error {
+	if err := s.repo.DeleteRolePermissions(ctx, db, role); err != nil {
+		s.logger.Error("service - user - setRolePermissions - failed to delete role permissions: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	err := s.repo.AddRolePermissions(ctx, db, repository.AddRolePermissionsParams{
+		Role:        role,
+		Permissions: permissions,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			s.logger.Error("service - user - setRolePermissions - unknown permission: %v", permissions)
+
+			return failure.BadRequestFromString("unknown permission")
+		}
+
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			s.logger.Error("service - user - setRolePermissions - duplicate permission: %v", permissions)
+
+			return failure.BadRequestFromString("duplicate permission")
+		}
+
+		s.logger.Error("service - user - setRolePermissions - failed to add role permissions: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
+
+func (s *userService) revokeRoleUsers(ctx context.Context, role string) error {
+	userIDs, err := s.repo.GetUserIDsByRole(ctx, s.db, role)
+	if err != nil {
+		s.logger.Error("service - user - revokeRoleUsers - failed to get users of role: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	for _, userID := range userIDs {
+		if err = s.revoker.RevokeUser(ctx, userID.String()); err != nil {
+			s.logger.Error("service - user - revokeRoleUsers - failed to revoke user tokens: %v", err)
+
+			return failure.InternalError(err)
+		}
+	}
+
+	return nil
+}
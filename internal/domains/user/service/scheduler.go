@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/postgres"
+)
+
+type SchedulerService struct {
+	db     postgres.PgxIface
+	repo   repository.Querier
+	logger logger.Interface
+}
+
+func NewSchedulerService(db postgres.PgxIface, l logger.Interface) *SchedulerService {
+	return &SchedulerService{
+		db:     db,
+		repo:   repository.New(),
+		logger: l,
+	}
+}
+
+// CleanupExpiredTokens deletes expired verification, password reset, email change and refresh tokens,
+// and the sessions left without refresh tokens, which can no longer be used.
+func (s *SchedulerService) CleanupExpiredTokens(ctx context.Context) error {
+	verifications, err := s.repo.DeleteExpiredEmailVerifications(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	resets, err := s.repo.DeleteExpiredPasswordResets(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	emailChanges, err := s.repo.DeleteExpiredEmailChanges(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	refreshTokens, err := s.repo.DeleteExpiredRefreshTokens(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := s.repo.DeleteInactiveSessions(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("scheduler - user - cleaned up %d email verifications, %d password resets, %d email changes, %d refresh tokens and %d sessions",
+		verifications, resets, emailChanges, refreshTokens, sessions)
+
+	return nil
+}
@@ -4,17 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/savioruz/goth/config"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	paymentRepository "github.com/savioruz/goth/internal/domains/payments/repository"
 	"github.com/savioruz/goth/internal/domains/user/dto"
 	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
 	"github.com/savioruz/goth/pkg/helper"
 	"github.com/savioruz/goth/pkg/logger"
+	"github.com/savioruz/goth/pkg/loginguard"
+	"github.com/savioruz/goth/pkg/mail"
+	"github.com/savioruz/goth/pkg/otp"
 	"github.com/savioruz/goth/pkg/postgres"
 	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/session"
+	"github.com/savioruz/goth/pkg/supabase"
 )
 
 type UserService interface {
@@ -22,6 +33,30 @@ type UserService interface {
 	GetAllUsers(ctx context.Context, req dto.GetUsersRequest) (dto.PaginatedUserResponse, error)
 	GetUserByID(ctx context.Context, userID string) (dto.UserAdminResponse, error)
 	UpdateUserRole(ctx context.Context, id string, req dto.UpdateUserRoleRequest) (dto.UserAdminResponse, error)
+	GetSessions(ctx context.Context, userID, currentSessionID string) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	UnlockUser(ctx context.Context, id string) error
+	CreateUser(ctx context.Context, req dto.CreateUserRequest) (dto.UserAdminResponse, error)
+	DeactivateUser(ctx context.Context, id, actorID string) (dto.UserAdminResponse, error)
+	ReactivateUser(ctx context.Context, id string) (dto.UserAdminResponse, error)
+	ForcePasswordReset(ctx context.Context, id string) error
+	VerifyUserEmail(ctx context.Context, id string) (dto.UserAdminResponse, error)
+	Impersonate(ctx context.Context, id, impersonatorID string) (dto.ImpersonationResponse, error)
+	UpdateProfile(ctx context.Context, userID string, req dto.UpdateProfileRequest) (dto.UserProfileResponse, error)
+	UploadAvatar(ctx context.Context, userID string, file *multipart.FileHeader) (dto.UserProfileResponse, error)
+	ChangePassword(ctx context.Context, userID, currentSessionID string, req dto.ChangePasswordRequest) error
+	ChangeEmail(ctx context.Context, userID string, req dto.ChangeEmailRequest) error
+	ChangePhone(ctx context.Context, userID string, req dto.ChangePhoneRequest) (dto.UserProfileResponse, error)
+	VerifyPhone(ctx context.Context, userID string, req dto.VerifyPhoneRequest) (dto.UserProfileResponse, error)
+	ConfirmEmailChange(ctx context.Context, req dto.ConfirmEmailChangeRequest) error
+	DeleteAccount(ctx context.Context, userID string, req dto.DeleteAccountRequest) error
+	Export(ctx context.Context, userID string) (dto.UserExportResponse, error)
+	ExportArchive(ctx context.Context, userID string) ([]byte, error)
+	GetRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	GetPermissions(ctx context.Context) ([]dto.PermissionResponse, error)
+	CreateRole(ctx context.Context, req dto.CreateRoleRequest) (dto.RoleResponse, error)
+	UpdateRole(ctx context.Context, name string, req dto.UpdateRoleRequest) (dto.RoleResponse, error)
+	DeleteRole(ctx context.Context, name string) error
 }
 
 const (
@@ -30,20 +65,50 @@ const (
 )
 
 type userService struct {
-	db     postgres.PgxIface
-	repo   repository.Querier
-	cache  redis.IRedisCache
-	config *config.Config
-	logger logger.Interface
+	db          postgres.PgxIface
+	repo        repository.Querier
+	bookingRepo bookingRepository.Querier
+	paymentRepo paymentRepository.Querier
+	cache       redis.IRedisCache
+	config      *config.Config
+	logger      logger.Interface
+	revoker     session.Revoker
+	guard       loginguard.Guard
+	mail        mail.Service
+	storage     supabase.Storage
+	auditor     audit.Recorder
+	otp         otp.Service
 }
 
-func New(db postgres.PgxIface, repo repository.Querier, cache redis.IRedisCache, cfg *config.Config, l logger.Interface) UserService {
+func New(
+	db postgres.PgxIface,
+	repo repository.Querier,
+	b bookingRepository.Querier,
+	p paymentRepository.Querier,
+	cache redis.IRedisCache,
+	cfg *config.Config,
+	l logger.Interface,
+	rv session.Revoker,
+	g loginguard.Guard,
+	m mail.Service,
+	storage supabase.Storage,
+	auditor audit.Recorder,
+	o otp.Service,
+) UserService {
 	return &userService{
-		db:     db,
-		repo:   repo,
-		cache:  cache,
-		config: cfg,
-		logger: l,
+		db:          db,
+		repo:        repo,
+		bookingRepo: b,
+		paymentRepo: p,
+		cache:       cache,
+		config:      cfg,
+		logger:      l,
+		revoker:     rv,
+		guard:       g,
+		mail:        m,
+		storage:     storage,
+		auditor:     auditor,
+		otp:         o,
 	}
 }
 
@@ -104,7 +169,8 @@ func (s *userService) GetAllUsers(ctx context.Context, req dto.GetUsersRequest)
 	totalCount, err := s.repo.CountUsers(ctx, s.db, repository.CountUsersParams{
 		Column1: req.Email,
 		Column2: req.FullName,
-		Column3: req.Level,
+		Column3: req.Role,
+		Column4: req.Deactivated,
 	})
 	if err != nil {
 		s.logger.Error("service - user - GetAllUsers - failed to count users: %v", err)
@@ -116,7 +182,8 @@ func (s *userService) GetAllUsers(ctx context.Context, req dto.GetUsersRequest)
 	users, err := s.repo.GetAllUsers(ctx, s.db, repository.GetAllUsersParams{
 		Column1: req.Email,
 		Column2: req.FullName,
-		Column3: req.Level,
+		Column3: req.Role,
+		Column4: req.Deactivated,
 		Limit:   int32(limit),
 		Offset:  int32(offset),
 	})
@@ -151,9 +218,34 @@ func (s *userService) GetUserByID(ctx context.Context, userID string) (res dto.U
 }
 
 func (s *userService) UpdateUserRole(ctx context.Context, id string, req dto.UpdateUserRoleRequest) (res dto.UserAdminResponse, err error) {
+	if _, err = s.repo.GetRole(ctx, s.db, req.Role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UpdateUserRole - role not found: %s", req.Role)
+
+			return res, failure.BadRequestFromString("role not found")
+		}
+
+		s.logger.Error("service - user - UpdateUserRole - failed to get role: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	before, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UpdateUserRole - user not found: %s", id)
+
+			return res, failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - UpdateUserRole - failed to get user: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
 	user, err := s.repo.UpdateUserRole(ctx, s.db, repository.UpdateUserRoleParams{
-		ID:    helper.PgUUID(id),
-		Level: req.Level,
+		ID:   helper.PgUUID(id),
+		Role: req.Role,
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -167,7 +259,75 @@ func (s *userService) UpdateUserRole(ctx context.Context, id string, req dto.Upd
 		return res, failure.InternalError(err)
 	}
 
+	// Tokens carry the role and its permissions, so make the user pick up the new one on their next refresh
+	if err = s.revoker.RevokeUser(ctx, user.ID.String()); err != nil {
+		s.logger.Error("service - user - UpdateUserRole - failed to revoke user tokens: %v", err)
+
+		return res, failure.InternalError(err)
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserRoleUpdate,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+		Before:     map[string]string{"role": before.Role},
+		After:      map[string]string{"role": user.Role},
+	})
+
 	res = dto.UserAdminResponse{}.FromModel(user)
 
 	return res, nil
 }
+
+// UnlockUser lifts a lockout after too many failed logins before it expires.
+func (s *userService) UnlockUser(ctx context.Context, id string) error {
+	user, err := s.repo.GetUserByID(ctx, s.db, helper.PgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error("service - user - UnlockUser - user not found: %s", id)
+
+			return failure.NotFound("user not found")
+		}
+
+		s.logger.Error("service - user - UnlockUser - failed to get user: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	// Phone logins count failures under the number, so a verified phone is locked separately
+	keys := []string{user.Email}
+	if user.PhoneVerified && user.Phone.Valid {
+		keys = append(keys, user.Phone.String)
+	}
+
+	for _, key := range keys {
+		if err = s.guard.Unlock(ctx, key); err != nil {
+			s.logger.Error("service - user - UnlockUser - failed to unlock user: %v", err)
+
+			return failure.InternalError(err)
+		}
+	}
+
+	s.auditor.Record(ctx, audit.Entry{
+		Action:     constant.AuditActionUserUnlock,
+		EntityType: constant.AuditEntityUser,
+		EntityID:   id,
+	})
+
+	return nil
+}
+
+// attachGuestBookings gives the owner of a newly verified email the bookings staff made for it as a
+// walk-in guest.
+func (s *userService) attachGuestBookings(ctx context.Context, db repository.DBTX, op string, userID pgtype.UUID, email string) error {
+	if _, err := s.bookingRepo.AttachGuestBookings(ctx, db, bookingRepository.AttachGuestBookingsParams{
+		UserID: userID,
+		Email:  email,
+	}); err != nil {
+		s.logger.Error("service - user - %s - failed to attach guest bookings: %v", op, err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
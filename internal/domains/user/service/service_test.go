@@ -8,17 +8,33 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/savioruz/goth/config"
+	bookingMock "github.com/savioruz/goth/internal/domains/bookings/mock"
+	bookingRepository "github.com/savioruz/goth/internal/domains/bookings/repository"
+	paymentMock "github.com/savioruz/goth/internal/domains/payments/mock"
 	"github.com/savioruz/goth/internal/domains/user/dto"
 	"github.com/savioruz/goth/internal/domains/user/mock"
 	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/audit"
+	auditMock "github.com/savioruz/goth/pkg/audit/mock"
+	"github.com/savioruz/goth/pkg/constant"
 	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
 	log "github.com/savioruz/goth/pkg/logger/mock"
+	guard "github.com/savioruz/goth/pkg/loginguard/mock"
+	"github.com/savioruz/goth/pkg/otp"
+	otpMock "github.com/savioruz/goth/pkg/otp/mock"
 	redis "github.com/savioruz/goth/pkg/redis/mock"
+	session "github.com/savioruz/goth/pkg/session/mock"
+	"github.com/savioruz/goth/pkg/supabase"
+	storageMock "github.com/savioruz/goth/pkg/supabase/mock"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUserService_Profile(t *testing.T) {
@@ -35,17 +51,17 @@ func TestUserService_Profile(t *testing.T) {
 	mockPgx, _ := pgxmock.NewPool()
 	mockRedis := redis.NewMockIRedisCache(ctrl)
 	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
 	mockError := errors.New("error")
 
-	service := New(mockPgx, mockQuerier, mockRedis, cfg, mockLogger)
+	service := New(mockPgx, mockQuerier, nil, nil, mockRedis, cfg, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, nil, nil)
 
 	mockID := uuid.New()
 	profileMock := repository.User{
 		ID:           pgtype.UUID{Bytes: mockID, Valid: true},
 		Email:        "string@gmail.com",
 		Password:     pgtype.Text{String: "strongpassword", Valid: true},
-		Level:        "user",
-		GoogleID:     pgtype.Text{String: "google123", Valid: true},
+		Role:         "user",
 		FullName:     pgtype.Text{String: "Test User", Valid: true},
 		ProfileImage: pgtype.Text{String: "https://example.com/profile.jpg", Valid: true},
 		IsVerified:   pgtype.Bool{Bool: true, Valid: true},
@@ -122,3 +138,554 @@ func TestUserService_Profile(t *testing.T) {
 		assert.Equal(t, "https://example.com/profile.jpg", res.ProfileImage)
 	})
 }
+
+func TestUserService_UpdateUserRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRevoker := session.NewMockRevoker(ctrl)
+
+	mockLogger := log.NewMockInterface(ctrl)
+	mockAuditor := auditMock.NewMockRecorder(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, mockAuditor, nil)
+
+	mockID := uuid.New()
+	user := repository.User{
+		ID:    pgtype.UUID{Bytes: mockID, Valid: true},
+		Email: "string@gmail.com",
+		Role:  constant.UserRoleStaff,
+	}
+
+	t.Run("success: revokes the tokens carrying the old role", func(t *testing.T) {
+		mockQuerier.EXPECT().GetRole(gomock.Any(), gomock.Any(), constant.UserRoleStaff).Return(repository.Role{Name: constant.UserRoleStaff}, nil)
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), user.ID).Return(repository.User{ID: user.ID, Role: constant.UserRoleUser}, nil)
+		mockQuerier.EXPECT().
+			UpdateUserRole(gomock.Any(), gomock.Any(), repository.UpdateUserRoleParams{ID: user.ID, Role: constant.UserRoleStaff}).
+			Return(user, nil)
+
+		mockRevoker.EXPECT().RevokeUser(gomock.Any(), mockID.String()).Return(nil)
+		mockAuditor.EXPECT().Record(gomock.Any(), audit.Entry{
+			Action:     constant.AuditActionUserRoleUpdate,
+			EntityType: constant.AuditEntityUser,
+			EntityID:   mockID.String(),
+			Before:     map[string]string{"role": constant.UserRoleUser},
+			After:      map[string]string{"role": constant.UserRoleStaff},
+		})
+
+		res, err := service.UpdateUserRole(ctx, mockID.String(), dto.UpdateUserRoleRequest{Role: constant.UserRoleStaff})
+
+		assert.NoError(t, err)
+		assert.Equal(t, constant.UserRoleStaff, res.Role)
+	})
+
+	t.Run("error: unknown role", func(t *testing.T) {
+		mockQuerier.EXPECT().GetRole(gomock.Any(), gomock.Any(), "unknown").Return(repository.Role{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).Times(1)
+
+		_, err := service.UpdateUserRole(ctx, mockID.String(), dto.UpdateUserRoleRequest{Role: "unknown"})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+}
+
+func TestUserService_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, nil, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	params := repository.RevokeSessionParams{ID: sessionID, UserID: userID}
+
+	t.Run("error: session of another user", func(t *testing.T) {
+		mockQuerier.EXPECT().RevokeSession(gomock.Any(), gomock.Any(), params).Return(int64(0), nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.RevokeSession(ctx, userID.String(), sessionID.String())
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+
+	t.Run("success: revokes the session and its tokens", func(t *testing.T) {
+		mockQuerier.EXPECT().RevokeSession(gomock.Any(), gomock.Any(), params).Return(int64(1), nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), sessionID).Return(nil)
+		mockRevoker.EXPECT().RevokeSession(gomock.Any(), sessionID.String()).Return(nil)
+
+		err := service.RevokeSession(ctx, userID.String(), sessionID.String())
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserService_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockGuard := guard.NewMockGuard(ctrl)
+	mockAuditor := auditMock.NewMockRecorder(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, session.NewMockRevoker(ctrl), mockGuard, nil, nil, mockAuditor, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("error: user not found", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.UnlockUser(ctx, userID.String())
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+
+	t.Run("success: unlocks the user's email", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Email: "test@gmail.com"}, nil)
+		mockGuard.EXPECT().Unlock(gomock.Any(), "test@gmail.com").Return(nil)
+		mockAuditor.EXPECT().Record(gomock.Any(), gomock.Any())
+
+		err := service.UnlockUser(ctx, userID.String())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success: unlocks the user's verified phone", func(t *testing.T) {
+		user := repository.User{ID: userID, Email: "test@gmail.com", Phone: pgtype.Text{String: "+628123456789", Valid: true}, PhoneVerified: true}
+
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockGuard.EXPECT().Unlock(gomock.Any(), "test@gmail.com").Return(nil)
+		mockGuard.EXPECT().Unlock(gomock.Any(), "+628123456789").Return(nil)
+		mockAuditor.EXPECT().Record(gomock.Any(), gomock.Any())
+
+		err := service.UnlockUser(ctx, userID.String())
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, nil, nil)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	currentSession := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	otherSession := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	user := repository.User{ID: userID, Email: "test@gmail.com", Password: pgtype.Text{String: string(hash), Valid: true}}
+
+	t.Run("error: wrong current password", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.ChangePassword(ctx, userID.String(), currentSession.String(), dto.ChangePasswordRequest{
+			CurrentPassword: "wrongpassword",
+			NewPassword:     "newpassword",
+		})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: oauth user has no password", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID}, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.ChangePassword(ctx, userID.String(), currentSession.String(), dto.ChangePasswordRequest{
+			CurrentPassword: "oldpassword",
+			NewPassword:     "newpassword",
+		})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("success: logs out the other sessions", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).Return(user, nil)
+		mockQuerier.EXPECT().DeletePasswordResetsByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().RevokeOtherSessions(gomock.Any(), gomock.Any(), repository.RevokeOtherSessionsParams{
+			UserID: userID,
+			KeepID: currentSession,
+		}).Return([]pgtype.UUID{otherSession}, nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), otherSession).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+		mockRevoker.EXPECT().RevokeSession(gomock.Any(), otherSession.String()).Return(nil)
+
+		err := service.ChangePassword(ctx, userID.String(), currentSession.String(), dto.ChangePasswordRequest{
+			CurrentPassword: "oldpassword",
+			NewPassword:     "newpassword",
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+	})
+}
+
+func TestUserService_ConfirmEmailChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRedis := redis.NewMockIRedisCache(ctrl)
+	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
+	mockBookingQuerier := bookingMock.NewMockQuerier(ctrl)
+
+	service := New(mockPgx, mockQuerier, mockBookingQuerier, nil, mockRedis, &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, nil, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	tokenHash := helper.HashToken("token")
+
+	t.Run("error: invalid or expired token", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().GetEmailChangeByTokenHash(gomock.Any(), gomock.Any(), tokenHash).Return(repository.EmailChange{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any())
+		mockPgx.ExpectRollback()
+
+		err := service.ConfirmEmailChange(ctx, dto.ConfirmEmailChangeRequest{Token: "token"})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("success: switches the email and revokes tokens", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().GetEmailChangeByTokenHash(gomock.Any(), gomock.Any(), tokenHash).
+			Return(repository.EmailChange{UserID: userID, NewEmail: "new@gmail.com"}, nil)
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Email: "old@gmail.com"}, nil)
+		mockQuerier.EXPECT().UpdateUserEmail(gomock.Any(), gomock.Any(), repository.UpdateUserEmailParams{
+			ID:    userID,
+			Email: "new@gmail.com",
+		}).Return(repository.User{ID: userID, Email: "new@gmail.com"}, nil)
+		// Guest bookings made for the new address move to the account that proved it owns it
+		mockBookingQuerier.EXPECT().AttachGuestBookings(gomock.Any(), gomock.Any(), bookingRepository.AttachGuestBookingsParams{
+			UserID: userID,
+			Email:  "new@gmail.com",
+		}).Return(int64(1), nil)
+		mockQuerier.EXPECT().DeleteEmailChangesByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+		mockRevoker.EXPECT().RevokeUser(gomock.Any(), userID.String()).Return(nil)
+		mockRedis.EXPECT().Delete(gomock.Any(), "cache:get_user:old@gmail.com").Return(nil)
+
+		err := service.ConfirmEmailChange(ctx, dto.ConfirmEmailChangeRequest{Token: "token"})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+	})
+}
+
+func TestUserService_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockBookingQuerier := bookingMock.NewMockQuerier(ctrl)
+	mockPaymentQuerier := paymentMock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRedis := redis.NewMockIRedisCache(ctrl)
+	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
+	mockStorage := storageMock.NewMockStorage(ctrl)
+
+	service := New(mockPgx, mockQuerier, mockBookingQuerier, mockPaymentQuerier, mockRedis, &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, mockStorage, nil, nil)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	bookingID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	organizationID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	user := repository.User{ID: userID, Email: "test@gmail.com", Password: pgtype.Text{String: string(hash), Valid: true}}
+
+	t.Run("error: wrong password", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		err := service.DeleteAccount(ctx, userID.String(), dto.DeleteAccountRequest{Password: "wrongpassword"})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("success: anonymises the user and keeps the bookings", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockBookingQuerier.EXPECT().GetAllBookingsByUserId(gomock.Any(), gomock.Any(), userID).
+			Return([]bookingRepository.Booking{{ID: bookingID, UserID: userID, OrganizationID: organizationID}}, nil)
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().AnonymizeUser(gomock.Any(), gomock.Any(), userID).Return(int64(1), nil)
+		mockQuerier.EXPECT().DeleteUserIdentitiesByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().DeleteUserMFA(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().DeleteMFARecoveryCodes(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().DeleteEmailVerificationsByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().DeletePasswordResetsByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().DeleteEmailChangesByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockQuerier.EXPECT().RevokeOtherSessions(gomock.Any(), gomock.Any(), repository.RevokeOtherSessionsParams{UserID: userID}).
+			Return([]pgtype.UUID{}, nil)
+		// Bookings made for the user as a guest still carry the contact details they gave at the counter
+		mockBookingQuerier.EXPECT().AnonymizeGuestBookingsByUserID(gomock.Any(), gomock.Any(), userID).Return(nil)
+		mockPaymentQuerier.EXPECT().AnonymizePaymentsByBookingIDs(gomock.Any(), gomock.Any(), []pgtype.UUID{bookingID}).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+		mockRevoker.EXPECT().RevokeUser(gomock.Any(), userID.String()).Return(nil)
+		mockRedis.EXPECT().Delete(gomock.Any(), "cache:get_user:test@gmail.com").Return(nil)
+
+		// Stored receipts still show the customer details, they are deleted in the background
+		receiptDeleted := make(chan struct{})
+		mockStorage.EXPECT().
+			DeleteObject(gomock.Any(), supabase.ReceiptKey(organizationID.String(), bookingID.String())).
+			DoAndReturn(func(context.Context, string) error {
+				close(receiptDeleted)
+
+				return nil
+			})
+
+		err := service.DeleteAccount(ctx, userID.String(), dto.DeleteAccountRequest{Password: "password"})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+		<-receiptDeleted
+	})
+}
+
+func TestUserService_DeleteRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockAuditor := auditMock.NewMockRecorder(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, session.NewMockRevoker(ctrl), guard.NewMockGuard(ctrl), nil, nil, mockAuditor, nil)
+
+	t.Run("success", func(t *testing.T) {
+		mockQuerier.EXPECT().DeleteRole(gomock.Any(), gomock.Any(), "front_desk").Return(int64(1), nil)
+		mockAuditor.EXPECT().Record(gomock.Any(), gomock.Any())
+
+		err := service.DeleteRole(ctx, "front_desk")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error: system role", func(t *testing.T) {
+		mockQuerier.EXPECT().DeleteRole(gomock.Any(), gomock.Any(), constant.UserRoleAdmin).Return(int64(0), nil)
+		mockQuerier.EXPECT().GetRole(gomock.Any(), gomock.Any(), constant.UserRoleAdmin).Return(repository.Role{Name: constant.UserRoleAdmin, IsSystem: true}, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).Times(1)
+
+		err := service.DeleteRole(ctx, constant.UserRoleAdmin)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+
+	t.Run("error: role not found", func(t *testing.T) {
+		mockQuerier.EXPECT().DeleteRole(gomock.Any(), gomock.Any(), "unknown").Return(int64(0), nil)
+		mockQuerier.EXPECT().GetRole(gomock.Any(), gomock.Any(), "unknown").Return(repository.Role{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).Times(1)
+
+		err := service.DeleteRole(ctx, "unknown")
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+}
+
+func TestUserService_DeactivateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockRedis := redis.NewMockIRedisCache(ctrl)
+	mockLogger := log.NewMockInterface(ctrl)
+	mockRevoker := session.NewMockRevoker(ctrl)
+	mockAuditor := auditMock.NewMockRecorder(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, mockRedis, &config.Config{}, mockLogger, mockRevoker, guard.NewMockGuard(ctrl), nil, nil, mockAuditor, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("error: own account", func(t *testing.T) {
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.DeactivateUser(ctx, userID.String(), userID.String())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: user not found", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockPgx.ExpectRollback()
+		mockQuerier.EXPECT().DeactivateUser(gomock.Any(), gomock.Any(), userID).Return(repository.User{}, pgx.ErrNoRows)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.DeactivateUser(ctx, userID.String(), uuid.NewString())
+
+		assert.Equal(t, http.StatusNotFound, failure.GetCode(err))
+	})
+
+	t.Run("success: logs the user out everywhere", func(t *testing.T) {
+		mockPgx.ExpectBegin()
+		mockQuerier.EXPECT().DeactivateUser(gomock.Any(), gomock.Any(), userID).Return(repository.User{
+			ID:        userID,
+			Email:     "test@gmail.com",
+			DeletedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		}, nil)
+		mockQuerier.EXPECT().
+			RevokeOtherSessions(gomock.Any(), gomock.Any(), repository.RevokeOtherSessionsParams{UserID: userID}).
+			Return([]pgtype.UUID{sessionID}, nil)
+		mockQuerier.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any(), sessionID).Return(nil)
+		mockPgx.ExpectCommit()
+		mockPgx.ExpectRollback()
+		mockRevoker.EXPECT().RevokeUser(gomock.Any(), userID.String()).Return(nil)
+		mockRedis.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+		mockAuditor.EXPECT().Record(gomock.Any(), gomock.Any())
+
+		res, err := service.DeactivateUser(ctx, userID.String(), uuid.NewString())
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.DeactivatedAt)
+		assert.NoError(t, mockPgx.ExpectationsWereMet())
+	})
+}
+
+func TestUserService_Impersonate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, session.NewMockRevoker(ctrl), guard.NewMockGuard(ctrl), nil, nil, nil, nil)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	t.Run("error: impersonating oneself", func(t *testing.T) {
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.Impersonate(ctx, userID.String(), userID.String())
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: user who can impersonate", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(repository.User{ID: userID, Role: constant.UserRoleAdmin}, nil)
+		mockQuerier.EXPECT().
+			GetRolePermissions(gomock.Any(), gomock.Any(), constant.UserRoleAdmin).
+			Return([]string{constant.PermissionUsersRead, constant.PermissionUsersImpersonate}, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.Impersonate(ctx, userID.String(), uuid.NewString())
+
+		assert.Equal(t, http.StatusForbidden, failure.GetCode(err))
+	})
+}
+
+func TestUserService_VerifyPhone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockOTP := otpMock.NewMockService(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, session.NewMockRevoker(ctrl), guard.NewMockGuard(ctrl), nil, nil, nil, mockOTP)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	user := repository.User{ID: userID, Email: "test@gmail.com", Phone: helper.PgString("+6281234567890")}
+	req := dto.VerifyPhoneRequest{Code: "123456"}
+
+	t.Run("error: wrong code", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockOTP.EXPECT().Verify(gomock.Any(), constant.OTPPurposePhoneVerify, user.Phone.String, req.Code).Return(otp.ErrInvalidCode)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.VerifyPhone(ctx, userID.String(), req)
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: number verified by another user", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockOTP.EXPECT().Verify(gomock.Any(), constant.OTPPurposePhoneVerify, user.Phone.String, req.Code).Return(nil)
+		mockQuerier.EXPECT().
+			VerifyUserPhone(gomock.Any(), gomock.Any(), repository.VerifyUserPhoneParams{ID: userID, Phone: user.Phone}).
+			Return(repository.User{}, &pgconn.PgError{Code: "23505"})
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.VerifyPhone(ctx, userID.String(), req)
+
+		assert.Equal(t, http.StatusConflict, failure.GetCode(err))
+	})
+}
+
+func TestUserService_ChangePhone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockQuerier := mock.NewMockQuerier(ctrl)
+	mockPgx, _ := pgxmock.NewPool()
+	mockLogger := log.NewMockInterface(ctrl)
+	mockOTP := otpMock.NewMockService(ctrl)
+
+	service := New(mockPgx, mockQuerier, nil, nil, redis.NewMockIRedisCache(ctrl), &config.Config{}, mockLogger, session.NewMockRevoker(ctrl), guard.NewMockGuard(ctrl), nil, nil, nil, mockOTP)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	user := repository.User{
+		ID:            userID,
+		Email:         "test@gmail.com",
+		Password:      pgtype.Text{String: string(hash), Valid: true},
+		Phone:         helper.PgString("+6281234567890"),
+		PhoneVerified: true,
+	}
+
+	t.Run("error: wrong password", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.ChangePhone(ctx, userID.String(), dto.ChangePhoneRequest{Phone: "+6289876543210", Password: "wrongpassword"})
+
+		assert.Equal(t, http.StatusBadRequest, failure.GetCode(err))
+	})
+
+	t.Run("error: cooldown keeps the verified number", func(t *testing.T) {
+		mockQuerier.EXPECT().GetUserByID(gomock.Any(), gomock.Any(), userID).Return(user, nil)
+		mockOTP.EXPECT().Send(gomock.Any(), constant.OTPPurposePhoneVerify, "+6289876543210").Return(otp.ErrCooldown)
+		mockLogger.EXPECT().Error(gomock.Any(), gomock.Any())
+
+		_, err := service.ChangePhone(ctx, userID.String(), dto.ChangePhoneRequest{Phone: "+6289876543210", Password: "password"})
+
+		assert.Equal(t, http.StatusTooManyRequests, failure.GetCode(err))
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"github.com/savioruz/goth/internal/domains/user/dto"
+	"github.com/savioruz/goth/internal/domains/user/repository"
+	"github.com/savioruz/goth/pkg/failure"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+func (s *userService) GetSessions(ctx context.Context, userID, currentSessionID string) ([]dto.SessionResponse, error) {
+	sessions, err := s.repo.GetActiveSessionsByUserID(ctx, s.db, helper.PgUUID(userID))
+	if err != nil {
+		s.logger.Error("service - user - GetSessions - failed to get sessions: %v", err)
+
+		return nil, failure.InternalError(err)
+	}
+
+	res := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		res[i] = dto.SessionResponse{}.FromModel(session, currentSessionID)
+	}
+
+	return res, nil
+}
+
+// RevokeSession signs the user out of one of their own sessions, e.g. a lost device.
+func (s *userService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	sessionUUID := helper.PgUUID(sessionID)
+
+	revoked, err := s.repo.RevokeSession(ctx, s.db, repository.RevokeSessionParams{
+		ID:     sessionUUID,
+		UserID: helper.PgUUID(userID),
+	})
+	if err != nil {
+		s.logger.Error("service - user - RevokeSession - failed to revoke session: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if revoked == 0 {
+		s.logger.Error("service - user - RevokeSession - session not found: %s", sessionID)
+
+		return failure.NotFound("session not found")
+	}
+
+	if err = s.repo.RevokeRefreshTokenFamily(ctx, s.db, sessionUUID); err != nil {
+		s.logger.Error("service - user - RevokeSession - failed to revoke refresh tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	if err = s.revoker.RevokeSession(ctx, sessionID); err != nil {
+		s.logger.Error("service - user - RevokeSession - failed to revoke access tokens: %v", err)
+
+		return failure.InternalError(err)
+	}
+
+	return nil
+}
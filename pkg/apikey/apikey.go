@@ -0,0 +1,39 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=apikey.go -destination=mock/apikey_mock.go -package=mock github.com/savioruz/goth/pkg/apikey Authenticator
+
+// Principal is who a request authenticated with an API key acts as.
+type Principal struct {
+	KeyID  pgtype.UUID
+	UserID string
+	Email  string
+	Scopes []string
+}
+
+// Authenticator checks API keys presented in the X-API-Key header.
+type Authenticator interface {
+	// Authenticate returns the principal of the key of the context's organisation. It fails for
+	// unknown or revoked keys and once the key exceeded its rate limit.
+	Authenticate(ctx context.Context, key string) (Principal, error)
+}
+
+type ctxKey struct{}
+
+// NewContext returns a context carrying the ID of the API key the request was made with.
+func NewContext(ctx context.Context, keyID pgtype.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, keyID)
+}
+
+// FromContext returns the ID of the API key of the context, invalid when the request was not
+// made with one.
+func FromContext(ctx context.Context) pgtype.UUID {
+	id, _ := ctx.Value(ctxKey{}).(pgtype.UUID)
+
+	return id
+}
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=audit.go -destination=mock/audit_mock.go -package=mock github.com/savioruz/goth/pkg/audit Recorder
+
+// Entry is a privileged action to keep a trace of. Before and After are the state of the entity
+// around the action, either may be nil for creations and deletions.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+// Recorder keeps the audit trail. Services record their privileged actions once they succeeded.
+type Recorder interface {
+	// Record stores the entry along with the actor of the context. The action already happened, so
+	// failures are logged rather than returned.
+	Record(ctx context.Context, entry Entry)
+}
+
+// Actor is who made a request, set up by the audit and authentication middlewares.
+type Actor struct {
+	UserID         string
+	APIKeyID       string
+	ImpersonatorID string
+	RequestID      string
+	IPAddress      string
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, ctxKey{}, actor)
+}
+
+// WithUser returns a context whose actor is the authenticated user, and the API key they used if any.
+func WithUser(ctx context.Context, userID, apiKeyID string) context.Context {
+	actor := FromContext(ctx)
+	actor.UserID = userID
+	actor.APIKeyID = apiKeyID
+
+	return NewContext(ctx, actor)
+}
+
+// WithImpersonator returns a context whose actor is being impersonated by the given admin.
+func WithImpersonator(ctx context.Context, impersonatorID string) context.Context {
+	actor := FromContext(ctx)
+	actor.ImpersonatorID = impersonatorID
+
+	return NewContext(ctx, actor)
+}
+
+func FromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(ctxKey{}).(Actor)
+
+	return actor
+}
+
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+const redacted = "[redacted]"
+
+// sensitiveFields never make it into the trail, only that they changed.
+var sensitiveFields = []string{
+	"password",
+	"key_hash",
+	"share_token_hash",
+	"xendit_api_key",
+	"xendit_callback_token",
+	"mfa_secret",
+}
+
+// Diff returns the fields that differ between before and after by their JSON name. Values which
+// are not JSON objects are compared as a whole under "value".
+func Diff(before, after any) (map[string]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+
+	for name, value := range b {
+		if other, ok := a[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = Change{Before: value, After: a[name]}
+		}
+	}
+
+	for name, value := range a {
+		if _, ok := b[name]; !ok {
+			changes[name] = Change{After: value}
+		}
+	}
+
+	// Every update bumps it, it tells nothing the log entry does not
+	delete(changes, "updated_at")
+
+	for name, change := range changes {
+		if slices.Contains(sensitiveFields, name) {
+			changes[name] = Change{Before: redactedValue(change.Before), After: redactedValue(change.After)}
+		}
+	}
+
+	return changes, nil
+}
+
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return map[string]any{}, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err = json.Unmarshal(raw, &object); err == nil && object != nil {
+		return object, nil
+	}
+
+	var value any
+	if err = json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"value": value}, nil
+}
+
+func redactedValue(v any) any {
+	if v == nil {
+		return nil
+	}
+
+	return redacted
+}
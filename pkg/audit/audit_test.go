@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type field struct {
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Password  string  `json:"password,omitempty"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("update keeps only changed fields", func(t *testing.T) {
+		changes, err := Diff(
+			field{Name: "Court 1", Price: 100, UpdatedAt: "yesterday"},
+			field{Name: "Court 1", Price: 150, UpdatedAt: "today"},
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]Change{"price": {Before: float64(100), After: float64(150)}}, changes)
+	})
+
+	t.Run("deletion has no after", func(t *testing.T) {
+		changes, err := Diff(field{Name: "Court 1"}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, Change{Before: "Court 1"}, changes["name"])
+	})
+
+	t.Run("scalars are compared as a whole", func(t *testing.T) {
+		changes, err := Diff("staff", "admin")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]Change{"value": {Before: "staff", After: "admin"}}, changes)
+	})
+
+	t.Run("sensitive fields are redacted", func(t *testing.T) {
+		changes, err := Diff(field{Password: "old"}, field{Password: "new"})
+
+		require.NoError(t, err)
+		assert.Equal(t, Change{Before: redacted, After: redacted}, changes["password"])
+	})
+}
+
+func TestWithUser(t *testing.T) {
+	ctx := NewContext(context.Background(), Actor{RequestID: "req-1", IPAddress: "10.0.0.1"})
+
+	actor := FromContext(WithUser(ctx, "user-1", "key-1"))
+
+	assert.Equal(t, Actor{UserID: "user-1", APIKeyID: "key-1", RequestID: "req-1", IPAddress: "10.0.0.1"}, actor)
+}
@@ -30,16 +30,81 @@ const (
 var PaymentUnknownMethod = "UNKNOWN"
 
 const (
-	PaymentCurrencyIDR = "IDR"
+	// Minimum amounts in major units of the amount's currency
+	PaymentMinimumAmount = 10000
+	FieldMinimumPrice    = 5000
 
 	PaymentCashMethod = "CASH"
 
 	PaymentStatusPaid    = "PAID"
 	PaymentStatusPending = "PENDING"
+	PaymentStatusFailed  = "FAILED"
+	PaymentStatusExpired = "EXPIRED"
+)
+
+const (
+	PaymentChannelInvoice        = "INVOICE"
+	PaymentChannelQRIS           = "QRIS"
+	PaymentChannelVirtualAccount = "VIRTUAL_ACCOUNT"
+	PaymentChannelEWallet        = "EWALLET"
+	PaymentChannelCash           = "CASH"
+
+	PaymentRequestStatusSucceeded      = "SUCCEEDED"
+	PaymentRequestStatusPending        = "PENDING"
+	PaymentRequestStatusRequiresAction = "REQUIRES_ACTION"
+	PaymentRequestStatusFailed         = "FAILED"
+	PaymentRequestStatusCanceled       = "CANCELED"
+	PaymentRequestStatusExpired        = "EXPIRED"
+
+	PaymentRequestEventSucceeded = "payment.succeeded"
+	PaymentRequestEventFailed    = "payment.failed"
+	PaymentRequestEventPending   = "payment.pending"
+
+	PaymentExpiryDuration = 30 * time.Minute
+)
+
+const (
+	// OAuthStateExpiry bounds how long a user may take on the provider's consent screen
+	OAuthStateExpiry = 10 * time.Minute
+	// OAuthCodeExpiry bounds how long the frontend may take to exchange the code from the callback redirect
+	OAuthCodeExpiry = time.Minute
+)
+
+const (
+	// VerificationResendCooldown is how long an email has to wait before another verification link is sent
+	VerificationResendCooldown = time.Minute
+)
+
+const (
+	// MFAChallengeExpiry bounds how long a user may take to enter their second factor after the password
+	MFAChallengeExpiry = 5 * time.Minute
+	// MFAMaxAttempts wrong codes end the challenge, the user has to log in again
+	MFAMaxAttempts       = 5
+	MFARecoveryCodeCount = 10
+)
+
+// One-time code purposes, a code texted for one cannot be used for the other.
+const (
+	OTPPurposeLogin       = "login"
+	OTPPurposePhoneVerify = "phone_verify"
+)
+
+const (
+	PaymentFeeService     = "SERVICE_FEE"
+	PaymentFeeConvenience = "CONVENIENCE_FEE"
+	PaymentFeeDiscount    = "DISCOUNT"
+
+	PaymentItemCategoryFee      = "FEE"
+	PaymentItemCategoryDiscount = "DISCOUNT"
+	PaymentItemCategoryTax      = "TAX"
+
+	TaxLabelPPN = "PPN"
 )
 
 const (
 	RequestHeaderCallback = "x-callback-token"
+	RequestHeaderTenant   = "X-Tenant"
+	RequestHeaderAPIKey   = "X-API-Key"
 )
 
 const (
@@ -51,21 +116,113 @@ const (
 	SecondsPerHour     = 3600
 	MinutesPerHour     = 60
 	MicrosecondsPerSec = 1000000
-	CentsToUnit        = 100
 )
 
+// System roles, which cannot be deleted. Any other role is created by admins.
+const (
+	UserRoleAdmin = "admin"
+	UserRoleStaff = "staff"
+	UserRoleUser  = "user"
+)
+
+// Permissions are granted to roles and checked by middleware.RequirePermission.
+const (
+	PermissionUsersRead           = "users:read"
+	PermissionUsersWrite          = "users:write"
+	PermissionUsersImpersonate    = "users:impersonate"
+	PermissionRolesManage         = "roles:manage"
+	PermissionLocationsWrite      = "locations:write"
+	PermissionLocationsAny        = "locations:any"
+	PermissionLocationsStaff      = "locations:staff"
+	PermissionFieldsWrite         = "fields:write"
+	PermissionBookingsReadAny     = "bookings:read_any"
+	PermissionBookingsCancelAny   = "bookings:cancel_any"
+	PermissionBookingsCashPayment = "bookings:cash_payment"
+	PermissionPaymentsRead        = "payments:read"
+	PermissionOrganizationsManage = "organizations:manage"
+	PermissionAPIKeysManage       = "api_keys:manage"
+	PermissionAuditLogsRead       = "audit_logs:read"
+)
+
+// PlatformPermissions reach across organisations, staff bound to an organisation never hold them.
+var PlatformPermissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+	PermissionRolesManage,
+	PermissionOrganizationsManage,
+}
+
+// Audit log actions, named <entity>.<verb>, and the entities they act on.
+const (
+	AuditActionUserCreate               = "user.create"
+	AuditActionUserRoleUpdate           = "user.role_update"
+	AuditActionUserUnlock               = "user.unlock"
+	AuditActionUserDeactivate           = "user.deactivate"
+	AuditActionUserReactivate           = "user.reactivate"
+	AuditActionUserPasswordReset        = "user.password_reset"
+	AuditActionUserEmailVerify          = "user.email_verify"
+	AuditActionUserImpersonate          = "user.impersonate"
+	AuditActionUserImpersonatedRequest  = "user.impersonated_request"
+	AuditActionRoleCreate               = "role.create"
+	AuditActionRoleUpdate               = "role.update"
+	AuditActionRoleDelete               = "role.delete"
+	AuditActionLocationCreate           = "location.create"
+	AuditActionLocationUpdate           = "location.update"
+	AuditActionLocationDelete           = "location.delete"
+	AuditActionLocationStaffAssign      = "location.staff_assign"
+	AuditActionLocationStaffUnassign    = "location.staff_unassign"
+	AuditActionFieldCreate              = "field.create"
+	AuditActionFieldUpdate              = "field.update"
+	AuditActionFieldDelete              = "field.delete"
+	AuditActionFieldImageDelete         = "field.image_delete"
+	AuditActionOrganizationUpdate       = "organization.update"
+	AuditActionOrganizationXenditUpdate = "organization.xendit_update"
+	AuditActionOrganizationMemberAdd    = "organization.member_add"
+	AuditActionOrganizationMemberRemove = "organization.member_remove"
+	AuditActionAPIKeyCreate             = "api_key.create"
+	AuditActionAPIKeyRevoke             = "api_key.revoke"
+
+	AuditEntityUser         = "user"
+	AuditEntityRole         = "role"
+	AuditEntityLocation     = "location"
+	AuditEntityField        = "field"
+	AuditEntityOrganization = "organization"
+	AuditEntityAPIKey       = "api_key"
+)
+
+// API key scopes. Keys act as the user who created them, limited to their scopes: the bookings
+// scopes open the routes that accept keys, the others are permissions delegated to the key.
 const (
-	UserRoleAdmin = "9"
-	UserRoleStaff = "2"
-	UserRoleUser  = "1"
+	APIKeyScopeBookingsRead  = "bookings:read"
+	APIKeyScopeBookingsWrite = "bookings:write"
 )
 
+// APIKeyScopes are the scopes a key may be given.
+var APIKeyScopes = []string{
+	APIKeyScopeBookingsRead,
+	APIKeyScopeBookingsWrite,
+	PermissionBookingsReadAny,
+	PermissionBookingsCancelAny,
+	PermissionBookingsCashPayment,
+	PermissionPaymentsRead,
+}
+
 const (
-	JwtFieldUser  = "user_id"
-	JwtFieldEmail = "email"
-	JwtFieldLevel = "level"
+	JwtFieldUser         = "user_id"
+	JwtFieldEmail        = "email"
+	JwtFieldRole         = "role"
+	JwtFieldPermissions  = "permissions"
+	JwtFieldSession      = "session_id"
+	JwtFieldImpersonator = "impersonator"
 )
 
+// LocalsTenant holds the ID of the organisation the request is for, set by middleware.Tenant.
+const LocalsTenant = "tenant_id"
+
+// LocalsAPIKey holds the ID of the API key a request was authenticated with, set by middleware.Auth.Authenticate.
+const LocalsAPIKey = "api_key_id"
+
 const (
 	PaginationDefaultLimit = 10
 	PaginationDefaultPage  = 1
@@ -86,6 +86,14 @@ func Forbidden(msg string) error {
 	}
 }
 
+// TooManyRequests returns a new Failure with code for rate limited requests.
+func TooManyRequests(msg string) error {
+	return &Failure{
+		Code:    http.StatusTooManyRequests,
+		Message: msg,
+	}
+}
+
 // GetCode returns the error code of an error interface.
 func GetCode(err error) int {
 	var f *Failure
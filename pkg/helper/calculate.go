@@ -1,11 +1,6 @@
 package helper
 
-import (
-	"fmt"
-	"time"
-
-	"github.com/savioruz/goth/pkg/constant"
-)
+import "time"
 
 func CalculateOffset(page, limit int) int {
 	if page <= 0 || limit <= 0 {
@@ -26,16 +21,3 @@ func CalculateTotalPages(totalItems, limit int) int {
 func CalculateEndTime(startTime time.Time, durationHours int) time.Time {
 	return startTime.Add(time.Duration(durationHours) * time.Hour)
 }
-
-func CalculateTotalPrice(pricePerHour int64, durationHours int) int64 {
-	if pricePerHour <= 0 || durationHours <= 0 {
-		return 0
-	}
-
-	return pricePerHour * int64(durationHours)
-}
-
-// FormatAmountFromCents converts amount from cents to formatted string with 2 decimal places
-func FormatAmountFromCents(amountInCents int64) string {
-	return fmt.Sprintf("%.2f", float64(amountInCents)/constant.CentsToUnit)
-}
@@ -1,17 +1,13 @@
 package helper
 
 import (
-	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/savioruz/goth/pkg/constant"
 )
 
-const (
-	x = 10
-)
-
 func PgBool(b bool) pgtype.Bool {
 	return pgtype.Bool{
 		Bool:  b,
@@ -27,40 +23,25 @@ func PgString(s string) pgtype.Text {
 	}
 }
 
-// PgInt64 converts an int64 to pgtype.Numeric
-func PgInt64(i int64) pgtype.Numeric {
-	bigInt := new(big.Int).SetInt64(i)
+// PgFloat64 converts a float64 to pgtype.Numeric with two decimal places, e.g. a tax rate
+func PgFloat64(f float64) pgtype.Numeric {
+	var n pgtype.Numeric
 
-	return pgtype.Numeric{
-		Int:   bigInt,
-		Valid: true,
+	if err := n.Scan(strconv.FormatFloat(f, 'f', 2, 64)); err != nil {
+		return pgtype.Numeric{}
 	}
+
+	return n
 }
 
-// Int64FromPg converts a pgtype.Numeric to an int64
-func Int64FromPg(n pgtype.Numeric) int64 {
-	if !n.Valid || n.Int == nil {
+// Float64FromPg converts a pgtype.Numeric to a float64
+func Float64FromPg(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
 		return 0
 	}
 
-	if n.Exp != 0 {
-		// Create a copy of the number to work with
-		result := new(big.Int).Set(n.Int)
-
-		// If Exp is negative, divide by 10^(-Exp)
-		if n.Exp < 0 {
-			divisor := new(big.Int).Exp(big.NewInt(x), big.NewInt(int64(-n.Exp)), nil)
-			result = result.Div(result, divisor)
-		} else {
-			// If Exp is positive, multiply by 10^Exp
-			multiplier := new(big.Int).Exp(big.NewInt(x), big.NewInt(int64(n.Exp)), nil)
-			result = result.Mul(result, multiplier)
-		}
-
-		return result.Int64()
-	}
-
-	return n.Int.Int64()
+	return f.Float64
 }
 
 // PgUUID converts a string UUID to pgtype.UUID
@@ -145,6 +126,14 @@ func PgTimeToString(t pgtype.Time) (string, error) {
 	return time.Date(0, 1, 1, int(hours), int(minutes), 0, 0, time.Local).Format(constant.HoursFormat), nil
 }
 
+// PgInterval converts a time.Duration to pgtype.Interval
+func PgInterval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{
+		Microseconds: d.Microseconds(),
+		Valid:        true,
+	}
+}
+
 // PgTimestamp converts a time.Time object to pgtype.Timestamp
 func PgTimestamp(t time.Time) pgtype.Timestamp {
 	return pgtype.Timestamp{
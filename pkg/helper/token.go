@@ -2,6 +2,7 @@ package helper
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 )
 
@@ -13,3 +14,10 @@ func GenerateRandomToken(length int) (string, error) {
 
 	return hex.EncodeToString(bytes), nil
 }
+
+// HashToken returns the hex encoded SHA-256 of a token, so tokens can be looked up without storing them.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -4,10 +4,18 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claims of access tokens carry the permissions the role had when the token was issued,
+// refresh tokens carry none. Organization is set for staff bound to an organisation, their
+// permissions only hold within it. Impersonator is set on tokens an admin was issued to act as the
+// user, they have no session and cannot be refreshed.
 type Claims struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Level     string `json:"level"`
-	TokenType string `json:"token_type"`
+	ID           string   `json:"id"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Organization string   `json:"org,omitempty"`
+	Permissions  []string `json:"permissions,omitempty"`
+	TokenType    string   `json:"token_type"`
+	SessionID    string   `json:"sid"`
+	Impersonator string   `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
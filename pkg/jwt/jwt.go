@@ -7,6 +7,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
 )
 
 var (
@@ -43,12 +49,29 @@ func GetInstance() *JWT {
 	return instance
 }
 
-func GenerateAccessToken(userID, email, level string) (string, error) {
-	return GetInstance().generateToken(userID, email, level, GetInstance().accessTokenExpiry, "access_token")
+// GenerateAccessToken issues an access token. The organization is empty for users not bound to one.
+func GenerateAccessToken(userID, email, role, organization string, permissions []string, sessionID string) (string, error) {
+	return GetInstance().generateToken(userID, email, role, organization, permissions, sessionID, "", GetInstance().accessTokenExpiry, TokenTypeAccess)
+}
+
+// GenerateImpersonationToken issues an access token letting the impersonator act as the user until it expires.
+func GenerateImpersonationToken(userID, email, role, organization string, permissions []string, impersonatorID string) (string, error) {
+	return GetInstance().generateToken(userID, email, role, organization, permissions, "", impersonatorID, GetInstance().accessTokenExpiry, TokenTypeAccess)
+}
+
+// GenerateRefreshToken carries no permissions, they are resolved again when the refresh token is used.
+func GenerateRefreshToken(userID, email, role, sessionID string) (string, error) {
+	return GetInstance().generateToken(userID, email, role, "", nil, sessionID, "", GetInstance().refreshTokenExpiry, TokenTypeRefresh)
+}
+
+// AccessTokenExpiry returns how long an access token stays valid, e.g. to keep a revocation around.
+func AccessTokenExpiry() time.Duration {
+	return GetInstance().accessTokenExpiry
 }
 
-func GenerateRefreshToken(userID, email, level string) (string, error) {
-	return GetInstance().generateToken(userID, email, level, GetInstance().refreshTokenExpiry, "refresh_token")
+// RefreshTokenExpiry returns how long a refresh token stays valid, e.g. to persist its expiry.
+func RefreshTokenExpiry() time.Duration {
+	return GetInstance().refreshTokenExpiry
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
@@ -66,18 +89,23 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	return nil, ErrInvalidToken
 }
 
-func (j *JWT) generateToken(userID, email, level string, expiry time.Duration, tokenType string) (string, error) {
+func (j *JWT) generateToken(userID, email, role, organization string, permissions []string, sessionID, impersonator string, expiry time.Duration, tokenType string) (string, error) {
 	claims := &Claims{
-		ID:        userID,
-		Email:     email,
-		Level:     level,
-		TokenType: tokenType,
+		ID:           userID,
+		Email:        email,
+		Role:         role,
+		Organization: organization,
+		Permissions:  permissions,
+		TokenType:    tokenType,
+		SessionID:    sessionID,
+		Impersonator: impersonator,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    j.appName,
 			Subject:   userID,
+			ID:        uuid.NewString(),
 		},
 	}
 
@@ -0,0 +1,164 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/pkg/redis"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=loginguard.go -destination=mock/loginguard_mock.go -package=mock github.com/savioruz/goth/pkg/loginguard Guard
+
+const (
+	accountAttemptsKey = "login:attempts:account:%s"
+	ipAttemptsKey      = "login:attempts:ip:%s"
+	accountBlockedKey  = "login:blocked:account:%s"
+	ipBlockedKey       = "login:blocked:ip:%s"
+)
+
+type Config struct {
+	// FreeAttempts is how many failures an account may have before each further attempt is delayed.
+	FreeAttempts int
+	// MaxAttempts is how many failures lock the account for the LockoutDuration.
+	MaxAttempts int
+	// IPMaxAttempts is how many failures, across all accounts, block an IP for the LockoutDuration.
+	IPMaxAttempts int
+	// LockoutDuration also bounds how long failures are remembered.
+	LockoutDuration time.Duration
+}
+
+// Guard slows down password guessing. Failures delay the next attempt on the account exponentially
+// until it is locked, and an IP failing against many accounts is blocked.
+type Guard interface {
+	// Check returns how long the account or IP has to wait before it may try again, zero if it may try now.
+	Check(ctx context.Context, email, ip string) (time.Duration, error)
+	// Fail records a failed attempt. When it locks the account, it returns when the lockout ends.
+	Fail(ctx context.Context, email, ip string) (lockedUntil time.Time, err error)
+	// Succeed forgets the account's failed attempts.
+	Succeed(ctx context.Context, email string) error
+	// Unlock forgets the account's failed attempts and lifts its lockout.
+	Unlock(ctx context.Context, email string) error
+}
+
+type redisGuard struct {
+	cache  redis.IRedisCache
+	config Config
+}
+
+func NewRedisGuard(cache redis.IRedisCache, cfg Config) Guard {
+	return &redisGuard{cache: cache, config: cfg}
+}
+
+func (g *redisGuard) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	account, err := g.blockedFor(ctx, fmt.Sprintf(accountBlockedKey, normalize(email)))
+	if err != nil {
+		return 0, err
+	}
+
+	address, err := g.blockedFor(ctx, fmt.Sprintf(ipBlockedKey, ip))
+	if err != nil {
+		return 0, err
+	}
+
+	return max(account, address), nil
+}
+
+func (g *redisGuard) Fail(ctx context.Context, email, ip string) (time.Time, error) {
+	window := int(g.config.LockoutDuration.Seconds())
+	email = normalize(email)
+
+	failures, err := g.cache.Incr(ctx, fmt.Sprintf(accountAttemptsKey, email), window)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if delay := g.accountDelay(failures); delay > 0 {
+		if err = g.block(ctx, fmt.Sprintf(accountBlockedKey, email), delay); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	if ip != "" {
+		ipFailures, err := g.cache.Incr(ctx, fmt.Sprintf(ipAttemptsKey, ip), window)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if ipFailures >= int64(g.config.IPMaxAttempts) {
+			if err = g.block(ctx, fmt.Sprintf(ipBlockedKey, ip), g.config.LockoutDuration); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+
+	// Only the failure reaching the limit reports the lockout, so the owner is notified once
+	if failures != int64(g.config.MaxAttempts) {
+		return time.Time{}, nil
+	}
+
+	return time.Now().Add(g.config.LockoutDuration), nil
+}
+
+func (g *redisGuard) Succeed(ctx context.Context, email string) error {
+	return g.cache.Delete(ctx, fmt.Sprintf(accountAttemptsKey, normalize(email)))
+}
+
+func (g *redisGuard) Unlock(ctx context.Context, email string) error {
+	email = normalize(email)
+
+	if err := g.cache.Delete(ctx, fmt.Sprintf(accountAttemptsKey, email)); err != nil {
+		return err
+	}
+
+	return g.cache.Delete(ctx, fmt.Sprintf(accountBlockedKey, email))
+}
+
+// accountDelay doubles the wait with every failure after the free ones, up to the lockout.
+func (g *redisGuard) accountDelay(failures int64) time.Duration {
+	if failures >= int64(g.config.MaxAttempts) {
+		return g.config.LockoutDuration
+	}
+
+	excess := failures - int64(g.config.FreeAttempts)
+	if excess <= 0 {
+		return 0
+	}
+
+	return min(time.Second<<(excess-1), g.config.LockoutDuration)
+}
+
+// block stores when the block ends, so Check can report the remaining wait.
+func (g *redisGuard) block(ctx context.Context, key string, d time.Duration) error {
+	until := strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+
+	return g.cache.Save(ctx, key, until, int(d.Seconds()))
+}
+
+func (g *redisGuard) blockedFor(ctx context.Context, key string) (time.Duration, error) {
+	var until string
+
+	err := g.cache.Get(ctx, key, &until)
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	unix, err := strconv.ParseInt(until, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("loginguard: invalid block time %q: %w", until, err)
+	}
+
+	return max(time.Until(time.Unix(unix, 0)), 0), nil
+}
+
+func normalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,89 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	redis "github.com/savioruz/goth/pkg/redis/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testConfig = Config{
+	FreeAttempts:    3,
+	MaxAttempts:     10,
+	IPMaxAttempts:   50,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func TestGuard_AccountDelay(t *testing.T) {
+	g := &redisGuard{config: testConfig}
+
+	tests := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 9, want: 32 * time.Second},
+		{failures: 10, want: 15 * time.Minute},
+		{failures: 11, want: 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, g.accountDelay(tt.failures), "failures: %d", tt.failures)
+	}
+}
+
+func TestGuard_Fail(t *testing.T) {
+	ctx := context.Background()
+	window := int(testConfig.LockoutDuration.Seconds())
+
+	t.Run("free attempt is not delayed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		g := NewRedisGuard(cache, testConfig)
+
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:account:test@gmail.com", window).Return(int64(1), nil)
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:ip:127.0.0.1", window).Return(int64(1), nil)
+
+		lockedUntil, err := g.Fail(ctx, " Test@Gmail.com", "127.0.0.1")
+
+		require.NoError(t, err)
+		assert.True(t, lockedUntil.IsZero())
+	})
+
+	t.Run("reaching the limit locks the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		g := NewRedisGuard(cache, testConfig)
+
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:account:test@gmail.com", window).Return(int64(10), nil)
+		cache.EXPECT().Save(gomock.Any(), "login:blocked:account:test@gmail.com", gomock.Any(), window).Return(nil)
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:ip:127.0.0.1", window).Return(int64(10), nil)
+
+		lockedUntil, err := g.Fail(ctx, "test@gmail.com", "127.0.0.1")
+
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(testConfig.LockoutDuration), lockedUntil, time.Second)
+	})
+
+	t.Run("failing ip is blocked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		g := NewRedisGuard(cache, testConfig)
+
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:account:test@gmail.com", window).Return(int64(1), nil)
+		cache.EXPECT().Incr(gomock.Any(), "login:attempts:ip:127.0.0.1", window).Return(int64(50), nil)
+		cache.EXPECT().Save(gomock.Any(), "login:blocked:ip:127.0.0.1", gomock.Any(), window).Return(nil)
+
+		lockedUntil, err := g.Fail(ctx, "test@gmail.com", "127.0.0.1")
+
+		require.NoError(t, err)
+		assert.True(t, lockedUntil.IsZero())
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -30,15 +32,29 @@ type BookingConfirmationData struct {
 	BookingDate      string
 	StartTime        string
 	EndTime          string
+	Subtotal         string
+	TaxLabel         string
+	TaxAmount        string
 	TotalAmount      string
 	PaymentMethod    string
 	ConfirmationDate string
+	HasReceipt       bool
+}
+
+// Attachment represents a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
 }
 
 type Service interface {
 	SendVerificationEmail(to, name, token string) error
 	SendPasswordResetEmail(to, name, token string) error
-	SendBookingConfirmationEmail(to string, data BookingConfirmationData) error
+	SendBookingConfirmationEmail(to string, data BookingConfirmationData, attachments ...Attachment) error
+	SendAccountLockedEmail(to, name string, until time.Time) error
+	SendEmailChangeEmail(to, name, token string) error
+	SendInvitationEmail(to, name, token string) error
 }
 
 type service struct {
@@ -46,6 +62,9 @@ type service struct {
 	verificationTemplate        *template.Template
 	passwordResetTemplate       *template.Template
 	bookingConfirmationTemplate *template.Template
+	accountLockedTemplate       *template.Template
+	emailChangeTemplate         *template.Template
+	invitationTemplate          *template.Template
 }
 
 func New(config Config) Service {
@@ -71,11 +90,29 @@ func New(config Config) Service {
 		panic(fmt.Sprintf("failed to parse booking confirmation template: %v", err))
 	}
 
+	accountLockedTemplate, err := template.ParseFiles(filepath.Join(templatePath, "account_locked.html"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse account locked template: %v", err))
+	}
+
+	emailChangeTemplate, err := template.ParseFiles(filepath.Join(templatePath, "email_change.html"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse email change template: %v", err))
+	}
+
+	invitationTemplate, err := template.ParseFiles(filepath.Join(templatePath, "invitation.html"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse invitation template: %v", err))
+	}
+
 	return &service{
 		config:                      config,
 		verificationTemplate:        verificationTemplate,
 		passwordResetTemplate:       passwordResetTemplate,
 		bookingConfirmationTemplate: bookingConfirmationTemplate,
+		accountLockedTemplate:       accountLockedTemplate,
+		emailChangeTemplate:         emailChangeTemplate,
+		invitationTemplate:          invitationTemplate,
 	}
 }
 
@@ -123,7 +160,7 @@ func (s *service) SendPasswordResetEmail(to, name, token string) error {
 	return s.sendEmail(to, subject, body.String())
 }
 
-func (s *service) SendBookingConfirmationEmail(to string, data BookingConfirmationData) error {
+func (s *service) SendBookingConfirmationEmail(to string, data BookingConfirmationData, attachments ...Attachment) error {
 	subject := "Booking Confirmation - Payment Successful"
 
 	// Execute template
@@ -132,16 +169,101 @@ func (s *service) SendBookingConfirmationEmail(to string, data BookingConfirmati
 		return fmt.Errorf("failed to execute booking confirmation template: %w", err)
 	}
 
+	return s.sendEmail(to, subject, body.String(), attachments...)
+}
+
+func (s *service) SendAccountLockedEmail(to, name string, until time.Time) error {
+	subject := "Your Account Has Been Locked"
+	resetURL := fmt.Sprintf("%s/forgot-password", os.Getenv("APP_URL"))
+
+	// Template data
+	data := struct {
+		Name     string
+		Until    string
+		ResetURL string
+	}{
+		Name:     name,
+		Until:    until.UTC().Format("02 Jan 2006 15:04 MST"),
+		ResetURL: resetURL,
+	}
+
+	// Execute template
+	var body bytes.Buffer
+	if err := s.accountLockedTemplate.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to execute account locked template: %w", err)
+	}
+
 	return s.sendEmail(to, subject, body.String())
 }
 
-func (s *service) sendEmail(to, subject, body string) error {
+func (s *service) SendEmailChangeEmail(to, name, token string) error {
+	subject := "Confirm Your New Email Address"
+	confirmURL := fmt.Sprintf("%s/confirm-email-change?token=%s", os.Getenv("APP_URL"), token)
+
+	// Template data
+	data := struct {
+		Name       string
+		Email      string
+		ConfirmURL string
+	}{
+		Name:       name,
+		Email:      to,
+		ConfirmURL: confirmURL,
+	}
+
+	// Execute template
+	var body bytes.Buffer
+	if err := s.emailChangeTemplate.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to execute email change template: %w", err)
+	}
+
+	return s.sendEmail(to, subject, body.String())
+}
+
+// SendInvitationEmail invites a user an admin created to set their password. The link uses the
+// password reset page, the token is a password reset token.
+func (s *service) SendInvitationEmail(to, name, token string) error {
+	subject := "You're Invited"
+	inviteURL := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv("APP_URL"), token)
+
+	// Template data
+	data := struct {
+		Name      string
+		InviteURL string
+	}{
+		Name:      name,
+		InviteURL: inviteURL,
+	}
+
+	// Execute template
+	var body bytes.Buffer
+	if err := s.invitationTemplate.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to execute invitation template: %w", err)
+	}
+
+	return s.sendEmail(to, subject, body.String())
+}
+
+func (s *service) sendEmail(to, subject, body string, attachments ...Attachment) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromEmail))
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
 	m.SetBody("text/html", body)
 
+	for _, attachment := range attachments {
+		content := attachment.Content
+
+		m.Attach(attachment.Filename,
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(content)
+
+				return err
+			}),
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+		)
+	}
+
 	d := gomail.NewDialer(s.config.SMTPHost, s.config.SMTPPort, s.config.SMTPUsername, s.config.SMTPPassword)
 
 	return d.DialAndSend(m)
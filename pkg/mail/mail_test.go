@@ -29,5 +29,8 @@ func TestMailService_Templates(t *testing.T) {
 
 		require.NotNil(t, s.verificationTemplate)
 		require.NotNil(t, s.passwordResetTemplate)
+		require.NotNil(t, s.accountLockedTemplate)
+		require.NotNil(t, s.emailChangeTemplate)
+		require.NotNil(t, s.invitationTemplate)
 	})
 }
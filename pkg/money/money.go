@@ -0,0 +1,296 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+
+	DefaultCurrency = IDR
+
+	decimalBase  = 10
+	thousandsSep = 3
+	percentBase  = 100
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidAmount       = errors.New("invalid amount")
+)
+
+// exponents holds the number of minor unit digits of each supported currency (ISO 4217).
+var exponents = map[Currency]int32{
+	IDR: 2,
+}
+
+// Exponent returns the number of minor unit digits of the currency, e.g. 2 for IDR.
+func (c Currency) Exponent() (int32, error) {
+	exp, ok := exponents[c]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, string(c))
+	}
+
+	return exp, nil
+}
+
+// settlementExponents holds the minor unit digits a currency is actually charged in, where it differs from ISO 4217.
+// IDR has two minor digits on paper, but Xendit only accepts whole rupiah.
+var settlementExponents = map[Currency]int32{
+	IDR: 0,
+}
+
+func (c Currency) minorPerMajor() int64 {
+	return pow10(exponents[c])
+}
+
+// minorPerSettlement returns the number of minor units in the smallest chargeable amount, e.g. 100 for IDR.
+func (c Currency) minorPerSettlement() int64 {
+	exp, ok := settlementExponents[c]
+	if !ok {
+		return 1
+	}
+
+	return pow10(exponents[c] - exp)
+}
+
+// roundToSettlement rounds an amount in minor units half away from zero to a chargeable amount.
+func (c Currency) roundToSettlement(minor float64) int64 {
+	unit := c.minorPerSettlement()
+
+	return int64(math.Round(minor/float64(unit))) * unit
+}
+
+func pow10(exp int32) int64 {
+	factor := int64(1)
+	for range exp {
+		factor *= decimalBase
+	}
+
+	return factor
+}
+
+// Money is an amount in the smallest unit of its currency, e.g. 1 IDR is stored as 100.
+// It is the only representation of money between the database, DTOs, Xendit and emails.
+type Money struct {
+	Amount   int64    `json:"amount" validate:"min=0" example:"10000000"`
+	Currency Currency `json:"currency" validate:"required,oneof=IDR" example:"IDR"`
+}
+
+// New returns an amount expressed in minor units.
+func New(minor int64, currency Currency) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+// FromMajor returns an amount expressed in whole currency units, e.g. FromMajor(150000, IDR) is IDR 150,000.00.
+func FromMajor(major int64, currency Currency) Money {
+	return Money{Amount: major * currency.minorPerMajor(), Currency: currency}
+}
+
+// Zero returns a zero amount in the given currency.
+func Zero(currency Currency) Money {
+	return Money{Currency: currency}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns m + o. Both amounts must share a currency, check with SameCurrency at the boundary.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}
+}
+
+// Sub returns m - o. Both amounts must share a currency, check with SameCurrency at the boundary.
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}
+}
+
+// Mul returns m multiplied by a quantity.
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+func (m Money) LessThan(o Money) bool {
+	return m.Amount < o.Amount
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.Amount == o.Amount && m.Currency == o.Currency
+}
+
+// SameCurrency reports whether all amounts are in currency.
+func SameCurrency(currency Currency, amounts ...Money) bool {
+	for _, amount := range amounts {
+		if amount.Currency != currency {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SplitTax splits m into subtotal, tax and total for a tax rate in percent.
+// An inclusive rate means m already contains the tax, otherwise the tax is added on top.
+// The tax is rounded to an amount the currency can be charged in, e.g. whole rupiah for IDR.
+func (m Money) SplitTax(ratePercent float64, inclusive bool) (subtotal, tax, total Money) {
+	if m.Amount <= 0 || ratePercent <= 0 {
+		return m, Zero(m.Currency), m
+	}
+
+	if inclusive {
+		tax = New(m.Currency.roundToSettlement(float64(m.Amount)*ratePercent/(percentBase+ratePercent)), m.Currency)
+
+		return m.Sub(tax), tax, m
+	}
+
+	tax = New(m.Currency.roundToSettlement(float64(m.Amount)*ratePercent/percentBase), m.Currency)
+
+	return m, tax, m.Add(tax)
+}
+
+// Major returns the amount in whole currency units as used by the Xendit API, e.g. 150000 for IDR 150,000.00.
+// Currencies charged in whole units, such as IDR, are rounded to a whole number without a float division.
+func (m Money) Major() float64 {
+	factor := m.Currency.minorPerMajor()
+
+	if m.Currency.minorPerSettlement() == factor {
+		whole, remainder := m.Amount/factor, m.Amount%factor
+		if 2*remainder >= factor {
+			whole++
+		} else if 2*remainder <= -factor {
+			whole--
+		}
+
+		return float64(whole)
+	}
+
+	return float64(m.Amount) / float64(factor)
+}
+
+// Decimal renders the amount in major units with the currency's minor digits, e.g. "150,000.00".
+func (m Money) Decimal() string {
+	factor := m.Currency.minorPerMajor()
+
+	sign := ""
+	amount := m.Amount
+
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	out := sign + groupThousands(strconv.FormatInt(amount/factor, decimalBase))
+
+	if exp := exponents[m.Currency]; exp > 0 {
+		out += fmt.Sprintf(".%0*d", exp, amount%factor)
+	}
+
+	return out
+}
+
+// String renders the amount with its currency, e.g. "IDR 150,000.00".
+func (m Money) String() string {
+	return string(m.Currency) + " " + m.Decimal()
+}
+
+// Pg converts the amount into a NUMERIC holding major units, e.g. IDR 150,000.00 is stored as 150000.00.
+func (m Money) Pg() pgtype.Numeric {
+	return pgtype.Numeric{
+		Int:   big.NewInt(m.Amount),
+		Exp:   -exponents[m.Currency],
+		Valid: true,
+	}
+}
+
+// FromPg reads a NUMERIC holding major units into minor units of the currency.
+// Digits beyond the currency's minor unit are rounded half away from zero.
+func FromPg(n pgtype.Numeric, currency Currency) (Money, error) {
+	exp, err := currency.Exponent()
+	if err != nil {
+		return Money{}, err
+	}
+
+	if !n.Valid || n.Int == nil {
+		return Zero(currency), nil
+	}
+
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return Money{}, fmt.Errorf("%w: not a finite number", ErrInvalidAmount)
+	}
+
+	minor := new(big.Int).Set(n.Int)
+	shift := int64(n.Exp + exp)
+
+	switch {
+	case shift > 0:
+		minor.Mul(minor, new(big.Int).Exp(big.NewInt(decimalBase), big.NewInt(shift), nil))
+	case shift < 0:
+		divisor := new(big.Int).Exp(big.NewInt(decimalBase), big.NewInt(-shift), nil)
+		quotient, remainder := new(big.Int).QuoRem(minor, divisor, new(big.Int))
+
+		if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(divisor) >= 0 {
+			quotient.Add(quotient, big.NewInt(int64(minor.Sign())))
+		}
+
+		minor = quotient
+	}
+
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+
+	return New(minor.Int64(), currency), nil
+}
+
+// FromPgOrZero is FromPg for displaying stored amounts, where an invalid value shows as zero.
+// Amounts that are charged must use FromPg, so an invalid price is an error rather than free.
+func FromPgOrZero(n pgtype.Numeric, currency string) Money {
+	m, err := FromPg(n, ParseCurrency(currency))
+	if err != nil {
+		return Zero(ParseCurrency(currency))
+	}
+
+	return m
+}
+
+// ParseCurrency normalises a currency code, falling back to the default currency when empty.
+func ParseCurrency(code string) Currency {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency
+	}
+
+	return Currency(code)
+}
+
+func groupThousands(digits string) string {
+	var out []byte
+
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%thousandsSep == 0 {
+			out = append(out, ',')
+		}
+
+		out = append(out, digits[i])
+	}
+
+	return string(out)
+}
@@ -0,0 +1,234 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMajor(t *testing.T) {
+	m := FromMajor(150000, IDR)
+
+	require.Equal(t, int64(15000000), m.Amount)
+	require.Equal(t, IDR, m.Currency)
+	require.Equal(t, float64(150000), m.Major())
+}
+
+func TestMajor(t *testing.T) {
+	require.Equal(t, float64(150000), New(15000049, IDR).Major())
+	require.Equal(t, float64(150001), New(15000050, IDR).Major())
+	require.Equal(t, float64(-150001), New(-15000050, IDR).Major())
+	require.Equal(t, float64(90071992547409), New(9007199254740900, IDR).Major())
+}
+
+func TestPgRoundTrip(t *testing.T) {
+	amounts := []Money{
+		Zero(IDR),
+		New(1, IDR),
+		New(99, IDR),
+		FromMajor(5000, IDR),
+		New(15000050, IDR),
+		New(-250000, IDR),
+	}
+
+	for _, amount := range amounts {
+		t.Run(amount.String(), func(t *testing.T) {
+			got, err := FromPg(amount.Pg(), amount.Currency)
+
+			require.NoError(t, err)
+			require.Equal(t, amount, got)
+		})
+	}
+}
+
+func TestPgScannedFromDatabase(t *testing.T) {
+	tests := map[string]int64{
+		"150000":     15000000,
+		"150000.00":  15000000,
+		"150000.5":   15000050,
+		"0.01":       1,
+		"2500.005":   250001,
+		"-2500.005":  -250001,
+		"2500.004":   250000,
+		"150000.500": 15000050,
+		"0":          0,
+		"1000000000": 100000000000,
+	}
+
+	for text, minor := range tests {
+		t.Run(text, func(t *testing.T) {
+			var n pgtype.Numeric
+			require.NoError(t, n.Scan(text))
+
+			got, err := FromPg(n, IDR)
+
+			require.NoError(t, err)
+			require.Equal(t, New(minor, IDR), got)
+		})
+	}
+}
+
+func TestPgStoresMajorUnits(t *testing.T) {
+	value, err := FromMajor(150000, IDR).Pg().Value()
+
+	require.NoError(t, err)
+	require.Equal(t, "150000.00", value)
+
+	f, err := New(15000050, IDR).Pg().Float64Value()
+
+	require.NoError(t, err)
+	require.InDelta(t, 150000.50, f.Float64, 0)
+}
+
+func TestFromPg(t *testing.T) {
+	t.Run("null is zero", func(t *testing.T) {
+		got, err := FromPg(pgtype.Numeric{}, IDR)
+
+		require.NoError(t, err)
+		require.Equal(t, Zero(IDR), got)
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		_, err := FromPg(New(100, IDR).Pg(), Currency("XXX"))
+
+		require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	})
+
+	t.Run("nan", func(t *testing.T) {
+		_, err := FromPg(pgtype.Numeric{NaN: true, Valid: true, Int: big.NewInt(0)}, IDR)
+
+		require.ErrorIs(t, err, ErrInvalidAmount)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+		_, err := FromPg(pgtype.Numeric{Int: huge, Valid: true}, IDR)
+
+		require.ErrorIs(t, err, ErrInvalidAmount)
+	})
+}
+
+func TestFromPgOrZero(t *testing.T) {
+	require.Equal(t, FromMajor(2500, IDR), FromPgOrZero(FromMajor(2500, IDR).Pg(), "IDR"))
+	require.Equal(t, FromMajor(2500, IDR), FromPgOrZero(FromMajor(2500, IDR).Pg(), ""))
+	require.Equal(t, Zero(IDR), FromPgOrZero(pgtype.Numeric{NaN: true, Valid: true}, "IDR"))
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	m := New(15000050, IDR)
+
+	out, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":15000050,"currency":"IDR"}`, string(out))
+
+	var got Money
+	require.NoError(t, json.Unmarshal(out, &got))
+	require.Equal(t, m, got)
+}
+
+func TestString(t *testing.T) {
+	tests := map[string]Money{
+		"IDR 0.00":           Zero(IDR),
+		"IDR 0.05":           New(5, IDR),
+		"IDR 999.00":         FromMajor(999, IDR),
+		"IDR 1,000.00":       FromMajor(1000, IDR),
+		"IDR 150,000.50":     New(15000050, IDR),
+		"IDR 1,234,567.89":   New(123456789, IDR),
+		"IDR -2,500.00":      FromMajor(-2500, IDR),
+		"IDR 100,000,000.00": FromMajor(100000000, IDR),
+	}
+
+	for expected, m := range tests {
+		require.Equal(t, expected, m.String())
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	price := FromMajor(100000, IDR)
+	discount := FromMajor(10000, IDR)
+
+	require.Equal(t, FromMajor(190000, IDR), price.Mul(2).Sub(discount))
+	require.Equal(t, FromMajor(110000, IDR), price.Add(discount))
+	require.Equal(t, FromMajor(-10000, IDR), discount.Neg())
+	require.True(t, discount.LessThan(price))
+	require.True(t, price.IsPositive())
+	require.True(t, Zero(IDR).IsZero())
+	require.True(t, SameCurrency(IDR, price, discount))
+	require.False(t, SameCurrency(IDR, price, Money{Amount: 1, Currency: "USD"}))
+}
+
+func TestSplitTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    Money
+		rate      float64
+		inclusive bool
+		subtotal  Money
+		tax       Money
+		total     Money
+	}{
+		{
+			name:     "exclusive",
+			amount:   FromMajor(200000, IDR),
+			rate:     11,
+			subtotal: FromMajor(200000, IDR),
+			tax:      FromMajor(22000, IDR),
+			total:    FromMajor(222000, IDR),
+		},
+		{
+			name:      "inclusive",
+			amount:    FromMajor(222000, IDR),
+			rate:      11,
+			inclusive: true,
+			subtotal:  FromMajor(200000, IDR),
+			tax:       FromMajor(22000, IDR),
+			total:     FromMajor(222000, IDR),
+		},
+		{
+			name:     "rounds down to whole rupiah",
+			amount:   FromMajor(150001, IDR),
+			rate:     11,
+			subtotal: FromMajor(150001, IDR),
+			tax:      FromMajor(16500, IDR),
+			total:    FromMajor(166501, IDR),
+		},
+		{
+			name:      "inclusive rounds up to whole rupiah",
+			amount:    FromMajor(150001, IDR),
+			rate:      11,
+			inclusive: true,
+			subtotal:  FromMajor(135136, IDR),
+			tax:       FromMajor(14865, IDR),
+			total:     FromMajor(150001, IDR),
+		},
+		{
+			name:     "no tax",
+			amount:   FromMajor(200000, IDR),
+			subtotal: FromMajor(200000, IDR),
+			tax:      Zero(IDR),
+			total:    FromMajor(200000, IDR),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtotal, tax, total := tt.amount.SplitTax(tt.rate, tt.inclusive)
+
+			require.Equal(t, tt.subtotal, subtotal)
+			require.Equal(t, tt.tax, tax)
+			require.Equal(t, tt.total, total)
+			require.Equal(t, total, subtotal.Add(tax))
+		})
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	require.Equal(t, IDR, ParseCurrency(""))
+	require.Equal(t, IDR, ParseCurrency(" idr "))
+
+	_, err := Currency("XXX").Exponent()
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
@@ -0,0 +1,126 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const (
+	ProviderGitHub = "github"
+
+	githubAPIURL = "https://api.github.com"
+)
+
+type GitHubProvider struct {
+	config *oauth2.Config
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return ProviderGitHub
+}
+
+func (p *GitHubProvider) AuthURL(_ context.Context, state, codeVerifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return token, nil
+}
+
+// UserInfo reads the profile and the primary email, which GitHub keeps separate since the public
+// profile email is optional.
+func (p *GitHubProvider) UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	client := p.config.Client(ctx, token)
+
+	var user githubUser
+	if err := getJSON(ctx, client, githubAPIURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	var emails []githubEmail
+	if err := getJSON(ctx, client, githubAPIURL+"/user/emails", &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+
+	info := &UserInfo{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+		Picture: user.AvatarURL,
+	}
+
+	if info.Name == "" {
+		info.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			info.Email = email.Email
+			info.EmailVerified = email.Verified
+
+			break
+		}
+	}
+
+	if info.Email == "" {
+		return nil, ErrEmailMissing
+	}
+
+	return info, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url) //nolint:err113
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -11,6 +11,8 @@ import (
 	"golang.org/x/oauth2/google"
 )
 
+const ProviderGoogle = "google"
+
 type GoogleProvider struct {
 	config *oauth2.Config
 }
@@ -38,12 +40,16 @@ func NewGoogleProvider(clientID, clientSecret, redirectURL string) *GoogleProvid
 	}
 }
 
-func (p *GoogleProvider) GetAuthURL(state string) string {
-	return p.config.AuthCodeURL(state)
+func (p *GoogleProvider) Name() string {
+	return ProviderGoogle
+}
+
+func (p *GoogleProvider) AuthURL(_ context.Context, state, codeVerifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
 }
 
-func (p *GoogleProvider) Exchange(code string) (*oauth2.Token, error) {
-	token, err := p.config.Exchange(context.Background(), code)
+func (p *GoogleProvider) Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
 	}
@@ -51,14 +57,15 @@ func (p *GoogleProvider) Exchange(code string) (*oauth2.Token, error) {
 	return token, nil
 }
 
-func (p *GoogleProvider) GetUserInfo(token *oauth2.Token) (*GoogleUserInfo, error) {
+func (p *GoogleProvider) UserInfo(_ context.Context, token *oauth2.Token) (*UserInfo, error) {
 	req := fasthttp.AcquireRequest()
 	resp := fasthttp.AcquireResponse()
 
 	defer fasthttp.ReleaseRequest(req)
 	defer fasthttp.ReleaseResponse(resp)
 
-	req.SetRequestURI("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
+	req.SetRequestURI("https://www.googleapis.com/oauth2/v2/userinfo")
+	req.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token.AccessToken)
 	err := fasthttp.Do(req, resp)
 
 	if err != nil {
@@ -70,5 +77,15 @@ func (p *GoogleProvider) GetUserInfo(token *oauth2.Token) (*GoogleUserInfo, erro
 		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
 	}
 
-	return &userInfo, nil
+	if userInfo.Email == "" {
+		return nil, ErrEmailMissing
+	}
+
+	return &UserInfo{
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+		Name:          userInfo.Name,
+		Picture:       userInfo.Picture,
+	}, nil
 }
@@ -0,0 +1,264 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	httpTimeout   = 10 * time.Second
+)
+
+var (
+	ErrIDTokenMissing = errors.New("oauth: provider did not return an id_token")
+	ErrUnknownKey     = errors.New("oauth: id_token signed with an unknown key")
+)
+
+// OIDCConfig configures a generic OpenID Connect provider found through discovery.
+type OIDCConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// FormPost asks the provider to POST the callback instead of redirecting, as Sign in with Apple
+	// requires when requesting the email scope.
+	FormPost bool
+}
+
+// OIDCProvider signs users in with any OpenID Connect provider. Endpoints and signing keys are
+// discovered from the issuer on first use and the identity is read from the verified id_token.
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"` // Apple sends "true" as a string
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *OIDCProvider) AuthURL(ctx context.Context, state, codeVerifier string) (string, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(codeVerifier)}
+	if p.cfg.FormPost {
+		opts = append(opts, oauth2.SetAuthURLParam("response_mode", "form_post"))
+	}
+
+	return config.AuthCodeURL(state, opts...), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return token, nil
+}
+
+func (p *OIDCProvider) UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrIDTokenMissing
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}
+
+	// Some providers keep the profile out of the id_token
+	if info.Email == "" && discovery.UserinfoEndpoint != "" {
+		var profile struct {
+			Email         string `json:"email"`
+			EmailVerified bool   `json:"email_verified"`
+			Name          string `json:"name"`
+			Picture       string `json:"picture"`
+		}
+
+		client := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, p.client), oauth2.StaticTokenSource(token))
+		if err := getJSON(ctx, client, discovery.UserinfoEndpoint, &profile); err != nil {
+			return nil, fmt.Errorf("failed to get user info: %w", err)
+		}
+
+		info.Email, info.EmailVerified = profile.Email, profile.EmailVerified
+		info.Name, info.Picture = profile.Name, profile.Picture
+	}
+
+	if info.Email == "" {
+		return nil, ErrEmailMissing
+	}
+
+	return info, nil
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, rawIDToken string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return p.key(ctx, discovery.JwksURI, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (p *OIDCProvider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}, nil
+}
+
+// discover fetches the issuer's configuration once; failures are retried on the next login.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.client, strings.TrimSuffix(p.cfg.IssuerURL, "/")+discoveryPath, &discovery); err != nil {
+		return nil, fmt.Errorf("oauth: failed to discover %s: %w", p.cfg.Name, err)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+// key returns the signing key by ID, refreshing the key set once when the provider has rotated keys.
+func (p *OIDCProvider) key(ctx context.Context, jwksURI, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	var set jsonWebKeySet
+	if err := getJSON(ctx, p.client, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("oauth: failed to get signing keys: %w", err)
+	}
+
+	p.keys = make(map[string]*rsa.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+
+		if errN != nil || errE != nil {
+			continue
+		}
+
+		p.keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
@@ -0,0 +1,71 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"golang.org/x/oauth2"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=provider.go -destination=mock/provider_mock.go -package=mock github.com/savioruz/goth/pkg/oauth Provider
+
+var (
+	ErrUnknownProvider = errors.New("oauth: unknown provider")
+	ErrEmailMissing    = errors.New("oauth: provider did not return an email address")
+)
+
+// Provider is an OAuth 2.0 / OpenID Connect identity provider users can sign in with.
+type Provider interface {
+	// Name identifies the provider in routes and stored identities, e.g. "google".
+	Name() string
+	// AuthURL builds the consent URL, binding it to the PKCE verifier with an S256 challenge.
+	AuthURL(ctx context.Context, state, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error)
+	UserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error)
+}
+
+// UserInfo is the identity asserted by a provider. Subject is stable for the user at that provider.
+type UserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+
+	return r
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return p, nil
+}
+
+// Names lists the configured providers in alphabetical order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,143 @@
+package otp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/pkg/helper"
+	"github.com/savioruz/goth/pkg/redis"
+	"github.com/savioruz/goth/pkg/sms"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=otp.go -destination=mock/otp_mock.go -package=mock github.com/savioruz/goth/pkg/otp Service
+
+const (
+	codeKey     = "otp:code:%s:%s"
+	attemptsKey = "otp:attempts:%s:%s"
+	cooldownKey = "otp:cooldown:%s:%s"
+
+	codeDigits = 6
+)
+
+var (
+	ErrInvalidCode     = errors.New("otp: invalid or expired code")
+	ErrTooManyAttempts = errors.New("otp: too many attempts")
+	ErrCooldown        = errors.New("otp: a code was sent recently")
+)
+
+type Config struct {
+	// AppName tells the recipient who the code is from.
+	AppName string
+	// TTL is how long a code can be used.
+	TTL time.Duration
+	// MaxAttempts is how many wrong codes discard the code, so it cannot be guessed.
+	MaxAttempts int
+	// ResendCooldown is how long to wait before another code is sent to the same number.
+	ResendCooldown time.Duration
+}
+
+// Service sends one-time codes by SMS and checks them. Codes are scoped to a purpose, so a code sent to
+// verify a number cannot be used to log in.
+type Service interface {
+	// Send texts a new code to the phone, replacing the previous one.
+	Send(ctx context.Context, purpose, phone string) error
+	// Verify accepts the code once. Wrong codes count towards MaxAttempts.
+	Verify(ctx context.Context, purpose, phone, code string) error
+}
+
+type redisService struct {
+	cache  redis.IRedisCache
+	sender sms.Sender
+	config Config
+}
+
+func NewRedisService(cache redis.IRedisCache, sender sms.Sender, cfg Config) Service {
+	return &redisService{cache: cache, sender: sender, config: cfg}
+}
+
+func (s *redisService) Send(ctx context.Context, purpose, phone string) error {
+	sent, err := s.cache.Incr(ctx, fmt.Sprintf(cooldownKey, purpose, phone), int(s.config.ResendCooldown.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	if sent > 1 {
+		return ErrCooldown
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return err
+	}
+
+	if err = s.cache.Save(ctx, fmt.Sprintf(codeKey, purpose, phone), helper.HashToken(code), int(s.config.TTL.Seconds())); err != nil {
+		return err
+	}
+
+	if err = s.cache.Delete(ctx, fmt.Sprintf(attemptsKey, purpose, phone)); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("%s: your code is %s. It expires in %s, do not share it with anyone.", s.config.AppName, code, s.config.TTL)
+
+	return s.sender.Send(ctx, phone, message)
+}
+
+func (s *redisService) Verify(ctx context.Context, purpose, phone, code string) error {
+	key := fmt.Sprintf(codeKey, purpose, phone)
+
+	var hash string
+
+	err := s.cache.Get(ctx, key, &hash)
+	if errors.Is(err, goredis.Nil) {
+		return ErrInvalidCode
+	}
+
+	if err != nil {
+		return err
+	}
+
+	attempts, err := s.cache.Incr(ctx, fmt.Sprintf(attemptsKey, purpose, phone), int(s.config.TTL.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	if attempts > int64(s.config.MaxAttempts) {
+		if err = s.cache.Delete(ctx, key); err != nil {
+			return err
+		}
+
+		return ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(helper.HashToken(code))) != 1 {
+		return ErrInvalidCode
+	}
+
+	// Only the request that removes the code may use it
+	err = s.cache.GetDel(ctx, key, &hash)
+	if errors.Is(err, goredis.Nil) {
+		return ErrInvalidCode
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Delete(ctx, fmt.Sprintf(attemptsKey, purpose, phone))
+}
+
+func generateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n.Int64()), nil
+}
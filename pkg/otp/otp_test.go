@@ -0,0 +1,117 @@
+package otp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/savioruz/goth/pkg/helper"
+	redis "github.com/savioruz/goth/pkg/redis/mock"
+	sms "github.com/savioruz/goth/pkg/sms/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testConfig = Config{
+	AppName:        "goth",
+	TTL:            5 * time.Minute,
+	MaxAttempts:    5,
+	ResendCooldown: time.Minute,
+}
+
+const testPhone = "+6281234567890"
+
+func TestService_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("texts a code and stores its hash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		sender := sms.NewMockSender(ctrl)
+		s := NewRedisService(cache, sender, testConfig)
+
+		var stored string
+
+		cache.EXPECT().Incr(gomock.Any(), "otp:cooldown:login:"+testPhone, 60).Return(int64(1), nil)
+		cache.EXPECT().
+			Save(gomock.Any(), "otp:code:login:"+testPhone, gomock.Any(), 300).
+			DoAndReturn(func(_ context.Context, _ string, value any, _ int) error {
+				stored, _ = value.(string)
+
+				return nil
+			})
+		cache.EXPECT().Delete(gomock.Any(), "otp:attempts:login:"+testPhone).Return(nil)
+		sender.EXPECT().
+			Send(gomock.Any(), testPhone, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, message string) error {
+				code := message[len("goth: your code is ") : len("goth: your code is ")+codeDigits]
+				assert.Equal(t, helper.HashToken(code), stored)
+
+				return nil
+			})
+
+		require.NoError(t, s.Send(ctx, "login", testPhone))
+	})
+
+	t.Run("refuses to resend during the cooldown", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		s := NewRedisService(cache, sms.NewMockSender(ctrl), testConfig)
+
+		cache.EXPECT().Incr(gomock.Any(), "otp:cooldown:login:"+testPhone, 60).Return(int64(2), nil)
+
+		assert.ErrorIs(t, s.Send(ctx, "login", testPhone), ErrCooldown)
+	})
+}
+
+func TestService_Verify(t *testing.T) {
+	ctx := context.Background()
+	hash := helper.HashToken("123456")
+
+	expectStored := func(cache *redis.MockIRedisCache) {
+		cache.EXPECT().
+			Get(gomock.Any(), "otp:code:login:"+testPhone, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, value any) error {
+				*value.(*string) = hash
+
+				return nil
+			})
+	}
+
+	t.Run("accepts the code once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		s := NewRedisService(cache, nil, testConfig)
+
+		expectStored(cache)
+		cache.EXPECT().Incr(gomock.Any(), "otp:attempts:login:"+testPhone, 300).Return(int64(1), nil)
+		cache.EXPECT().GetDel(gomock.Any(), "otp:code:login:"+testPhone, gomock.Any()).Return(nil)
+		cache.EXPECT().Delete(gomock.Any(), "otp:attempts:login:"+testPhone).Return(nil)
+
+		require.NoError(t, s.Verify(ctx, "login", testPhone, "123456"))
+	})
+
+	t.Run("rejects a wrong code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		s := NewRedisService(cache, nil, testConfig)
+
+		expectStored(cache)
+		cache.EXPECT().Incr(gomock.Any(), "otp:attempts:login:"+testPhone, 300).Return(int64(1), nil)
+
+		assert.ErrorIs(t, s.Verify(ctx, "login", testPhone, "654321"), ErrInvalidCode)
+	})
+
+	t.Run("discards the code after too many attempts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cache := redis.NewMockIRedisCache(ctrl)
+		s := NewRedisService(cache, nil, testConfig)
+
+		expectStored(cache)
+		cache.EXPECT().Incr(gomock.Any(), "otp:attempts:login:"+testPhone, 300).Return(int64(6), nil)
+		cache.EXPECT().Delete(gomock.Any(), "otp:code:login:"+testPhone).Return(nil)
+
+		assert.ErrorIs(t, s.Verify(ctx, "login", testPhone, "123456"), ErrTooManyAttempts)
+	})
+}
@@ -0,0 +1,177 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/savioruz/goth/pkg/money"
+)
+
+const (
+	ContentType = "application/pdf"
+
+	pageMargin   = 15.0
+	lineHeight   = 6.0
+	headerHeight = 10.0
+)
+
+// Item is a single line on the receipt.
+type Item struct {
+	Name        string
+	Description string
+	Quantity    int
+	UnitPrice   money.Money
+	Discount    money.Money
+	Amount      money.Money
+}
+
+// Data represents everything printed on a booking receipt
+type Data struct {
+	Issuer        string
+	ReceiptNumber string
+	IssuedAt      string
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	LocationName  string
+	FieldName     string
+	FieldType     string
+	BookingDate   string
+	StartTime     string
+	EndTime       string
+	Status        string
+	PaymentMethod string
+	PaidAt        string
+	Items         []Item
+	Subtotal      money.Money
+	Tax           money.Money
+	TaxLabel      string
+	Total         money.Money
+}
+
+// Render lays out the receipt on a single A4 page and returns the PDF bytes.
+func Render(data Data) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetTitle("Receipt "+data.ReceiptNumber, true)
+	pdf.SetCreator(data.Issuer, true)
+	pdf.AddPage()
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, headerHeight, tr(data.Issuer), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.CellFormat(0, lineHeight, "Payment Receipt", "", 1, "L", false, 0, "")
+	pdf.Ln(lineHeight)
+
+	details := [][2]string{
+		{"Receipt No.", data.ReceiptNumber},
+		{"Issued At", data.IssuedAt},
+		{"Customer", data.CustomerName},
+		{"Email", data.CustomerEmail},
+		{"Phone", data.CustomerPhone},
+		{"Location", data.LocationName},
+		{"Field", fieldLabel(data.FieldName, data.FieldType)},
+		{"Date", data.BookingDate},
+		{"Time", data.StartTime + " - " + data.EndTime},
+		{"Status", data.Status},
+		{"Payment Method", data.PaymentMethod},
+		{"Paid At", data.PaidAt},
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+
+	for _, detail := range details {
+		if detail[1] == "" {
+			continue
+		}
+
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(40, lineHeight, detail[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, lineHeight, tr(detail[1]), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(lineHeight)
+
+	widths := []float64{70, 20, 30, 30, 30}
+	headers := []string{"Item", "Qty", "Unit Price", "Discount", "Amount"}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+
+		pdf.CellFormat(widths[i], lineHeight+1, header, "B", 0, align, true, 0, "")
+	}
+
+	pdf.Ln(-1)
+	pdf.SetFont("Helvetica", "", 10)
+
+	for _, item := range data.Items {
+		name := item.Name
+		if item.Description != "" {
+			name += " (" + item.Description + ")"
+		}
+
+		pdf.CellFormat(widths[0], lineHeight, tr(name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], lineHeight, strconv.Itoa(item.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], lineHeight, item.UnitPrice.Decimal(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], lineHeight, item.Discount.Decimal(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], lineHeight, item.Amount.Decimal(), "", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+
+	totals := [][2]string{{"Subtotal", data.Subtotal.String()}}
+	if data.Tax.IsPositive() {
+		label := data.TaxLabel
+		if label == "" {
+			label = "Tax"
+		}
+
+		totals = append(totals, [2]string{label, data.Tax.String()})
+	}
+
+	totals = append(totals, [2]string{"Total", data.Total.String()})
+
+	labelWidth := widths[0] + widths[1] + widths[2] + widths[3]
+
+	for i, total := range totals {
+		style := ""
+		if i == len(totals)-1 {
+			style = "B"
+		}
+
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(labelWidth, lineHeight, total[0], "T", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], lineHeight, total[1], "T", 1, "R", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Filename returns the download name of a receipt, e.g. receipt-<number>.pdf.
+func Filename(number string) string {
+	return fmt.Sprintf("receipt-%s.pdf", number)
+}
+
+func fieldLabel(name, fieldType string) string {
+	if fieldType == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s (%s)", name, fieldType)
+}
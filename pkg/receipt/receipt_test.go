@@ -0,0 +1,41 @@
+package receipt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/savioruz/goth/pkg/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	data := Data{
+		Issuer:        "Test Service",
+		ReceiptNumber: "123e4567-e89b-12d3-a456-426614174000",
+		IssuedAt:      "2025-01-01 10:00:00",
+		CustomerName:  "John Doe",
+		CustomerEmail: "john@example.com",
+		LocationName:  "Main Arena",
+		FieldName:     "Field A",
+		FieldType:     "futsal",
+		BookingDate:   "2025-01-02",
+		StartTime:     "15:00",
+		EndTime:       "17:00",
+		Status:        "CONFIRMED",
+		PaymentMethod: "QRIS",
+		Items: []Item{
+			{Name: "Field A", Description: "2025-01-02 15:00, 2 hour(s)", Quantity: 2, UnitPrice: money.FromMajor(100000, money.IDR), Amount: money.FromMajor(200000, money.IDR)},
+			{Name: "Service fee", Quantity: 1, UnitPrice: money.FromMajor(2500, money.IDR), Amount: money.FromMajor(2500, money.IDR)},
+		},
+		Subtotal: money.FromMajor(202500, money.IDR),
+		Tax:      money.Zero(money.IDR),
+		Total:    money.FromMajor(202500, money.IDR),
+	}
+
+	t.Run("renders a pdf document", func(t *testing.T) {
+		out, err := Render(data)
+
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+	})
+}
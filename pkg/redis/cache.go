@@ -13,7 +13,11 @@ import (
 type IRedisCache interface {
 	Save(ctx context.Context, key string, value any, duration int) (err error)
 	Get(ctx context.Context, key string, value any) (err error)
+	// GetDel reads and removes the key atomically, so only one caller can ever consume it.
+	GetDel(ctx context.Context, key string, value any) (err error)
 	Delete(ctx context.Context, key string) error
+	// Incr increments the counter at key, starting its expiry when the counter is created.
+	Incr(ctx context.Context, key string, duration int) (int64, error)
 	Clear(ctx context.Context, prefix string) error
 }
 
@@ -61,10 +65,41 @@ func (i *iRedisCacheImpl) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Incr implements IRedisCache.
+// The increment and the expiry run in one transaction, so a counter is never left without a TTL.
+func (i *iRedisCacheImpl) Incr(ctx context.Context, key string, duration int) (int64, error) {
+	var incr *redis.IntCmd
+
+	_, err := i.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, time.Second*time.Duration(duration))
+
+		return nil
+	})
+	if err != nil {
+		i.log.Error("redis - incr - failed to increment counter", err)
+
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
 // Get implements IRedisCache.
 func (i *iRedisCacheImpl) Get(ctx context.Context, key string, value any) (err error) {
 	cacheValue, err := i.client.Get(ctx, key).Result()
 
+	return i.decode(cacheValue, err, value)
+}
+
+// GetDel implements IRedisCache.
+func (i *iRedisCacheImpl) GetDel(ctx context.Context, key string, value any) (err error) {
+	cacheValue, err := i.client.GetDel(ctx, key).Result()
+
+	return i.decode(cacheValue, err, value)
+}
+
+func (i *iRedisCacheImpl) decode(cacheValue string, err error, value any) error {
 	if err == nil {
 		switch v := value.(type) {
 		case *string:
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of the AES-256 key, before base64 encoding.
+const KeySize = 32
+
+var ErrMalformed = errors.New("secret: malformed ciphertext")
+
+// Box encrypts secrets stored in the database, such as payment provider credentials, with
+// AES-256-GCM. Sealed values are base64 and carry their own nonce.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box for the base64 encoded 32 byte key.
+func New(key string) (*Box, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("secret: decode key: %w", err)
+	}
+
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("secret: key must be %d bytes, got %d", KeySize, len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("secret: new cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secret: new gcm: %w", err)
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts the plaintext.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("secret: generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal.
+func (b *Box) Open(ciphertext string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	nonce, sealed := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+
+	plaintext, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("secret: open: %w", err)
+	}
+
+	return string(plaintext), nil
+}
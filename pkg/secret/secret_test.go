@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize)))
+
+func TestBox_SealOpen(t *testing.T) {
+	box, err := New(testKey)
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("xnd_development_key")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "xnd_development_key")
+
+	again, err := box.Seal("xnd_development_key")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "each seal uses a fresh nonce")
+
+	opened, err := box.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "xnd_development_key", opened)
+}
+
+func TestBox_OpenTampered(t *testing.T) {
+	box, err := New(testKey)
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("token")
+	require.NoError(t, err)
+
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 1
+
+	_, err = box.Open(base64.StdEncoding.EncodeToString(raw))
+	assert.Error(t, err)
+
+	_, err = box.Open("not base64!")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestNew_InvalidKey(t *testing.T) {
+	_, err := New(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
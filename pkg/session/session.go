@@ -0,0 +1,86 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/savioruz/goth/pkg/jwt"
+	"github.com/savioruz/goth/pkg/redis"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=session.go -destination=mock/session_mock.go -package=mock github.com/savioruz/goth/pkg/session Revoker
+
+const (
+	revokedSessionKey = "session:revoked:%s"
+	revokedUserKey    = "session:revoked_user:%s"
+)
+
+// Revoker rejects access tokens before they expire. Entries only need to outlive the access tokens
+// they cancel, refresh tokens are revoked in the database.
+type Revoker interface {
+	// RevokeSession rejects every access token issued for the session.
+	RevokeSession(ctx context.Context, sessionID string) error
+	// RevokeUser rejects every access token of the user issued until now, e.g. after their role changed.
+	RevokeUser(ctx context.Context, userID string) error
+	IsRevoked(ctx context.Context, claims *jwt.Claims) (bool, error)
+}
+
+type redisRevoker struct {
+	cache redis.IRedisCache
+}
+
+func NewRedisRevoker(cache redis.IRedisCache) Revoker {
+	return &redisRevoker{cache: cache}
+}
+
+func (r *redisRevoker) RevokeSession(ctx context.Context, sessionID string) error {
+	return r.cache.Save(ctx, fmt.Sprintf(revokedSessionKey, sessionID), "1", ttl())
+}
+
+func (r *redisRevoker) RevokeUser(ctx context.Context, userID string) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	return r.cache.Save(ctx, fmt.Sprintf(revokedUserKey, userID), now, ttl())
+}
+
+func (r *redisRevoker) IsRevoked(ctx context.Context, claims *jwt.Claims) (bool, error) {
+	if claims.SessionID != "" {
+		var revoked string
+
+		err := r.cache.Get(ctx, fmt.Sprintf(revokedSessionKey, claims.SessionID), &revoked)
+		if err == nil {
+			return true, nil
+		}
+
+		if !errors.Is(err, goredis.Nil) {
+			return false, err
+		}
+	}
+
+	var revokedAt string
+
+	err := r.cache.Get(ctx, fmt.Sprintf(revokedUserKey, claims.ID), &revokedAt)
+	if errors.Is(err, goredis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	cutoff, err := strconv.ParseInt(revokedAt, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("session: invalid revocation time %q: %w", revokedAt, err)
+	}
+
+	// Token timestamps have second precision, tokens refreshed right after the revocation must pass
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() < cutoff, nil
+}
+
+func ttl() int {
+	return int(jwt.AccessTokenExpiry().Seconds())
+}
@@ -0,0 +1,30 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/savioruz/goth/pkg/logger"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=sms.go -destination=mock/sms_mock.go -package=mock github.com/savioruz/goth/pkg/sms Sender
+
+// Sender delivers text messages to phone numbers in E.164 format, e.g. +6281234567890.
+// Gateways are plugged in by implementing it.
+type Sender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+type logSender struct {
+	logger logger.Interface
+}
+
+// NewLogSender writes messages to the log instead of sending them, for local development.
+func NewLogSender(l logger.Interface) Sender {
+	return &logSender{logger: l}
+}
+
+func (s *logSender) Send(_ context.Context, to, message string) error {
+	s.logger.Info("sms - to %s: %s", to, message)
+
+	return nil
+}
@@ -1,14 +1,17 @@
 package supabase
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -17,24 +20,42 @@ import (
 
 const (
 	// Storage paths
-	FieldsStoragePath = "fields"
+	FieldsStoragePath   = "fields"
+	ReceiptsStoragePath = "receipts"
+	AvatarsStoragePath  = "avatars"
 
 	// Constants
 	MinURLParts = 2
 )
 
+//go:generate go run go.uber.org/mock/mockgen -source=storage.go -destination=mock/storage_mock.go -package=mock github.com/savioruz/goth/pkg/supabase Storage
+
 var (
 	// Error messages
 	ErrInvalidFileURL     = errors.New("invalid file URL")
 	ErrFailedToUploadFile = errors.New("failed to upload file to Supabase")
 	ErrFailedToDeleteFile = errors.New("failed to delete file from Supabase")
+	ErrFailedToGetFile    = errors.New("failed to get file from Supabase")
+	ErrFileNotFound       = errors.New("file not found in Supabase")
 )
 
+// Storage keeps public uploads and private documents. Client implements it.
+type Storage interface {
+	UploadFileTo(ctx context.Context, file multipart.File, storagePath, filename string) (string, error)
+	DeleteFile(ctx context.Context, fileURL string) error
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	DeleteObject(ctx context.Context, key string) error
+	GetPublicURL(key string) string
+}
+
 type Client struct {
-	s3Client    *s3.S3
-	bucketName  string
-	endpointURL string
-	region      string
+	s3Client   *s3.S3
+	bucketName string
+	// privateBucketName must not be public, access to a public bucket ignores object ACLs
+	privateBucketName string
+	endpointURL       string
+	region            string
 }
 
 type Config struct {
@@ -43,6 +64,8 @@ type Config struct {
 	EndpointURL     string
 	Region          string
 	BucketName      string
+	// PrivateBucketName holds objects written by PutObject
+	PrivateBucketName string
 }
 
 func NewClient(cfg Config) (*Client, error) {
@@ -61,17 +84,23 @@ func NewClient(cfg Config) (*Client, error) {
 	}
 
 	return &Client{
-		s3Client:    s3.New(sess),
-		bucketName:  cfg.BucketName,
-		endpointURL: cfg.EndpointURL,
-		region:      cfg.Region,
+		s3Client:          s3.New(sess),
+		bucketName:        cfg.BucketName,
+		privateBucketName: cfg.PrivateBucketName,
+		endpointURL:       cfg.EndpointURL,
+		region:            cfg.Region,
 	}, nil
 }
 
 func (c *Client) UploadFile(ctx context.Context, file multipart.File, filename string) (string, error) {
-	// Generate unique filename with fields path
+	return c.UploadFileTo(ctx, file, FieldsStoragePath, filename)
+}
+
+// UploadFileTo publishes the file under a unique name in the given storage path and returns its public URL.
+func (c *Client) UploadFileTo(ctx context.Context, file multipart.File, storagePath, filename string) (string, error) {
+	// Generate unique filename with the storage path
 	ext := filepath.Ext(filename)
-	uniqueFilename := fmt.Sprintf("%s/%s%s", FieldsStoragePath, uuid.New().String(), ext)
+	uniqueFilename := fmt.Sprintf("%s/%s%s", storagePath, uuid.New().String(), ext)
 
 	// Reset file pointer
 	if _, err := file.Seek(0, 0); err != nil {
@@ -129,6 +158,65 @@ func (c *Client) DeleteFile(ctx context.Context, fileURL string) error {
 	return nil
 }
 
+// PutObject stores a private object under key in the private bucket, unlike UploadFile which
+// publishes field images.
+func (c *Client) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := c.s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.privateBucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+		ACL:         aws.String("private"),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUploadFile, err)
+	}
+
+	return nil
+}
+
+// GetObject reads an object stored under key by PutObject, returning ErrFileNotFound when it does not exist.
+func (c *Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	out, err := c.s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.privateBucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, key)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrFailedToGetFile, err)
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToGetFile, err)
+	}
+
+	return body, nil
+}
+
+// DeleteObject removes an object stored under key by PutObject. Deleting a missing object succeeds.
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	_, err := c.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.privateBucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToDeleteFile, err)
+	}
+
+	return nil
+}
+
+// ReceiptKey is where the receipt of a booking is stored with PutObject.
+func ReceiptKey(organizationID, bookingID string) string {
+	return fmt.Sprintf("%s/%s/%s.pdf", ReceiptsStoragePath, organizationID, bookingID)
+}
+
 func (c *Client) GetPublicURL(key string) string {
 	baseURL := strings.Replace(c.endpointURL, "/storage/v1/s3", "", 1)
 
@@ -0,0 +1,38 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/savioruz/goth/pkg/helper"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=tenant.go -destination=mock/tenant_mock.go -package=mock github.com/savioruz/goth/pkg/tenant Resolver
+
+// Resolver finds the organisation a request is for.
+type Resolver interface {
+	// Resolve returns the ID of the organisation with the slug.
+	Resolve(ctx context.Context, slug string) (pgtype.UUID, error)
+}
+
+type ctxKey struct{}
+
+// NewContext returns a context carrying the organisation ID. Repositories of tenant owned data
+// filter by it, so it must be set for every request touching them.
+func NewContext(ctx context.Context, organizationID pgtype.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, organizationID)
+}
+
+// FromContext returns the organisation ID of the context. It is invalid when none was set,
+// which matches no rows, so a missing tenant never leaks data of another one.
+func FromContext(ctx context.Context) pgtype.UUID {
+	id, _ := ctx.Value(ctxKey{}).(pgtype.UUID)
+
+	return id
+}
+
+// CacheKey builds a cache key private to the organisation of the context, so cached listings of
+// one organisation are never served to another.
+func CacheKey(ctx context.Context, prefix, postfix string) string {
+	return helper.BuildCacheKey(prefix, FromContext(ctx).String()+":"+postfix)
+}
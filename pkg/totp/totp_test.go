@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 key from the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFCVectors(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	t.Run("current code", func(t *testing.T) {
+		step, ok := Validate(secret, code, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, now.Unix()/30, step)
+	})
+
+	t.Run("previous period within skew", func(t *testing.T) {
+		_, ok := Validate(secret, code, now.Add(Period))
+
+		assert.True(t, ok)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		_, ok := Validate(secret, code, now.Add(3*Period))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("malformed code", func(t *testing.T) {
+		_, ok := Validate(secret, "12345", now)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("JBSWY3DPEHPK3PXP", "Goth", "admin@example.com")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Goth:admin@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Goth")
+}